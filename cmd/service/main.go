@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"go.uber.org/zap"
 	"os"
@@ -12,6 +13,7 @@ import (
 	"subservice/internal/logger"
 	"subservice/internal/service"
 	"subservice/internal/storage"
+	"subservice/internal/storage/memory"
 	"subservice/internal/storage/postgres"
 	"syscall"
 	"time"
@@ -31,6 +33,13 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := RunMigrate(ctx, cfg, l, os.Args[2:]); err != nil {
+			l.Fatal("migration failed:", zap.Error(err))
+		}
+		return
+	}
+
 	sig := make(chan os.Signal, 1)
 	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
 
@@ -40,13 +49,14 @@ func main() {
 		cancel()
 	}()
 
-	pool, err := pgxpool.Connect(ctx, cfg.PostgresURL)
-	if err != nil {
-		l.Fatal("failed to connect to database:", zap.Error(err))
-	}
-	defer pool.Close()
+	repo, closeStorage := InitStorage(ctx, cfg, l)
+	defer closeStorage()
+
+	SubscriptionService := service.NewSubscriptionService(repo, l)
+	SubscriptionService.IdempotencyTTL = cfg.IdempotencyTTL
+	SubscriptionService.RejectUnknownServices = cfg.RejectUnknownServices
 
-	SubscriptionService := service.NewSubscriptionService(InitStorage(pool), l)
+	go SubscriptionService.RunPurgeJob(ctx, cfg.PurgeRetention, cfg.PurgeInterval)
 
 	router := api.SetupRouter(SubscriptionService, l)
 
@@ -67,9 +77,40 @@ func main() {
 	time.Sleep(7 * time.Second)
 }
 
-func InitStorage(pool *pgxpool.Pool) storage.Facade {
+func InitStorage(ctx context.Context, cfg *config.Config, l *zap.Logger) (storage.Facade, func()) {
+	if cfg.StorageType == "memory" {
+		l.Warn("using in-memory storage, data will be lost on shutdown")
+		return memory.NewStorage(), func() {}
+	}
+
+	pool, err := pgxpool.Connect(ctx, cfg.PostgresURL)
+	if err != nil {
+		l.Fatal("failed to connect to database:", zap.Error(err))
+	}
+
+	if cfg.AutoMigrate {
+		if err := postgres.Migrate(ctx, pool, postgres.MigrateUp, l); err != nil {
+			l.Fatal("failed to apply migrations:", zap.Error(err))
+		}
+	}
+
 	txMngr := postgres.NewTxManager(pool)
 	pgRepo := postgres.NewPgRepository(txMngr)
 
-	return storage.NewStorageFacade(txMngr, pgRepo)
+	return storage.NewStorageFacade(txMngr, pgRepo), pool.Close
+}
+
+// RunMigrate implements the `migrate up|down|status` subcommand.
+func RunMigrate(ctx context.Context, cfg *config.Config, l *zap.Logger, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: %s migrate up|down|status", os.Args[0])
+	}
+
+	pool, err := pgxpool.Connect(ctx, cfg.PostgresURL)
+	if err != nil {
+		return fmt.Errorf("connect to database: %w", err)
+	}
+	defer pool.Close()
+
+	return postgres.Migrate(ctx, pool, args[0], l)
 }
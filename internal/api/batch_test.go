@@ -0,0 +1,92 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"subservice/internal/api/handler"
+	"subservice/internal/model"
+	"subservice/internal/testutil"
+	"testing"
+)
+
+func TestBatchSubscriptions(t *testing.T) {
+	create := `{"op":"create","subscription":{"service_name":"Kinopoisk","price":100,"user_id":"` + testutil.Owner.String() + `","start_date":"2024-01-01T00:00:00Z"}}`
+	invalid := `{"op":"renew"}`
+	missing := `{"op":"delete","id":"00000000-0000-0000-0000-0000000000ff"}`
+
+	tests := []struct {
+		name        string
+		mode        string
+		ops         []string
+		wantStatus  []int
+		wantField   []string
+		wantApplied int
+	}{
+		{
+			name:        "best effort maps service errors past rejected operations",
+			mode:        "best_effort",
+			ops:         []string{create, invalid, create, missing},
+			wantStatus:  []int{http.StatusCreated, http.StatusBadRequest, http.StatusCreated, http.StatusNotFound},
+			wantField:   []string{"", "op", "", ""},
+			wantApplied: 2,
+		},
+		{
+			name:       "atomic aborts on a rejected operation",
+			mode:       "atomic",
+			ops:        []string{create, create, invalid},
+			wantStatus: []int{http.StatusFailedDependency, http.StatusFailedDependency, http.StatusBadRequest},
+			wantField:  []string{"", "", "op"},
+		},
+		{
+			name:       "atomic aborts on a failed operation",
+			mode:       "atomic",
+			ops:        []string{create, missing, create},
+			wantStatus: []int{http.StatusFailedDependency, http.StatusNotFound, http.StatusFailedDependency},
+			wantField:  []string{"", "", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := `{"mode":"` + tt.mode + `","operations":[` + strings.Join(tt.ops, ",") + `]}`
+			rec := serve(newTestRouter(), http.MethodPost, "/api/v1/subscriptions/batch", body)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("POST = %d %s, want 200", rec.Code, rec.Body)
+			}
+			var resp handler.BatchResponse
+			decode(t, rec, &resp)
+
+			if len(resp.Results) != len(tt.ops) {
+				t.Fatalf("got %d results, want %d", len(resp.Results), len(tt.ops))
+			}
+			for i, result := range resp.Results {
+				if result.Index != i || result.Status != tt.wantStatus[i] || result.Field != tt.wantField[i] {
+					t.Errorf("result %d = %+v, want status %d field %q", i, result, tt.wantStatus[i], tt.wantField[i])
+				}
+			}
+			if resp.Applied != tt.wantApplied || resp.Failed != len(tt.ops)-tt.wantApplied {
+				t.Errorf("applied %d failed %d, want %d applied", resp.Applied, resp.Failed, tt.wantApplied)
+			}
+		})
+	}
+}
+
+func TestBatchSubscriptionsAtomicRollback(t *testing.T) {
+	router := newTestRouter()
+	body := `{"operations":[` +
+		`{"op":"create","subscription":{"service_name":"Kinopoisk","price":100,"user_id":"` + testutil.Owner.String() + `","start_date":"2024-01-01T00:00:00Z"}},` +
+		`{"op":"delete","id":"00000000-0000-0000-0000-0000000000ff"}]}`
+	if rec := serve(router, http.MethodPost, "/api/v1/subscriptions/batch", body); rec.Code != http.StatusOK {
+		t.Fatalf("POST = %d %s, want 200", rec.Code, rec.Body)
+	}
+
+	rec := serve(router, http.MethodGet, "/api/v1/subscriptions/"+testutil.Owner.String(), "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET = %d %s, want 200", rec.Code, rec.Body)
+	}
+	var page model.SubscriptionPage
+	decode(t, rec, &page)
+	if len(page.Items) != 0 {
+		t.Errorf("rolled back subscriptions are listed: %+v", page.Items)
+	}
+}
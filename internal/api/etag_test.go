@@ -0,0 +1,81 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestIfNoneMatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		want        int
+	}{
+		{name: "absent", want: http.StatusOK},
+		{name: "current version", ifNoneMatch: `"1"`, want: http.StatusNotModified},
+		{name: "weak current version", ifNoneMatch: `W/"1"`, want: http.StatusNotModified},
+		{name: "list with current version", ifNoneMatch: `"3", "1"`, want: http.StatusNotModified},
+		{name: "any version", ifNoneMatch: "*", want: http.StatusNotModified},
+		{name: "other version", ifNoneMatch: `"2"`, want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter()
+			id := subscribe(t, router)
+
+			rec := serve(router, http.MethodGet, "/api/v1/subscriptions/by-id/"+id, "", "If-None-Match", tt.ifNoneMatch)
+			if rec.Code != tt.want {
+				t.Fatalf("GET = %d, want %d", rec.Code, tt.want)
+			}
+			if got := rec.Header().Get("ETag"); got != `"1"` {
+				t.Errorf("ETag = %s, want \"1\"", got)
+			}
+			if tt.want == http.StatusNotModified && rec.Body.Len() > 0 {
+				t.Errorf("304 body = %q, want none", rec.Body)
+			}
+		})
+	}
+}
+
+func TestIfMatch(t *testing.T) {
+	update := `{"service_name":"Yandex Plus","price":150,"user_id":"00000000-0000-0000-0000-00000000000a","start_date":"2024-01-01T00:00:00Z"}`
+
+	tests := []struct {
+		name     string
+		method   string
+		body     string
+		ifMatch  string
+		want     int
+		wantETag string
+	}{
+		{name: "put without If-Match", method: http.MethodPut, body: update, want: http.StatusPreconditionRequired, wantETag: `"1"`},
+		{name: "put current version", method: http.MethodPut, body: update, ifMatch: `"1"`, want: http.StatusOK, wantETag: `"2"`},
+		{name: "put any version", method: http.MethodPut, body: update, ifMatch: "*", want: http.StatusOK, wantETag: `"2"`},
+		{name: "put stale version", method: http.MethodPut, body: update, ifMatch: `"2"`, want: http.StatusPreconditionFailed, wantETag: `"1"`},
+		{name: "put unquoted version", method: http.MethodPut, body: update, ifMatch: "1", want: http.StatusBadRequest, wantETag: `"1"`},
+		{name: "put zero version", method: http.MethodPut, body: update, ifMatch: `"0"`, want: http.StatusPreconditionFailed, wantETag: `"1"`},
+		{name: "patch current version", method: http.MethodPatch, body: `{"price":150}`, ifMatch: `"1"`, want: http.StatusOK, wantETag: `"2"`},
+		{name: "patch stale version", method: http.MethodPatch, body: `{"price":150}`, ifMatch: `"7"`, want: http.StatusPreconditionFailed, wantETag: `"1"`},
+		{name: "delete without If-Match", method: http.MethodDelete, want: http.StatusPreconditionRequired, wantETag: `"1"`},
+		{name: "delete stale version", method: http.MethodDelete, ifMatch: `"2"`, want: http.StatusPreconditionFailed, wantETag: `"1"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter()
+			id := subscribe(t, router)
+			target := "/api/v1/subscriptions/by-id/" + id
+
+			rec := serve(router, tt.method, target, tt.body, "Content-Type", "application/merge-patch+json", "If-Match", tt.ifMatch)
+			if rec.Code != tt.want {
+				t.Fatalf("%s = %d %s, want %d", tt.method, rec.Code, rec.Body, tt.want)
+			}
+
+			rec = serve(router, http.MethodGet, target, "")
+			if got := rec.Header().Get("ETag"); got != tt.wantETag {
+				t.Errorf("ETag after %s = %s, want %s", tt.method, got, tt.wantETag)
+			}
+		})
+	}
+}
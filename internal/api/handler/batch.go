@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"net/http"
+	apimw "subservice/internal/api/middleware"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"subservice/internal/service"
+	"subservice/internal/storage"
+	"time"
+)
+
+const (
+	BatchModeAtomic     = "atomic"
+	BatchModeBestEffort = "best_effort"
+)
+
+type BatchRequest struct {
+	// Mode defaults to atomic.
+	Mode       string                  `json:"mode,omitempty" example:"atomic" enums:"atomic,best_effort"`
+	Operations []BatchOperationRequest `json:"operations"`
+}
+
+// BatchOperationRequest carries Subscription for create and update; delete is
+// addressed by Id, or by UserId and ServiceName when the user has a single
+// current subscription to the service. Updates without a subscription id are
+// resolved the same way. Version is the expected subscription version for
+// update and delete, zero skips the check.
+type BatchOperationRequest struct {
+	Op           string               `json:"op" example:"create" enums:"create,update,delete"`
+	Subscription *SubscriptionRequest `json:"subscription,omitempty"`
+	Id           string               `json:"id,omitempty" example:"0b6f1a3e-8c2d-4e5f-9a7b-1c2d3e4f5a6b"`
+	UserId       string               `json:"user_id,omitempty" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	ServiceName  string               `json:"service_name,omitempty" example:"Yandex Plus"`
+	Version      int64                `json:"version,omitempty" example:"1"`
+}
+
+type BatchItemResult struct {
+	Index  int    `json:"index" example:"0"`
+	Status int    `json:"status" example:"201"`
+	Error  string `json:"error,omitempty" example:"subscription with this id already exists"`
+	Field  string `json:"field,omitempty" example:"start_date"`
+}
+
+type BatchResponse struct {
+	Applied int               `json:"applied" example:"2"`
+	Failed  int               `json:"failed" example:"1"`
+	Results []BatchItemResult `json:"results"`
+}
+
+// BatchSubscriptions godoc
+// @Summary      Пакетные операции с подписками
+// @Description  Выполняет до 1000 операций create/update/delete. В режиме atomic все операции выполняются в одной транзакции
+// @Description  и при первой ошибке откатываются (остальные получают статус 424); в режиме best_effort каждая операция выполняется отдельно.
+// @Description  Для каждой операции возвращается HTTP-статус и ошибка
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        body  body      BatchRequest  true  "Операции"
+// @Success      200   {object}  BatchResponse
+// @Failure      400   {object}  ErrorResponse   "invalid json / validation error"
+// @Failure      500   {object}  ErrorResponse   "internal server error"
+// @Router       /subscriptions/batch [post]
+func (h *RestHandler) BatchSubscriptions(w http.ResponseWriter, r *http.Request) {
+	l := apimw.FromContext(r.Context())
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	var req BatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		l.Warn("Handler BatchSubscriptions: invalid json")
+		respondError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	var atomic bool
+	switch req.Mode {
+	case "", BatchModeAtomic:
+		atomic = true
+	case BatchModeBestEffort:
+	default:
+		respondServiceError(w, r, domain.Validation("mode", "mode must be atomic or best_effort"))
+		return
+	}
+
+	if len(req.Operations) == 0 || len(req.Operations) > service.MaxBatchSize {
+		respondServiceError(w, r, domain.Validation("operations", "batch must contain 1 to %d operations", service.MaxBatchSize))
+		return
+	}
+
+	// Operations that fail request validation are not passed to the service;
+	// indexes maps the service's operations back to their request positions.
+	errs := make([]error, len(req.Operations))
+	ops := make([]model.BatchOperation, 0, len(req.Operations))
+	indexes := make([]int, 0, len(req.Operations))
+	for i, opReq := range req.Operations {
+		op, err := parseBatchOperation(opReq)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		ops = append(ops, op)
+		indexes = append(indexes, i)
+	}
+
+	if len(ops) < len(req.Operations) && atomic {
+		for i, err := range errs {
+			if err != nil {
+				storage.AbortBatch(errs, i, err)
+				break
+			}
+		}
+		respondJSON(w, http.StatusOK, batchResponse(r, req.Operations, errs))
+		return
+	}
+
+	if len(ops) > 0 {
+		opErrs, err := h.s.ApplyBatch(ctx, ops, atomic)
+		if err != nil {
+			respondServiceError(w, r, err)
+			return
+		}
+		for j, err := range opErrs {
+			errs[indexes[j]] = err
+		}
+	}
+	respondJSON(w, http.StatusOK, batchResponse(r, req.Operations, errs))
+}
+
+func parseBatchOperation(req BatchOperationRequest) (model.BatchOperation, error) {
+	op := model.BatchOperation{Op: req.Op}
+	switch req.Op {
+	case model.BatchCreate, model.BatchUpdate:
+		if req.Subscription == nil {
+			return op, domain.Validation("subscription", "subscription is required for %s", req.Op)
+		}
+		sub, err := ValidateSubscriptionRequest(req.Subscription)
+		if err != nil {
+			return op, err
+		}
+		op.Subscription = *sub
+	case model.BatchDelete:
+		if req.Id != "" {
+			id, err := parseSubscriptionId(req.Id)
+			if err != nil {
+				return op, err
+			}
+			op.Subscription = model.Subscription{Id: id}
+			break
+		}
+		userId, err := uuid.Parse(req.UserId)
+		if err != nil || userId == uuid.Nil {
+			return op, domain.Validation("user_id", "invalid user_id parameter")
+		}
+		if req.ServiceName == "" {
+			return op, domain.Validation("service_name", "service_name is required")
+		}
+		op.Subscription = model.Subscription{UserId: userId, ServiceName: req.ServiceName}
+	default:
+		return op, domain.Validation("op", "op must be one of create, update, delete")
+	}
+	if req.Version < 0 {
+		return op, domain.Validation("version", "version cannot be negative")
+	}
+	op.Subscription.Version = req.Version
+	return op, nil
+}
+
+func batchResponse(r *http.Request, ops []BatchOperationRequest, errs []error) BatchResponse {
+	resp := BatchResponse{Results: make([]BatchItemResult, len(errs))}
+	for i, err := range errs {
+		result := BatchItemResult{Index: i}
+		switch {
+		case err == nil:
+			resp.Applied++
+			result.Status = http.StatusOK
+			if ops[i].Op == model.BatchCreate {
+				result.Status = http.StatusCreated
+			}
+		default:
+			resp.Failed++
+			result.Status = statusFromError(err)
+			result.Error = err.Error()
+			if result.Status == http.StatusInternalServerError {
+				apimw.FromContext(r.Context()).Error("internal error", zap.Int("index", i), zap.Error(err))
+				result.Error = "internal server error"
+			}
+			var vErr *domain.ValidationError
+			if errors.As(err, &vErr) {
+				result.Field = vErr.Field
+			}
+		}
+		resp.Results[i] = result
+	}
+	return resp
+}
@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"net/http"
+	"strings"
+	apimw "subservice/internal/api/middleware"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"time"
+)
+
+type BudgetRequest struct {
+	// Category limits the budget to the services of a catalog category; the
+	// budget without it covers all subscriptions.
+	Category string `json:"category,omitempty" example:"video"`
+	Limit    int64  `json:"limit" example:"1500"`
+	// Currency is an ISO 4217 code and defaults to RUB.
+	Currency string `json:"currency,omitempty" example:"RUB"`
+}
+
+// SaveBudget godoc
+// @Summary      Сохранить бюджет пользователя
+// @Description  Создает или заменяет месячный лимит расходов пользователя на подписки: общий или, с category, на сервисы категории каталога
+// @Tags         budgets
+// @Accept       json
+// @Produce      json
+// @Param        userId  path      string         true  "User ID (UUID)"
+// @Param        body    body      BudgetRequest  true  "Бюджет"
+// @Success      200     {object}  model.Budget
+// @Failure      400     {object}  ErrorResponse   "invalid json / validation error"
+// @Failure      500     {object}  ErrorResponse   "internal server error"
+// @Router       /users/{userId}/budgets [put]
+func (h *RestHandler) SaveBudget(w http.ResponseWriter, r *http.Request) {
+	l := apimw.FromContext(r.Context())
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	userId, err := budgetUserId(r)
+	if err != nil {
+		l.Warn("Handler SaveBudget: invalid userId parameter")
+		respondServiceError(w, r, err)
+		return
+	}
+
+	var req BudgetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		l.Warn("Handler SaveBudget: invalid json")
+		respondError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	budget := model.Budget{
+		UserId:   userId,
+		Category: req.Category,
+		Limit:    req.Limit,
+		Currency: strings.ToUpper(req.Currency),
+	}
+	if budget.Currency != "" && !model.ValidCurrency(budget.Currency) {
+		respondServiceError(w, r, domain.Validation("currency", "currency must be a three-letter ISO 4217 code"))
+		return
+	}
+
+	saved, err := h.s.SaveBudget(ctx, budget)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, saved)
+}
+
+// ListBudgets godoc
+// @Summary      Бюджеты пользователя
+// @Tags         budgets
+// @Produce      json
+// @Param        userId  path      string  true  "User ID (UUID)"
+// @Success      200     {array}   model.Budget
+// @Failure      400     {object}  ErrorResponse
+// @Failure      500     {object}  ErrorResponse
+// @Router       /users/{userId}/budgets [get]
+func (h *RestHandler) ListBudgets(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	userId, err := budgetUserId(r)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	budgets, err := h.s.ListBudgets(ctx, userId)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, budgets)
+}
+
+// DeleteBudget godoc
+// @Summary      Удалить бюджет пользователя
+// @Description  Удаляет общий бюджет пользователя или, с category, бюджет категории
+// @Tags         budgets
+// @Produce      json
+// @Param        userId    path      string  true   "User ID (UUID)"
+// @Param        category  query     string  false  "Категория бюджета"
+// @Success      200       {object}  SuccessResponse "status: success"
+// @Failure      400       {object}  ErrorResponse
+// @Failure      404       {object}  ErrorResponse   "budget not found"
+// @Failure      500       {object}  ErrorResponse
+// @Router       /users/{userId}/budgets [delete]
+func (h *RestHandler) DeleteBudget(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	userId, err := budgetUserId(r)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	category := strings.TrimSpace(r.URL.Query().Get("category"))
+	if err := h.s.DeleteBudget(ctx, userId, category); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]string{"status": "success"})
+}
+
+// EvaluateBudgets godoc
+// @Summary      Проверить бюджеты пользователя
+// @Description  Сравнивает помесячную сумму подписок пользователя (как /subscriptions/summary с user_id, в валюте бюджета) с лимитом каждого бюджета за каждый месяц периода.
+// @Description  Месяцы сверх лимита отмечаются exceeded. Ничего не записывает; превышения фиксирует POST на тот же путь
+// @Tags         budgets
+// @Produce      json
+// @Param        userId    path      string  true   "User ID (UUID)"
+// @Param        from      query     string  true   "Первый месяц периода (RFC3339)"
+// @Param        to        query     string  true   "Последний месяц периода (RFC3339)"
+// @Param        category  query     string  false  "Проверить только бюджет категории; пустое значение — только общий бюджет"
+// @Success      200       {array}   model.BudgetEvaluation
+// @Failure      400       {object}  ErrorResponse   "validation error / no exchange rate"
+// @Failure      404       {object}  ErrorResponse   "budget not found"
+// @Failure      500       {object}  ErrorResponse
+// @Router       /users/{userId}/budgets/evaluation [get]
+func (h *RestHandler) EvaluateBudgets(w http.ResponseWriter, r *http.Request) {
+	l := apimw.FromContext(r.Context())
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	p, err := parseBudgetEvaluation(r)
+	if err != nil {
+		l.Warn("Handler EvaluateBudgets: invalid parameters")
+		respondServiceError(w, r, err)
+		return
+	}
+
+	evaluations, err := h.s.EvaluateBudgets(ctx, p.userId, p.from, p.to, p.category)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, evaluations)
+}
+
+// RecordBudgetAlerts godoc
+// @Summary      Зафиксировать превышения бюджетов
+// @Description  Проверяет бюджеты как GET /users/{userId}/budgets/evaluation и для уже начавшихся месяцев сверх лимита один раз записывает превышение в историю событием budget_exceeded
+// @Tags         budgets
+// @Produce      json
+// @Param        userId    path      string  true   "User ID (UUID)"
+// @Param        from      query     string  true   "Первый месяц периода (RFC3339)"
+// @Param        to        query     string  true   "Последний месяц периода (RFC3339)"
+// @Param        category  query     string  false  "Проверить только бюджет категории; пустое значение — только общий бюджет"
+// @Success      200       {array}   model.BudgetEvaluation
+// @Failure      400       {object}  ErrorResponse   "validation error / no exchange rate"
+// @Failure      404       {object}  ErrorResponse   "budget not found"
+// @Failure      500       {object}  ErrorResponse
+// @Router       /users/{userId}/budgets/evaluation [post]
+func (h *RestHandler) RecordBudgetAlerts(w http.ResponseWriter, r *http.Request) {
+	l := apimw.FromContext(r.Context())
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	p, err := parseBudgetEvaluation(r)
+	if err != nil {
+		l.Warn("Handler RecordBudgetAlerts: invalid parameters")
+		respondServiceError(w, r, err)
+		return
+	}
+
+	evaluations, err := h.s.RecordBudgetAlerts(ctx, p.userId, p.from, p.to, p.category)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, evaluations)
+}
+
+// budgetEvaluation holds the parameters of a budget evaluation request.
+type budgetEvaluation struct {
+	userId   uuid.UUID
+	from, to time.Time
+	category *string
+}
+
+func parseBudgetEvaluation(r *http.Request) (budgetEvaluation, error) {
+	var p budgetEvaluation
+	var err error
+	if p.userId, err = budgetUserId(r); err != nil {
+		return p, err
+	}
+
+	q := r.URL.Query()
+	if q.Get("from") == "" || q.Get("to") == "" {
+		return p, domain.Validation("from", "from and to parameters are required")
+	}
+	if p.from, err = time.Parse(time.RFC3339, q.Get("from")); err != nil {
+		return p, domain.Validation("from", "invalid from date format")
+	}
+	if p.to, err = time.Parse(time.RFC3339, q.Get("to")); err != nil {
+		return p, domain.Validation("to", "invalid to date format")
+	}
+
+	if c, ok := q["category"]; ok {
+		trimmed := strings.TrimSpace(c[0])
+		p.category = &trimmed
+	}
+	return p, nil
+}
+
+func budgetUserId(r *http.Request) (uuid.UUID, error) {
+	userId, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil || userId == uuid.Nil {
+		return uuid.Nil, domain.Validation("user_id", "invalid userId parameter")
+	}
+	return userId, nil
+}
@@ -0,0 +1,270 @@
+package handler
+
+import "net/http"
+
+// The handlers below serve the /subscriptions/by-id/{id} routes. They share the
+// implementation of the routes addressing a subscription by user and service,
+// which read the {id} path parameter when it is present.
+
+// GetSubscriptionById godoc
+// @Summary      Получить подписку по id
+// @Description  Возвращает подписку с текущим статусом (scheduled, active, paused, ended). Версия подписки передаётся в заголовке ETag
+// @Tags         subscriptions
+// @Produce      json
+// @Param        id             path      string  true   "ID подписки (UUID)"
+// @Param        If-None-Match  header    string  false  "ETag известной клиенту версии"
+// @Success      200            {object}  model.Subscription
+// @Header       200            {string}  ETag  "Версия подписки"
+// @Success      304            "Подписка не изменилась"
+// @Failure      400            {object}  ErrorResponse   "invalid id parameter"
+// @Failure      404            {object}  ErrorResponse   "subscription not found"
+// @Failure      500            {object}  ErrorResponse
+// @Router       /subscriptions/by-id/{id} [get]
+func (h *RestHandler) GetSubscriptionById(w http.ResponseWriter, r *http.Request) {
+	h.GetSubscription(w, r)
+}
+
+// UpdateSubscriptionById godoc
+// @Summary      Обновить подписку по id
+// @Description  Заменяет данные подписки. id в теле можно не указывать; пользователя и сервис подписки изменить нельзя.
+// @Description  Новая цена действует с текущего месяца и попадает в историю цен, прошлые месяцы считаются по прежней.
+// @Description  Заголовок If-Match должен содержать ETag текущей версии или "*"
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        id        path      string               true  "ID подписки (UUID)"
+// @Param        If-Match  header    string               true  "ETag версии подписки"
+// @Param        body      body      SubscriptionRequest  true  "Данные подписки"
+// @Success      200       {object}  SuccessResponse "status: success"
+// @Failure      400       {object}  ErrorResponse   "validation error"
+// @Failure      404       {object}  ErrorResponse   "subscription not found"
+// @Failure      412       {object}  ErrorResponse   "subscription version mismatch"
+// @Failure      428       {object}  ErrorResponse   "If-Match header is required"
+// @Failure      500       {object}  ErrorResponse   "internal server error"
+// @Router       /subscriptions/by-id/{id} [put]
+func (h *RestHandler) UpdateSubscriptionById(w http.ResponseWriter, r *http.Request) {
+	h.UpdateSubscription(w, r)
+}
+
+// PatchSubscriptionById godoc
+// @Summary      Частично обновить подписку по id
+// @Description  Применяет JSON Merge Patch (RFC 7396) к подписке по тем же правилам, что и PATCH /subscriptions/{userId}/{serviceName}.
+// @Description  Заголовок If-Match должен содержать ETag текущей версии или "*"
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        id        path      string               true  "ID подписки (UUID)"
+// @Param        If-Match  header    string               true  "ETag версии подписки"
+// @Param        body      body      SubscriptionRequest  true  "Изменяемые поля"
+// @Success      200       {object}  model.Subscription
+// @Header       200       {string}  ETag  "Новая версия подписки"
+// @Failure      400       {object}  ErrorResponse   "invalid json / validation error"
+// @Failure      404       {object}  ErrorResponse   "subscription not found"
+// @Failure      412       {object}  ErrorResponse   "subscription version mismatch"
+// @Failure      415       {object}  ErrorResponse   "unsupported content type"
+// @Failure      428       {object}  ErrorResponse   "If-Match header is required"
+// @Failure      500       {object}  ErrorResponse   "internal server error"
+// @Router       /subscriptions/by-id/{id} [patch]
+func (h *RestHandler) PatchSubscriptionById(w http.ResponseWriter, r *http.Request) {
+	h.PatchSubscription(w, r)
+}
+
+// UnsubscribeById godoc
+// @Summary      Удалить подписку по id
+// @Description  Помечает подписку удалённой. До очистки её можно восстановить.
+// @Description  Заголовок If-Match должен содержать ETag текущей версии или "*"
+// @Tags         subscriptions
+// @Produce      json
+// @Param        id        path      string  true  "ID подписки (UUID)"
+// @Param        If-Match  header    string  true  "ETag версии подписки"
+// @Success      200       {object}  SuccessResponse "status: success"
+// @Failure      400       {object}  ErrorResponse   "invalid id parameter"
+// @Failure      404       {object}  ErrorResponse   "subscription not found"
+// @Failure      412       {object}  ErrorResponse   "subscription version mismatch"
+// @Failure      428       {object}  ErrorResponse   "If-Match header is required"
+// @Failure      500       {object}  ErrorResponse   "internal server error"
+// @Router       /subscriptions/by-id/{id} [delete]
+func (h *RestHandler) UnsubscribeById(w http.ResponseWriter, r *http.Request) {
+	h.Unsubscribe(w, r)
+}
+
+// RestoreSubscriptionById godoc
+// @Summary      Восстановить подписку по id
+// @Description  Снимает пометку об удалении с подписки, если она ещё не очищена
+// @Tags         subscriptions
+// @Produce      json
+// @Param        id   path      string  true  "ID подписки (UUID)"
+// @Success      200  {object}  SuccessResponse "status: success"
+// @Failure      400  {object}  ErrorResponse   "invalid id parameter"
+// @Failure      404  {object}  ErrorResponse   "deleted subscription not found"
+// @Failure      500  {object}  ErrorResponse   "internal server error"
+// @Router       /subscriptions/by-id/{id}/restore [post]
+func (h *RestHandler) RestoreSubscriptionById(w http.ResponseWriter, r *http.Request) {
+	h.RestoreSubscription(w, r)
+}
+
+// GetSubscriptionHistoryById godoc
+// @Summary      История изменений подписки
+// @Description  Возвращает журнал изменений одной подписки (от новых к старым) со снимками до и после изменения
+// @Tags         subscriptions
+// @Produce      json
+// @Param        id         path      string  true   "ID подписки (UUID)"
+// @Param        from       query     string  false  "Начало периода (RFC3339)"
+// @Param        to         query     string  false  "Конец периода (RFC3339)"
+// @Param        before_id  query     int     false  "Вернуть события с id меньше указанного"
+// @Param        limit      query     int     false  "Размер страницы (по умолчанию 50, максимум 500)"
+// @Success      200        {array}   model.SubscriptionEvent
+// @Failure      400        {object}  ErrorResponse
+// @Failure      500        {object}  ErrorResponse
+// @Router       /subscriptions/by-id/{id}/history [get]
+func (h *RestHandler) GetSubscriptionHistoryById(w http.ResponseWriter, r *http.Request) {
+	h.GetSubscriptionHistory(w, r)
+}
+
+// SchedulePriceChangeById godoc
+// @Summary      Запланировать изменение цены подписки по id
+// @Description  Устанавливает новую цену подписки начиная с указанного месяца. Прошлые месяцы считаются по прежней цене
+// @Tags         prices
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string              true  "ID подписки (UUID)"
+// @Param        body  body      PriceChangeRequest  true  "Новая цена"
+// @Success      201   {object}  SuccessResponse "status: success"
+// @Failure      400   {object}  ErrorResponse   "validation error / effective_from outside of subscription period"
+// @Failure      404   {object}  ErrorResponse   "subscription not found"
+// @Failure      500   {object}  ErrorResponse   "internal server error"
+// @Router       /subscriptions/by-id/{id}/prices [post]
+func (h *RestHandler) SchedulePriceChangeById(w http.ResponseWriter, r *http.Request) {
+	h.SchedulePriceChange(w, r)
+}
+
+// GetPriceHistoryById godoc
+// @Summary      История цен подписки по id
+// @Description  Возвращает запланированные и прошедшие изменения цены подписки
+// @Tags         prices
+// @Produce      json
+// @Param        id   path      string  true  "ID подписки (UUID)"
+// @Success      200  {array}   model.PriceChange
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse   "subscription not found"
+// @Failure      500  {object}  ErrorResponse
+// @Router       /subscriptions/by-id/{id}/prices [get]
+func (h *RestHandler) GetPriceHistoryById(w http.ResponseWriter, r *http.Request) {
+	h.GetPriceHistory(w, r)
+}
+
+// PauseSubscriptionById godoc
+// @Summary      Приостановить подписку по id
+// @Description  Приостанавливает подписку с месяца from до месяца resume_from (не включая его) или до возобновления. Приостановленные месяцы не учитываются в сумме.
+// @Description  Без тела запроса пауза начинается с текущего месяца и длится до возобновления. Версия подписки (ETag) увеличивается
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string        true   "ID подписки (UUID)"
+// @Param        body  body      PauseRequest  false  "Период паузы"
+// @Success      201   {object}  SuccessResponse "status: success"
+// @Failure      400   {object}  ErrorResponse   "validation error / pause outside of subscription period"
+// @Failure      404   {object}  ErrorResponse   "subscription not found"
+// @Failure      409   {object}  ErrorResponse   "pause overlaps an existing pause"
+// @Failure      500   {object}  ErrorResponse   "internal server error"
+// @Router       /subscriptions/by-id/{id}/pause [post]
+func (h *RestHandler) PauseSubscriptionById(w http.ResponseWriter, r *http.Request) {
+	h.PauseSubscription(w, r)
+}
+
+// ResumeSubscriptionById godoc
+// @Summary      Возобновить подписку по id
+// @Description  Завершает действующую паузу с месяца resume_from (по умолчанию текущий). Пауза, которая к этому месяцу ещё не началась, отменяется. Версия подписки (ETag) увеличивается
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string         true   "ID подписки (UUID)"
+// @Param        body  body      ResumeRequest  false  "Месяц возобновления"
+// @Success      200   {object}  SuccessResponse "status: success"
+// @Failure      400   {object}  ErrorResponse
+// @Failure      404   {object}  ErrorResponse   "subscription not found"
+// @Failure      409   {object}  ErrorResponse   "subscription is not paused"
+// @Failure      500   {object}  ErrorResponse
+// @Router       /subscriptions/by-id/{id}/resume [post]
+func (h *RestHandler) ResumeSubscriptionById(w http.ResponseWriter, r *http.Request) {
+	h.ResumeSubscription(w, r)
+}
+
+// GetPausesById godoc
+// @Summary      Паузы подписки по id
+// @Description  Возвращает прошедшие, текущие и запланированные паузы подписки
+// @Tags         subscriptions
+// @Produce      json
+// @Param        id   path      string  true  "ID подписки (UUID)"
+// @Success      200  {array}   model.Pause
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse   "subscription not found"
+// @Failure      500  {object}  ErrorResponse
+// @Router       /subscriptions/by-id/{id}/pauses [get]
+func (h *RestHandler) GetPausesById(w http.ResponseWriter, r *http.Request) {
+	h.GetPauses(w, r)
+}
+
+// ApplyPromotionById godoc
+// @Summary      Применить промокод к подписке по id
+// @Description  Применяет скидку к подписке с месяца applied_from (по умолчанию текущий) на duration_months месяцев. Промокоды подписки не должны пересекаться по месяцам; завершённые сохраняются. Суммы в сводке учитывают скидку
+// @Tags         promotions
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string                 true  "ID подписки (UUID)"
+// @Param        body  body      ApplyPromotionRequest  true  "Промокод"
+// @Success      201   {object}  model.AppliedPromotion
+// @Failure      400   {object}  ErrorResponse   "validation error / promotion does not apply to the service"
+// @Failure      404   {object}  ErrorResponse   "subscription or promotion not found"
+// @Failure      409   {object}  ErrorResponse   "promotion overlaps an applied promotion"
+// @Failure      500   {object}  ErrorResponse
+// @Router       /subscriptions/by-id/{id}/promotion [post]
+func (h *RestHandler) ApplyPromotionById(w http.ResponseWriter, r *http.Request) {
+	h.ApplyPromotion(w, r)
+}
+
+// GetAppliedPromotionById godoc
+// @Summary      Промокод подписки по id
+// @Description  Возвращает промокод, действующий в текущем месяце, или ближайший запланированный
+// @Tags         promotions
+// @Produce      json
+// @Param        id   path      string  true  "ID подписки (UUID)"
+// @Success      200  {object}  model.AppliedPromotion
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse   "subscription has no promotion"
+// @Failure      500  {object}  ErrorResponse
+// @Router       /subscriptions/by-id/{id}/promotion [get]
+func (h *RestHandler) GetAppliedPromotionById(w http.ResponseWriter, r *http.Request) {
+	h.GetAppliedPromotion(w, r)
+}
+
+// ListAppliedPromotionsById godoc
+// @Summary      Промокоды подписки по id
+// @Description  Возвращает прошедшие, текущие и запланированные промокоды подписки
+// @Tags         promotions
+// @Produce      json
+// @Param        id   path      string  true  "ID подписки (UUID)"
+// @Success      200  {array}   model.AppliedPromotion
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse   "subscription not found"
+// @Failure      500  {object}  ErrorResponse
+// @Router       /subscriptions/by-id/{id}/promotions [get]
+func (h *RestHandler) ListAppliedPromotionsById(w http.ResponseWriter, r *http.Request) {
+	h.ListAppliedPromotions(w, r)
+}
+
+// RemovePromotionById godoc
+// @Summary      Отменить промокод подписки по id
+// @Description  Завершает действующий промокод с месяца from (по умолчанию текущий); скидка прошлых месяцев сохраняется. Промокод, который к этому месяцу ещё не начал действовать, отменяется
+// @Tags         promotions
+// @Produce      json
+// @Param        id    path      string  true   "ID подписки (UUID)"
+// @Param        from  query     string  false  "Месяц окончания скидки (RFC3339)"
+// @Success      200  {object}  SuccessResponse "status: success"
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse   "subscription has no promotion"
+// @Failure      500  {object}  ErrorResponse
+// @Router       /subscriptions/by-id/{id}/promotion [delete]
+func (h *RestHandler) RemovePromotionById(w http.ResponseWriter, r *http.Request) {
+	h.RemovePromotion(w, r)
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"net/http"
+	"strings"
+	apimw "subservice/internal/api/middleware"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"time"
+)
+
+// GetCalendar godoc
+// @Summary      Календарь списаний
+// @Description  Возвращает iCalendar (RFC 5545) с событиями на весь день для каждого списания по подпискам пользователя и для окончания подписок.
+// @Description  Сумма списания учитывает примененные промокоды, для совместных подписок — доля пользователя.
+// @Description  По умолчанию охватывает год начиная с сегодняшнего дня
+// @Tags         subscriptions
+// @Produce      text/calendar
+// @Param        userId  path      string  true   "User ID (UUID)"
+// @Param        from    query     string  false  "Начало периода (RFC3339), по умолчанию сегодня"
+// @Param        to      query     string  false  "Конец периода (RFC3339), по умолчанию через год после from"
+// @Success      200     {file}    file
+// @Failure      400     {object}  ErrorResponse
+// @Failure      500     {object}  ErrorResponse
+// @Router       /users/{userId}/calendar.ics [get]
+func (h *RestHandler) GetCalendar(w http.ResponseWriter, r *http.Request) {
+	l := apimw.FromContext(r.Context())
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	userId, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil || userId == uuid.Nil {
+		l.Warn("Handler GetCalendar: invalid userId parameter")
+		respondServiceError(w, r, domain.Validation("user_id", "invalid userId parameter"))
+		return
+	}
+
+	q := r.URL.Query()
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if fromStr := q.Get("from"); fromStr != "" {
+		if from, err = time.Parse(time.RFC3339, fromStr); err != nil {
+			respondServiceError(w, r, domain.Validation("from", "invalid from format"))
+			return
+		}
+	}
+	to := from.AddDate(1, 0, 0)
+	if toStr := q.Get("to"); toStr != "" {
+		if to, err = time.Parse(time.RFC3339, toStr); err != nil {
+			respondServiceError(w, r, domain.Validation("to", "invalid to format"))
+			return
+		}
+	}
+
+	events, err := h.s.GetCalendar(ctx, userId, from, to)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="subscriptions.ics"`)
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(renderCalendar(events, now)); err != nil {
+		l.Error("Handler GetCalendar: write failed", zap.Error(err))
+	}
+}
+
+// renderCalendar encodes events as a VCALENDAR. UIDs are derived from the
+// subscription and date, so calendar clients update events in place on refresh.
+func renderCalendar(events []model.CalendarEvent, stamp time.Time) []byte {
+	var b bytes.Buffer
+	line := func(s string) {
+		b.WriteString(foldLine(s))
+		b.WriteString("\r\n")
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//subservice//subscriptions//EN")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	line("X-WR-CALNAME:Subscriptions")
+	for _, e := range events {
+		var summary, description string
+		switch e.Kind {
+		case model.CalendarEnd:
+			summary = fmt.Sprintf("%s ends", e.ServiceName)
+			description = fmt.Sprintf("Last paid day of the %s subscription.", e.ServiceName)
+		default:
+			summary = fmt.Sprintf("%s: %d %s", e.ServiceName, e.Price, e.Currency)
+			description = fmt.Sprintf("Charge of %d %s for %s.", e.Price, e.Currency, e.ServiceName)
+		}
+
+		line("BEGIN:VEVENT")
+		line("UID:" + calendarUID(e))
+		line("DTSTAMP:" + stamp.UTC().Format("20060102T150405Z"))
+		line("DTSTART;VALUE=DATE:" + e.Date.Format("20060102"))
+		line("DTEND;VALUE=DATE:" + e.Date.AddDate(0, 0, 1).Format("20060102"))
+		line("SUMMARY:" + escapeText(summary))
+		line("DESCRIPTION:" + escapeText(description))
+		line("TRANSP:TRANSPARENT")
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	return b.Bytes()
+}
+
+func calendarUID(e model.CalendarEvent) string {
+	sum := sha1.Sum([]byte(e.SubscriptionId.String()))
+	return fmt.Sprintf("%s-%s-%s@subservice", e.Kind, e.Date.Format("20060102"), hex.EncodeToString(sum[:8]))
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// escapeText escapes a TEXT property value (RFC 5545, section 3.3.11).
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// foldLine splits content lines longer than 75 octets (RFC 5545, section 3.1)
+// without breaking UTF-8 sequences.
+func foldLine(s string) string {
+	const limit = 75
+	if len(s) <= limit {
+		return s
+	}
+
+	var b strings.Builder
+	width := 0
+	for _, r := range s {
+		n := len(string(r))
+		if width+n > limit {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += n
+	}
+	return b.String()
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestEscapeText(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "Yandex Plus", want: "Yandex Plus"},
+		{in: `a\b`, want: `a\\b`},
+		{in: "a;b,c", want: `a\;b\,c`},
+		{in: "a\r\nb\nc", want: `a\nb\nc`},
+		{in: `\;`, want: `\\\;`},
+	}
+
+	for _, tt := range tests {
+		if got := escapeText(tt.in); got != tt.want {
+			t.Errorf("escapeText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFoldLine(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "short", in: "SUMMARY:Yandex Plus", want: "SUMMARY:Yandex Plus"},
+		{name: "exactly 75 octets", in: strings.Repeat("a", 75), want: strings.Repeat("a", 75)},
+		{name: "76 octets", in: strings.Repeat("a", 76), want: strings.Repeat("a", 75) + "\r\n a"},
+		{
+			name: "continuation lines count the leading space",
+			in:   strings.Repeat("a", 75+74+1),
+			want: strings.Repeat("a", 75) + "\r\n " + strings.Repeat("a", 74) + "\r\n a",
+		},
+		{
+			name: "multibyte rune is not split",
+			in:   strings.Repeat("a", 74) + "ё",
+			want: strings.Repeat("a", 74) + "\r\n ё",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := foldLine(tt.in); got != tt.want {
+				t.Errorf("foldLine() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFoldLineUnfolds(t *testing.T) {
+	in := "DESCRIPTION:" + strings.Repeat("Подписка на Яндекс Плюс, ", 10)
+
+	folded := foldLine(in)
+	for _, line := range strings.Split(folded, "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line of %d octets: %q", len(line), line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("line is not valid UTF-8: %q", line)
+		}
+	}
+	if got := strings.ReplaceAll(folded, "\r\n ", ""); got != in {
+		t.Errorf("unfolded = %q, want %q", got, in)
+	}
+}
@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+	"net/http"
+	"net/url"
+	apimw "subservice/internal/api/middleware"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"time"
+)
+
+type CatalogServiceRequest struct {
+	Name         string   `json:"name" example:"Yandex Plus"`
+	Aliases      []string `json:"aliases,omitempty" example:"Яндекс Плюс"`
+	Category     string   `json:"category,omitempty" example:"video"`
+	DefaultPrice *int64   `json:"default_price,omitempty" example:"299"`
+}
+
+func (req CatalogServiceRequest) toModel() model.CatalogService {
+	return model.CatalogService{
+		Name:         req.Name,
+		Aliases:      req.Aliases,
+		Category:     req.Category,
+		DefaultPrice: req.DefaultPrice,
+	}
+}
+
+// serviceNameFromPath reads the {name} path parameter, which may contain spaces.
+func serviceNameFromPath(r *http.Request) (string, error) {
+	name, err := url.PathUnescape(chi.URLParam(r, "name"))
+	if err != nil || name == "" {
+		return "", domain.Validation("name", "invalid name parameter")
+	}
+	return name, nil
+}
+
+// CreateCatalogService godoc
+// @Summary      Добавить сервис в каталог
+// @Description  Создает сервис с каноническим названием и синонимами. Подписки на синоним или название в другом регистре сохраняются под каноническим названием, в том числе уже существующие: их переименование записывается в журнал изменений.
+// @Description  default_price — цена новых подписок на сервис, созданных без price
+// @Tags         services
+// @Accept       json
+// @Produce      json
+// @Param        body  body      CatalogServiceRequest  true  "Сервис"
+// @Success      201   {object}  model.CatalogService
+// @Failure      400   {object}  ErrorResponse   "invalid json / validation error"
+// @Failure      409   {object}  ErrorResponse   "name or alias already refers to another service"
+// @Failure      500   {object}  ErrorResponse   "internal server error"
+// @Router       /services [post]
+func (h *RestHandler) CreateCatalogService(w http.ResponseWriter, r *http.Request) {
+	l := apimw.FromContext(r.Context())
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	var req CatalogServiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		l.Warn("Handler CreateCatalogService: invalid json")
+		respondError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	svc, err := h.s.CreateCatalogService(ctx, req.toModel())
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, svc)
+}
+
+// ListCatalogServices godoc
+// @Summary      Каталог сервисов
+// @Tags         services
+// @Produce      json
+// @Param        category  query     string  false  "Категория"
+// @Success      200       {array}   model.CatalogService
+// @Failure      500       {object}  ErrorResponse
+// @Router       /services [get]
+func (h *RestHandler) ListCatalogServices(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	var category *string
+	if c := r.URL.Query().Get("category"); c != "" {
+		category = &c
+	}
+
+	services, err := h.s.ListCatalogServices(ctx, category)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, services)
+}
+
+// GetCatalogService godoc
+// @Summary      Сервис из каталога
+// @Tags         services
+// @Produce      json
+// @Param        name  path      string  true  "Каноническое название"
+// @Success      200   {object}  model.CatalogService
+// @Failure      404   {object}  ErrorResponse   "service not found"
+// @Failure      500   {object}  ErrorResponse
+// @Router       /services/{name} [get]
+func (h *RestHandler) GetCatalogService(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	name, err := serviceNameFromPath(r)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	svc, err := h.s.GetCatalogService(ctx, name)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, svc)
+}
+
+// UpdateCatalogService godoc
+// @Summary      Обновить сервис в каталоге
+// @Description  Заменяет синонимы, категорию и цену по умолчанию. Каноническое название берется из пути и не меняется. Существующие подписки на новые синонимы переименовываются в каноническое название, переименование записывается в журнал изменений.
+// @Tags         services
+// @Accept       json
+// @Produce      json
+// @Param        name  path      string                 true  "Каноническое название"
+// @Param        body  body      CatalogServiceRequest  true  "Сервис"
+// @Success      200   {object}  model.CatalogService
+// @Failure      400   {object}  ErrorResponse
+// @Failure      404   {object}  ErrorResponse   "service not found"
+// @Failure      409   {object}  ErrorResponse   "alias already refers to another service"
+// @Failure      500   {object}  ErrorResponse
+// @Router       /services/{name} [put]
+func (h *RestHandler) UpdateCatalogService(w http.ResponseWriter, r *http.Request) {
+	l := apimw.FromContext(r.Context())
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	name, err := serviceNameFromPath(r)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	var req CatalogServiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		l.Warn("Handler UpdateCatalogService: invalid json")
+		respondError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if req.Name != "" && req.Name != name {
+		l.Warn("Handler UpdateCatalogService: name mismatch", zap.String("name", req.Name))
+		respondServiceError(w, r, domain.Validation("name", "name cannot be changed"))
+		return
+	}
+	req.Name = name
+
+	svc, err := h.s.UpdateCatalogService(ctx, req.toModel())
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, svc)
+}
+
+// DeleteCatalogService godoc
+// @Summary      Удалить сервис из каталога
+// @Description  Удаляет сервис и его синонимы. Существующие подписки не меняются
+// @Tags         services
+// @Produce      json
+// @Param        name  path      string  true  "Каноническое название"
+// @Success      200   {object}  SuccessResponse "status: success"
+// @Failure      404   {object}  ErrorResponse   "service not found"
+// @Failure      500   {object}  ErrorResponse
+// @Router       /services/{name} [delete]
+func (h *RestHandler) DeleteCatalogService(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	name, err := serviceNameFromPath(r)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	if err := h.s.DeleteCatalogService(ctx, name); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]string{"status": "success"})
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"subservice/internal/domain"
+)
+
+// errIfMatchRequired is answered with 428 Precondition Required.
+var errIfMatchRequired = errors.New("If-Match header is required")
+
+// etag formats a subscription version as a strong entity tag.
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// versionFromIfMatch returns the version a conditional write expects to
+// replace. "*" matches any version and is returned as zero.
+func versionFromIfMatch(r *http.Request) (int64, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	switch {
+	case value == "":
+		return 0, errIfMatchRequired
+	case value == "*":
+		return 0, nil
+	}
+
+	unquoted, err := strconv.Unquote(value)
+	if err != nil {
+		return 0, domain.Validation("If-Match", "If-Match must hold a single strong ETag")
+	}
+	version, err := strconv.ParseInt(unquoted, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, domain.PreconditionFailed("If-Match does not match the subscription version")
+	}
+	return version, nil
+}
+
+// notModified reports whether If-None-Match already names the current version.
+// Weak comparison is used, as RFC 9110 requires for If-None-Match.
+func notModified(r *http.Request, version int64) bool {
+	value := r.Header.Get("If-None-Match")
+	if value == "" {
+		return false
+	}
+	current := etag(version)
+	for _, tag := range strings.Split(value, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == current {
+			return true
+		}
+	}
+	return false
+}
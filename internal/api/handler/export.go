@@ -0,0 +1,311 @@
+package handler
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"strings"
+	apimw "subservice/internal/api/middleware"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"time"
+)
+
+// exportFlushEvery is the number of rows written between flushes.
+const exportFlushEvery = 100
+
+// exportWriter writes rows as CSV or NDJSON. The response header is sent with
+// the first row, so errors found before it can still be answered normally.
+type exportWriter struct {
+	w        http.ResponseWriter
+	format   string
+	filename string
+	header   []string
+	csv      *csv.Writer
+	json     *json.Encoder
+	started  bool
+	rows     int
+}
+
+func newExportWriter(w http.ResponseWriter, format, filename string, header []string) *exportWriter {
+	return &exportWriter{w: w, format: format, filename: filename, header: header}
+}
+
+func (e *exportWriter) start() error {
+	e.started = true
+	if e.format == FormatCSV {
+		e.w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		e.w.Header().Set("Content-Disposition", `attachment; filename="`+e.filename+`.csv"`)
+		e.w.WriteHeader(http.StatusOK)
+		e.csv = csv.NewWriter(e.w)
+		return e.csv.Write(e.header)
+	}
+	e.w.Header().Set("Content-Type", "application/x-ndjson")
+	e.w.Header().Set("Content-Disposition", `attachment; filename="`+e.filename+`.ndjson"`)
+	e.w.WriteHeader(http.StatusOK)
+	e.json = json.NewEncoder(e.w)
+	return nil
+}
+
+// write emits one row: record for CSV and v for NDJSON.
+func (e *exportWriter) write(record []string, v interface{}) error {
+	if !e.started {
+		if err := e.start(); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	if e.csv != nil {
+		err = e.csv.Write(record)
+	} else {
+		err = e.json.Encode(v)
+	}
+	if err != nil {
+		return err
+	}
+
+	e.rows++
+	if e.rows%exportFlushEvery == 0 {
+		e.flush()
+	}
+	return nil
+}
+
+func (e *exportWriter) finish() error {
+	if !e.started {
+		if err := e.start(); err != nil {
+			return err
+		}
+	}
+	e.flush()
+	if e.csv != nil {
+		return e.csv.Error()
+	}
+	return nil
+}
+
+func (e *exportWriter) flush() {
+	if e.csv != nil {
+		e.csv.Flush()
+	}
+	if f, ok := e.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func exportFormat(r *http.Request) (string, error) {
+	switch format := r.URL.Query().Get("format"); format {
+	case "", FormatCSV:
+		return FormatCSV, nil
+	case FormatNDJSON:
+		return FormatNDJSON, nil
+	default:
+		return "", domain.Validation("format", "format must be csv or ndjson")
+	}
+}
+
+// ExportSubscriptions godoc
+// @Summary      Экспорт подписок
+// @Description  Выгружает подписки в CSV или NDJSON построчно, без постраничной разбивки. Фильтры и сортировка те же, что у списка подписок.
+// @Description  Колонки и поля совпадают с SubscriptionRequest, поэтому выгрузку можно загрузить обратно через импорт.
+// @Description  В CSV теги и участники перечисляются через «;», участник записывается как user_id или user_id:share
+// @Tags         subscriptions
+// @Produce      text/csv
+// @Produce      application/x-ndjson
+// @Param        format               query     string  false  "Формат выгрузки (по умолчанию csv)" Enums(csv, ndjson)
+// @Param        user_id              query     string  false  "User ID (UUID)"
+// @Param        service_name_prefix  query     string  false  "Префикс названия сервиса"
+// @Param        tag                  query     string  false  "Только подписки с тегом"
+// @Param        active_at            query     string  false  "Активна на дату (RFC3339)"
+// @Param        min_price            query     int     false  "Минимальная цена"
+// @Param        max_price            query     int     false  "Максимальная цена"
+// @Param        sort                 query     string  false  "Поле сортировки" Enums(service_name, price, start_date)
+// @Param        order                query     string  false  "Направление сортировки" Enums(asc, desc)
+// @Param        limit                query     int     false  "Максимальное число строк (по умолчанию без ограничения)"
+// @Success      200                  {file}    file
+// @Failure      400                  {object}  ErrorResponse
+// @Failure      500                  {object}  ErrorResponse
+// @Router       /subscriptions/export [get]
+func (h *RestHandler) ExportSubscriptions(w http.ResponseWriter, r *http.Request) {
+	l := apimw.FromContext(r.Context())
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Minute)
+	defer cancel()
+
+	format, err := exportFormat(r)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	filter, err := parseListFilter(r)
+	if err != nil {
+		l.Warn("Handler ExportSubscriptions: invalid parameters", zap.Error(err))
+		respondServiceError(w, r, err)
+		return
+	}
+
+	if userIdStr := r.URL.Query().Get("user_id"); userIdStr != "" {
+		userId, err := uuid.Parse(userIdStr)
+		if err != nil || userId == uuid.Nil {
+			respondServiceError(w, r, domain.Validation("user_id", "invalid user_id parameter"))
+			return
+		}
+		filter.UserId = &userId
+	}
+
+	out := newExportWriter(w, format, "subscriptions", []string{
+		"id", "service_name", "price", "user_id", "start_date", "end_date", "billing_period", "billing_interval", "currency", "trial_end_date", "tags", "split", "members",
+	})
+	err = h.s.ExportSubscriptions(ctx, filter, func(sub model.Subscription) error {
+		req := requestFromSubscription(&sub)
+		return out.write([]string{
+			req.Id,
+			req.ServiceName,
+			strconv.FormatInt(req.Price, 10),
+			req.UserId,
+			req.StartDate,
+			req.EndDate,
+			req.BillingPeriod,
+			strconv.Itoa(req.BillingInterval),
+			req.Currency,
+			req.TrialEndDate,
+			strings.Join(req.Tags, csvTagSeparator),
+			req.Split,
+			formatCSVMembers(req.Members),
+		}, req)
+	})
+	if err == nil {
+		err = out.finish()
+	}
+	if err != nil {
+		if !out.started {
+			respondServiceError(w, r, err)
+			return
+		}
+		// The status line is already sent; the client sees a truncated body.
+		l.Error("Handler ExportSubscriptions: export interrupted", zap.Int("rows", out.rows), zap.Error(err))
+		return
+	}
+	l.Info("Subscriptions exported", zap.String("format", format), zap.Int("rows", out.rows))
+}
+
+// ExportSubscriptionSummary godoc
+// @Summary      Экспорт помесячной сводки
+// @Description  Выгружает разбивку суммы подписок в CSV или NDJSON. Параметры те же, что у /subscriptions/summary; без group_by разбивка строится по месяцам
+// @Tags         subscriptions
+// @Produce      text/csv
+// @Produce      application/x-ndjson
+// @Param        format           query     string  false  "Формат выгрузки (по умолчанию csv)" Enums(csv, ndjson)
+// @Param        from             query     string  true   "Начало периода (RFC3339)"
+// @Param        to               query     string  true   "Конец периода (RFC3339)"
+// @Param        user_id          query     string  false  "User ID (UUID)"
+// @Param        service_name     query     string  false  "Название сервиса"
+// @Param        tag              query     string  false  "Только подписки с тегом"
+// @Param        category         query     string  false  "Только подписки на сервисы категории каталога"
+// @Param        group_by         query     string  false  "Группировка через запятую: month, service_name, user_id, tag"
+// @Param        target_currency  query     string  false  "Валюта пересчёта (ISO 4217); обязательна, если у подписок разные валюты"
+// @Param        amortize         query     bool    false  "Распределять стоимость длинных периодов по месяцам"
+// @Success      200              {file}    file
+// @Failure      400              {object}  ErrorResponse
+// @Failure      500              {object}  ErrorResponse
+// @Router       /subscriptions/summary/export [get]
+func (h *RestHandler) ExportSubscriptionSummary(w http.ResponseWriter, r *http.Request) {
+	l := apimw.FromContext(r.Context())
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	format, err := exportFormat(r)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	filter, err := parseSummaryFilter(r)
+	if err != nil {
+		l.Warn("Handler ExportSubscriptionSummary: invalid parameters", zap.Error(err))
+		respondServiceError(w, r, err)
+		return
+	}
+	if !filter.Grouped() {
+		filter.GroupByMonth = true
+	}
+
+	summary, err := h.s.GetSubscriptionSummary(ctx, filter)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	var header []string
+	if filter.GroupByMonth {
+		header = append(header, "month")
+	}
+	if filter.GroupByService {
+		header = append(header, "service_name")
+	}
+	if filter.GroupByUser {
+		header = append(header, "user_id")
+	}
+	if filter.GroupByTag {
+		header = append(header, "tag")
+	}
+	header = append(header, "total", "active_count", "trial_months")
+	if summary.Currency != "" {
+		header = append(header, "currency")
+	}
+
+	out := newExportWriter(w, format, "summary", header)
+	for _, row := range summary.Breakdown {
+		var record []string
+		if row.Month != nil {
+			record = append(record, row.Month.Format("2006-01"))
+		}
+		// A month without charges leaves the other group columns empty.
+		if filter.GroupByService {
+			var serviceName string
+			if row.ServiceName != nil {
+				serviceName = *row.ServiceName
+			}
+			record = append(record, serviceName)
+		}
+		if filter.GroupByUser {
+			var userId string
+			if row.UserId != nil {
+				userId = row.UserId.String()
+			}
+			record = append(record, userId)
+		}
+		if filter.GroupByTag {
+			var tag string
+			if row.Tag != nil {
+				tag = *row.Tag
+			}
+			record = append(record, tag)
+		}
+		record = append(record, strconv.FormatInt(row.Total, 10), strconv.Itoa(row.ActiveCount), strconv.Itoa(row.TrialMonths))
+		if summary.Currency != "" {
+			record = append(record, summary.Currency)
+		}
+
+		item := summaryExportRow{SummaryRow: row, Currency: summary.Currency}
+		if err := out.write(record, item); err != nil {
+			l.Error("Handler ExportSubscriptionSummary: export interrupted", zap.Error(err))
+			return
+		}
+	}
+	if err := out.finish(); err != nil {
+		l.Error("Handler ExportSubscriptionSummary: export interrupted", zap.Error(err))
+	}
+}
+
+type summaryExportRow struct {
+	model.SummaryRow
+	Currency string `json:"currency,omitempty"`
+}
@@ -1,9 +1,18 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 	"net/http"
+	"net/url"
+	apimw "subservice/internal/api/middleware"
+	"subservice/internal/domain"
 	"subservice/internal/service"
+
+	"go.uber.org/zap"
 )
 
 type RestHandler struct {
@@ -23,7 +32,83 @@ func respondJSON(w http.ResponseWriter, status int, data interface{}) {
 }
 
 func respondError(w http.ResponseWriter, status int, message string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(map[string]string{"error": message})
+	respondJSON(w, status, ErrorResponse{Error: message})
+}
+
+// subscriptionKeyFromPath reads the {userId} and {serviceName} path parameters.
+// Service names may contain spaces, so the raw path segment is unescaped.
+func subscriptionKeyFromPath(r *http.Request) (uuid.UUID, string, error) {
+	userId, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil || userId == uuid.Nil {
+		return uuid.Nil, "", domain.Validation("user_id", "invalid userId parameter")
+	}
+
+	serviceName, err := url.PathUnescape(chi.URLParam(r, "serviceName"))
+	if err != nil || serviceName == "" {
+		return uuid.Nil, "", domain.Validation("service_name", "invalid serviceName parameter")
+	}
+	return userId, serviceName, nil
+}
+
+// subscriptionIdFromPath identifies the subscription of a request by the {id}
+// path parameter, or by {userId} and {serviceName} on the routes that predate
+// subscription ids. The latter must match exactly one live subscription, or
+// one deleted subscription with deleted set.
+func (h *RestHandler) subscriptionIdFromPath(ctx context.Context, r *http.Request, deleted bool) (uuid.UUID, error) {
+	if raw := chi.URLParam(r, "id"); raw != "" {
+		return parseSubscriptionId(raw)
+	}
+	userId, serviceName, err := subscriptionKeyFromPath(r)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return h.s.ResolveSubscriptionId(ctx, userId, serviceName, deleted)
+}
+
+func parseSubscriptionId(raw string) (uuid.UUID, error) {
+	id, err := uuid.Parse(raw)
+	if err != nil || id == uuid.Nil {
+		return uuid.Nil, domain.Validation("id", "invalid id parameter")
+	}
+	return id, nil
+}
+
+// statusFromError maps domain and request errors to HTTP status codes.
+func statusFromError(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrValidation), errors.Is(err, domain.ErrInvalidPeriod):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrPreconditionFailed):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, domain.ErrUnprocessable):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, domain.ErrBatchAborted):
+		return http.StatusFailedDependency
+	case errors.Is(err, errIfMatchRequired):
+		return http.StatusPreconditionRequired
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// respondServiceError writes err using the status derived from its domain kind.
+// Errors without a domain kind are logged and hidden behind a generic message.
+func respondServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	status := statusFromError(err)
+	if status == http.StatusInternalServerError {
+		apimw.FromContext(r.Context()).Error("internal error", zap.Error(err))
+		respondError(w, status, "internal server error")
+		return
+	}
+
+	resp := ErrorResponse{Error: err.Error()}
+	var vErr *domain.ValidationError
+	if errors.As(err, &vErr) {
+		resp.Field = vErr.Field
+	}
+	respondJSON(w, status, resp)
 }
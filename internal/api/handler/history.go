@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"context"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	apimw "subservice/internal/api/middleware"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"time"
+)
+
+// GetSubscriptionHistory godoc
+// @Summary      История изменений подписок
+// @Description  Возвращает журнал изменений подписок (от новых к старым) со снимками до и после изменения.
+// @Description  События budget_exceeded о превышении бюджета не привязаны к подписке и находятся по user_id
+// @Tags         subscriptions
+// @Produce      json
+// @Param        subscription_id  query  string  false  "ID подписки (UUID)"
+// @Param        user_id       query     string  false  "User ID (UUID)"
+// @Param        service_name  query     string  false  "Название сервиса"
+// @Param        from          query     string  false  "Начало периода (RFC3339)"
+// @Param        to            query     string  false  "Конец периода (RFC3339)"
+// @Param        before_id     query     int     false  "Вернуть события с id меньше указанного"
+// @Param        limit         query     int     false  "Размер страницы (по умолчанию 50, максимум 500)"
+// @Success      200           {array}   model.SubscriptionEvent
+// @Failure      400           {object}  ErrorResponse
+// @Failure      500           {object}  ErrorResponse
+// @Router       /subscriptions/history [get]
+func (h *RestHandler) GetSubscriptionHistory(w http.ResponseWriter, r *http.Request) {
+	l := apimw.FromContext(r.Context())
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	filter, err := parseEventFilter(r)
+	if err != nil {
+		l.Warn("Handler GetSubscriptionHistory: invalid parameters", zap.Error(err))
+		respondServiceError(w, r, err)
+		return
+	}
+
+	events, err := h.s.GetHistory(ctx, filter)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, events)
+}
+
+func parseEventFilter(r *http.Request) (model.EventFilter, error) {
+	q := r.URL.Query()
+	var filter model.EventFilter
+
+	if raw := chi.URLParam(r, "id"); raw != "" {
+		id, err := parseSubscriptionId(raw)
+		if err != nil {
+			return filter, err
+		}
+		filter.SubscriptionId = &id
+	} else if idStr := q.Get("subscription_id"); idStr != "" {
+		id, err := uuid.Parse(idStr)
+		if err != nil || id == uuid.Nil {
+			return filter, domain.Validation("subscription_id", "invalid subscription_id parameter")
+		}
+		filter.SubscriptionId = &id
+	}
+
+	if userIdStr := q.Get("user_id"); userIdStr != "" {
+		userId, err := uuid.Parse(userIdStr)
+		if err != nil || userId == uuid.Nil {
+			return filter, domain.Validation("user_id", "invalid user_id parameter")
+		}
+		filter.UserId = &userId
+	}
+
+	if serviceName := q.Get("service_name"); serviceName != "" {
+		filter.ServiceName = &serviceName
+	}
+
+	if fromStr := q.Get("from"); fromStr != "" {
+		from, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			return filter, domain.Validation("from", "invalid from date format")
+		}
+		filter.From = &from
+	}
+
+	if toStr := q.Get("to"); toStr != "" {
+		to, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			return filter, domain.Validation("to", "invalid to date format")
+		}
+		filter.To = &to
+	}
+
+	var err error
+	if filter.BeforeId, err = parseOptionalInt64(q.Get("before_id")); err != nil {
+		return filter, domain.Validation("before_id", "invalid before_id parameter")
+	}
+
+	if limitStr := q.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			return filter, domain.Validation("limit", "invalid limit parameter")
+		}
+		filter.Limit = limit
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,318 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	apimw "subservice/internal/api/middleware"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"subservice/internal/service"
+	"time"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// csvTagSeparator joins the tags of a subscription in its CSV tags column and
+// the members in its members column.
+const csvTagSeparator = ";"
+
+// csvShareSeparator separates a member's user_id from their share in the CSV
+// members column, as in "user_id:share"; members of an equal split have none.
+const csvShareSeparator = ":"
+
+type ImportRowError struct {
+	Line  int    `json:"line" example:"3"`
+	Error string `json:"error" example:"invalid start_date format"`
+	Field string `json:"field,omitempty" example:"start_date"`
+}
+
+type ImportReport struct {
+	DryRun   bool             `json:"dry_run" example:"false"`
+	Total    int              `json:"total" example:"3"`
+	Accepted int              `json:"accepted" example:"2"`
+	Rejected int              `json:"rejected" example:"1"`
+	Errors   []ImportRowError `json:"errors"`
+}
+
+// importRow is a parsed line of the import body. Either sub or err is set.
+type importRow struct {
+	line int
+	sub  *model.Subscription
+	err  error
+}
+
+var errTooManyRows = fmt.Errorf("import is limited to %d rows", service.MaxImportRows)
+
+// ImportSubscriptions godoc
+// @Summary      Импорт подписок
+// @Description  Загружает подписки из CSV (первая строка — заголовок с именами полей SubscriptionRequest) или NDJSON (один объект SubscriptionRequest на строку).
+// @Description  Каждая строка проверяется как при создании подписки; отклонённые строки возвращаются с номерами. Корректные строки записываются одной транзакцией.
+// @Description  Строки без id получают новый id; строка без id, совпадающая с действующей подпиской или более ранней строкой по user_id, service_name и start_date, отклоняется, поэтому повторный импорт того же файла не создаёт дубликатов. Строка с id существующей подписки отклоняется.
+// @Description  С dry_run=true строки только проверяются, включая эти конфликты, без записи
+// @Tags         subscriptions
+// @Accept       plain
+// @Produce      json
+// @Param        format   query     string  false  "Формат тела; по умолчанию определяется по Content-Type" Enums(csv, ndjson)
+// @Param        dry_run  query     bool    false  "Только проверить строки"
+// @Success      200      {object}  ImportReport
+// @Failure      400      {object}  ErrorResponse   "invalid body / validation error"
+// @Failure      413      {object}  ErrorResponse   "too many rows"
+// @Failure      415      {object}  ErrorResponse   "unsupported format"
+// @Failure      500      {object}  ErrorResponse   "internal server error"
+// @Router       /subscriptions/import [post]
+func (h *RestHandler) ImportSubscriptions(w http.ResponseWriter, r *http.Request) {
+	l := apimw.FromContext(r.Context())
+
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+
+	format, err := importFormat(r)
+	if err != nil {
+		l.Warn("Handler ImportSubscriptions: unsupported format", zap.Error(err))
+		respondError(w, http.StatusUnsupportedMediaType, err.Error())
+		return
+	}
+
+	var dryRun bool
+	if dryRunStr := r.URL.Query().Get("dry_run"); dryRunStr != "" {
+		if dryRun, err = strconv.ParseBool(dryRunStr); err != nil {
+			respondServiceError(w, r, domain.Validation("dry_run", "invalid dry_run parameter"))
+			return
+		}
+	}
+
+	var rows []importRow
+	if format == FormatCSV {
+		rows, err = readCSVRows(r.Body)
+	} else {
+		rows, err = readNDJSONRows(r.Body)
+	}
+	if errors.Is(err, errTooManyRows) {
+		l.Warn("Handler ImportSubscriptions: too many rows")
+		respondError(w, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	}
+	if err != nil {
+		l.Warn("Handler ImportSubscriptions: invalid body", zap.Error(err))
+		respondServiceError(w, r, err)
+		return
+	}
+
+	subs := make([]model.Subscription, 0, len(rows))
+	indexes := make([]int, 0, len(rows))
+	for i, row := range rows {
+		if row.err == nil {
+			subs = append(subs, *row.sub)
+			indexes = append(indexes, i)
+		}
+	}
+
+	importErrs, err := h.s.ImportSubscriptions(ctx, subs, dryRun)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+	for j, err := range importErrs {
+		rows[indexes[j]].err = err
+	}
+
+	report := ImportReport{DryRun: dryRun, Total: len(rows), Errors: []ImportRowError{}}
+	for _, row := range rows {
+		if row.err == nil {
+			report.Accepted++
+			continue
+		}
+		report.Rejected++
+		rowErr := ImportRowError{Line: row.line, Error: row.err.Error()}
+		var vErr *domain.ValidationError
+		if errors.As(row.err, &vErr) {
+			rowErr.Field = vErr.Field
+		}
+		report.Errors = append(report.Errors, rowErr)
+	}
+	l.Info("Import finished", zap.Bool("dry_run", dryRun), zap.Int("accepted", report.Accepted), zap.Int("rejected", report.Rejected))
+	respondJSON(w, http.StatusOK, report)
+}
+
+func importFormat(r *http.Request) (string, error) {
+	if format := r.URL.Query().Get("format"); format != "" {
+		if format != FormatCSV && format != FormatNDJSON {
+			return "", fmt.Errorf("format must be csv or ndjson")
+		}
+		return format, nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "text/csv":
+		return FormatCSV, nil
+	case "application/x-ndjson", "application/jsonl", "application/jsonlines":
+		return FormatNDJSON, nil
+	default:
+		return "", fmt.Errorf("unsupported content type, use text/csv or application/x-ndjson")
+	}
+}
+
+// readCSVRows reads a CSV body whose header names SubscriptionRequest fields.
+// Malformed records are reported as rejected rows.
+func readCSVRows(body io.Reader) ([]importRow, error) {
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, domain.Validation("body", "csv header is missing")
+	}
+	if err != nil {
+		return nil, domain.Validation("body", "invalid csv header: %v", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		switch name {
+		case "id", "service_name", "price", "user_id", "start_date", "end_date", "trial_end_date", "billing_period", "billing_interval", "currency", "tags", "split", "members":
+			columns[name] = i
+		default:
+			return nil, domain.Validation("body", "unknown csv column %q", name)
+		}
+	}
+
+	var rows []importRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if len(rows) == service.MaxImportRows {
+			return nil, errTooManyRows
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			rows = append(rows, importRow{line: parseErr.StartLine, err: domain.Validation("", "invalid csv record: %v", parseErr.Err)})
+			continue
+		}
+		if err != nil {
+			return nil, domain.Validation("body", "invalid csv body: %v", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		sub, err := subscriptionFromCSV(record, columns)
+		rows = append(rows, importRow{line: line, sub: sub, err: err})
+	}
+}
+
+func subscriptionFromCSV(record []string, columns map[string]int) (*model.Subscription, error) {
+	field := func(name string) string {
+		if i, ok := columns[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	req := SubscriptionRequest{
+		Id:            field("id"),
+		ServiceName:   field("service_name"),
+		UserId:        field("user_id"),
+		StartDate:     field("start_date"),
+		EndDate:       field("end_date"),
+		TrialEndDate:  field("trial_end_date"),
+		BillingPeriod: field("billing_period"),
+		Currency:      field("currency"),
+		Split:         field("split"),
+	}
+	if tags := field("tags"); tags != "" {
+		req.Tags = strings.Split(tags, csvTagSeparator)
+	}
+
+	var err error
+	if req.Members, err = parseCSVMembers(field("members")); err != nil {
+		return nil, err
+	}
+	if req.Price, err = strconv.ParseInt(field("price"), 10, 64); err != nil {
+		return nil, domain.Validation("price", "invalid price")
+	}
+	if interval := field("billing_interval"); interval != "" {
+		if req.BillingInterval, err = strconv.Atoi(interval); err != nil {
+			return nil, domain.Validation("billing_interval", "invalid billing_interval")
+		}
+	}
+	return ValidateSubscriptionRequest(&req)
+}
+
+// parseCSVMembers reads the members column written by formatCSVMembers.
+func parseCSVMembers(raw string) ([]MemberRequest, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	var members []MemberRequest
+	for _, item := range strings.Split(raw, csvTagSeparator) {
+		userId, share, hasShare := strings.Cut(strings.TrimSpace(item), csvShareSeparator)
+		m := MemberRequest{UserId: userId}
+		if hasShare {
+			var err error
+			if m.Share, err = strconv.ParseInt(share, 10, 64); err != nil {
+				return nil, domain.Validation("members", "invalid member share %q", share)
+			}
+		}
+		members = append(members, m)
+	}
+	return members, nil
+}
+
+func formatCSVMembers(members []MemberRequest) string {
+	items := make([]string, len(members))
+	for i, m := range members {
+		items[i] = m.UserId
+		if m.Share != 0 {
+			items[i] += csvShareSeparator + strconv.FormatInt(m.Share, 10)
+		}
+	}
+	return strings.Join(items, csvTagSeparator)
+}
+
+// readNDJSONRows reads one SubscriptionRequest object per line; blank lines
+// are skipped.
+func readNDJSONRows(body io.Reader) ([]importRow, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var rows []importRow
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		if len(rows) == service.MaxImportRows {
+			return nil, errTooManyRows
+		}
+
+		var req SubscriptionRequest
+		dec := json.NewDecoder(bytes.NewReader(text))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&req); err != nil {
+			rows = append(rows, importRow{line: line, err: domain.Validation("", "invalid json: %v", err)})
+			continue
+		}
+		sub, err := ValidateSubscriptionRequest(&req)
+		rows = append(rows, importRow{line: line, sub: sub, err: err})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, domain.Validation("body", "invalid ndjson body: %v", err)
+	}
+	return rows, nil
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	apimw "subservice/internal/api/middleware"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"time"
+)
+
+// AdminListSubscriptions godoc
+// @Summary      Список подписок всех пользователей
+// @Description  Возвращает подписки всех пользователей постранично, с сортировкой и фильтрами
+// @Tags         admin
+// @Produce      json
+// @Param        user_id              query     string  false  "User ID (UUID)"
+// @Param        service_name_prefix  query     string  false  "Префикс названия сервиса"
+// @Param        tag                  query     string  false  "Только подписки с тегом"
+// @Param        active_at            query     string  false  "Активна на дату (RFC3339)"
+// @Param        min_price            query     int     false  "Минимальная цена"
+// @Param        max_price            query     int     false  "Максимальная цена"
+// @Param        sort                 query     string  false  "Поле сортировки" Enums(service_name, price, start_date)
+// @Param        order                query     string  false  "Направление сортировки" Enums(asc, desc)
+// @Param        limit                query     int     false  "Размер страницы (по умолчанию 50, максимум 500)"
+// @Param        cursor               query     string  false  "Курсор следующей страницы (next_cursor)"
+// @Param        include_deleted      query     bool    false  "Включить удалённые подписки"
+// @Success      200                  {object}  model.SubscriptionPage
+// @Failure      400                  {object}  ErrorResponse
+// @Failure      500                  {object}  ErrorResponse
+// @Router       /admin/subscriptions [get]
+func (h *RestHandler) AdminListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	l := apimw.FromContext(r.Context())
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	filter, err := parseListFilter(r)
+	if err != nil {
+		l.Warn("Handler AdminListSubscriptions: invalid parameters", zap.Error(err))
+		respondServiceError(w, r, err)
+		return
+	}
+
+	if userIdStr := r.URL.Query().Get("user_id"); userIdStr != "" {
+		userId, err := uuid.Parse(userIdStr)
+		if err != nil || userId == uuid.Nil {
+			l.Warn("Handler AdminListSubscriptions: invalid user_id parameter")
+			respondError(w, http.StatusBadRequest, "invalid user_id parameter")
+			return
+		}
+		filter.UserId = &userId
+	}
+
+	if includeDeletedStr := r.URL.Query().Get("include_deleted"); includeDeletedStr != "" {
+		includeDeleted, err := strconv.ParseBool(includeDeletedStr)
+		if err != nil {
+			l.Warn("Handler AdminListSubscriptions: invalid include_deleted parameter")
+			respondServiceError(w, r, domain.Validation("include_deleted", "invalid include_deleted parameter"))
+			return
+		}
+		filter.IncludeDeleted = includeDeleted
+	}
+
+	page, err := h.s.ListSubscriptions(ctx, filter)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, page)
+}
+
+// parseListFilter reads the paging, sorting and filter query parameters shared
+// by the listing endpoints.
+func parseListFilter(r *http.Request) (model.ListFilter, error) {
+	q := r.URL.Query()
+	var filter model.ListFilter
+
+	if prefix := q.Get("service_name_prefix"); prefix != "" {
+		filter.ServiceNamePrefix = &prefix
+	}
+
+	tag, err := parseTagParam(q.Get("tag"))
+	if err != nil {
+		return filter, err
+	}
+	filter.Tag = tag
+
+	if activeAtStr := q.Get("active_at"); activeAtStr != "" {
+		activeAt, err := time.Parse(time.RFC3339, activeAtStr)
+		if err != nil {
+			return filter, domain.Validation("active_at", "invalid active_at date format")
+		}
+		filter.ActiveAt = &activeAt
+	}
+
+	if filter.MinPrice, err = parseOptionalInt64(q.Get("min_price")); err != nil {
+		return filter, domain.Validation("min_price", "invalid min_price parameter")
+	}
+	if filter.MaxPrice, err = parseOptionalInt64(q.Get("max_price")); err != nil {
+		return filter, domain.Validation("max_price", "invalid max_price parameter")
+	}
+
+	switch sortBy := q.Get("sort"); sortBy {
+	case "", model.SortByServiceName, model.SortByPrice, model.SortByStartDate:
+		filter.SortBy = sortBy
+	default:
+		return filter, domain.Validation("sort", "sort must be one of service_name, price, start_date")
+	}
+
+	switch order := q.Get("order"); order {
+	case "", "asc":
+	case "desc":
+		filter.Desc = true
+	default:
+		return filter, domain.Validation("order", "order must be asc or desc")
+	}
+
+	if limitStr := q.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			return filter, domain.Validation("limit", "invalid limit parameter")
+		}
+		filter.Limit = limit
+	}
+
+	if cursorStr := q.Get("cursor"); cursorStr != "" {
+		cursor, err := model.DecodeCursor(cursorStr)
+		if err != nil {
+			return filter, domain.Validation("cursor", "invalid cursor parameter")
+		}
+		filter.After = cursor
+	}
+
+	return filter, nil
+}
+
+func parseOptionalInt64(s string) (*int64, error) {
+	if s == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"go.uber.org/zap"
+	"mime"
+	"net/http"
+	"strings"
+	apimw "subservice/internal/api/middleware"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"time"
+)
+
+// PatchSubscription godoc
+// @Summary      Частично обновить подписку
+// @Description  Применяет JSON Merge Patch (RFC 7396) к подписке. Отсутствующие поля не меняются, null в end_date снимает дату окончания,
+// @Description  null в billing_period, billing_interval и currency возвращает значение по умолчанию, null в tags снимает все теги, null в members отменяет совместное использование; массивы tags и members заменяются целиком. Результат проверяется теми же правилами, что и при создании.
+// @Description  Новая цена (price) действует с текущего месяца и попадает в историю цен, прошлые месяцы считаются по прежней.
+// @Description  Заголовок If-Match должен содержать ETag текущей версии или "*"
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        userId       path      string               true  "User ID (UUID)"
+// @Param        serviceName  path      string               true  "Название сервиса"
+// @Param        If-Match     header    string               true  "ETag версии подписки"
+// @Param        body         body      SubscriptionRequest  true  "Изменяемые поля"
+// @Success      200          {object}  model.Subscription
+// @Header       200          {string}  ETag  "Новая версия подписки"
+// @Failure      400          {object}  ErrorResponse   "invalid json / validation error"
+// @Failure      404          {object}  ErrorResponse   "subscription not found"
+// @Failure      409          {object}  ErrorResponse   "several subscriptions match userId and serviceName"
+// @Failure      412          {object}  ErrorResponse   "subscription version mismatch"
+// @Failure      415          {object}  ErrorResponse   "unsupported content type"
+// @Failure      428          {object}  ErrorResponse   "If-Match header is required"
+// @Failure      500          {object}  ErrorResponse   "internal server error"
+// @Router       /subscriptions/{userId}/{serviceName} [patch]
+func (h *RestHandler) PatchSubscription(w http.ResponseWriter, r *http.Request) {
+	l := apimw.FromContext(r.Context())
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	id, err := h.subscriptionIdFromPath(ctx, r, false)
+	if err != nil {
+		l.Warn("Handler PatchSubscription: cannot identify subscription", zap.Error(err))
+		respondServiceError(w, r, err)
+		return
+	}
+
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || (mediaType != "application/merge-patch+json" && mediaType != "application/json") {
+			l.Warn("Handler PatchSubscription: unsupported content type", zap.String("content_type", contentType))
+			respondError(w, http.StatusUnsupportedMediaType, "unsupported content type")
+			return
+		}
+	}
+
+	version, err := versionFromIfMatch(r)
+	if err != nil {
+		l.Warn("Handler PatchSubscription: invalid If-Match header", zap.Error(err))
+		respondServiceError(w, r, err)
+		return
+	}
+
+	var patch map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil || patch == nil {
+		l.Warn("Handler PatchSubscription: invalid json")
+		respondError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	current, err := h.s.GetSubscription(ctx, id)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+	// The patch is applied to the state read above, so even with "If-Match: *"
+	// a concurrent change between the read and the write is detected.
+	if version == 0 {
+		version = current.Version
+	}
+
+	req := requestFromSubscription(current)
+	if err := applyMergePatch(&req, patch); err != nil {
+		l.Warn("Handler PatchSubscription: invalid patch", zap.Error(err))
+		respondServiceError(w, r, err)
+		return
+	}
+
+	sub, err := ValidateSubscriptionRequest(&req)
+	if err != nil {
+		l.Warn("Handler PatchSubscription: validation error", zap.Error(err))
+		respondServiceError(w, r, err)
+		return
+	}
+	sub.Version = version
+
+	if err := h.s.UpdateSubscription(ctx, *sub); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	updated, err := h.s.GetSubscription(ctx, id)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("ETag", etag(updated.Version))
+	respondJSON(w, http.StatusOK, updated)
+}
+
+// requestFromSubscription is the inverse of ValidateSubscriptionRequest.
+func requestFromSubscription(sub *model.Subscription) SubscriptionRequest {
+	req := SubscriptionRequest{
+		Id:              sub.Id.String(),
+		ServiceName:     sub.ServiceName,
+		Price:           sub.Price,
+		UserId:          sub.UserId.String(),
+		StartDate:       sub.StartDate.Format(time.RFC3339),
+		BillingPeriod:   sub.BillingPeriod,
+		BillingInterval: sub.BillingInterval,
+		Currency:        sub.Currency,
+		Tags:            sub.Tags,
+		Split:           sub.Split,
+	}
+	for _, m := range sub.Members {
+		req.Members = append(req.Members, MemberRequest{UserId: m.UserId.String(), Share: m.Share})
+	}
+	if sub.EndDate != nil {
+		req.EndDate = sub.EndDate.Format(time.RFC3339)
+	}
+	if sub.TrialEndDate != nil {
+		req.TrialEndDate = sub.TrialEndDate.Format(time.RFC3339)
+	}
+	return req
+}
+
+// applyMergePatch merges patch into req following RFC 7396. A null removes the
+// member, which clears end_date, trial_end_date and tags, stops sharing the
+// subscription on members and resets defaulted fields; the identifying and
+// required members cannot be removed or changed.
+func applyMergePatch(req *SubscriptionRequest, patch map[string]json.RawMessage) error {
+	values := make(map[string]json.RawMessage, len(patch))
+	for field, value := range patch {
+		if !bytes.Equal(bytes.TrimSpace(value), []byte("null")) {
+			values[field] = value
+			continue
+		}
+		switch field {
+		case "end_date":
+			req.EndDate = ""
+		case "trial_end_date":
+			req.TrialEndDate = ""
+		case "billing_period":
+			req.BillingPeriod = ""
+		case "billing_interval":
+			req.BillingInterval = 0
+		case "currency":
+			req.Currency = ""
+		case "tags":
+			req.Tags = nil
+		case "split":
+			req.Split = ""
+		case "members":
+			req.Members = nil
+			req.Split = ""
+		default:
+			return domain.Validation(field, "%s cannot be null", field)
+		}
+	}
+
+	id, userId, serviceName := req.Id, req.UserId, req.ServiceName
+
+	raw, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(req); err != nil {
+		return domain.Validation("", "invalid patch: %v", err)
+	}
+
+	if !strings.EqualFold(req.Id, id) {
+		return domain.Validation("id", "id cannot be changed")
+	}
+	if !strings.EqualFold(req.UserId, userId) {
+		return domain.Validation("user_id", "user_id cannot be changed")
+	}
+	if model.ServiceKey(req.ServiceName) != model.ServiceKey(serviceName) {
+		return domain.Validation("service_name", "service_name cannot be changed")
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"subservice/internal/domain"
+	"testing"
+)
+
+func TestApplyMergePatch(t *testing.T) {
+	base := func() SubscriptionRequest {
+		return SubscriptionRequest{
+			Id:              "00000000-0000-0000-0000-000000000001",
+			ServiceName:     "Yandex Plus",
+			Price:           100,
+			UserId:          "00000000-0000-0000-0000-00000000000a",
+			StartDate:       "2024-01-01T00:00:00Z",
+			EndDate:         "2024-12-01T00:00:00Z",
+			TrialEndDate:    "2024-01-01T00:00:00Z",
+			BillingPeriod:   "quarter",
+			BillingInterval: 2,
+			Currency:        "USD",
+			Tags:            []string{"family", "video"},
+			Split:           "fixed",
+			Members:         []MemberRequest{{UserId: "00000000-0000-0000-0000-00000000000b", Share: 30}},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		patch     string
+		want      func(req *SubscriptionRequest)
+		wantField string
+	}{
+		{
+			name:  "empty patch",
+			patch: `{}`,
+			want:  func(req *SubscriptionRequest) {},
+		},
+		{
+			name:  "members are replaced",
+			patch: `{"price":150,"tags":["music"],"members":[{"user_id":"00000000-0000-0000-0000-00000000000c","share":40}]}`,
+			want: func(req *SubscriptionRequest) {
+				req.Price = 150
+				req.Tags = []string{"music"}
+				req.Members = []MemberRequest{{UserId: "00000000-0000-0000-0000-00000000000c", Share: 40}}
+			},
+		},
+		{
+			name:  "null clears optional members",
+			patch: `{"end_date":null,"trial_end_date":null,"tags":null}`,
+			want: func(req *SubscriptionRequest) {
+				req.EndDate = ""
+				req.TrialEndDate = ""
+				req.Tags = nil
+			},
+		},
+		{
+			name:  "null resets defaulted members",
+			patch: `{"billing_period":null,"billing_interval":null,"currency":null}`,
+			want: func(req *SubscriptionRequest) {
+				req.BillingPeriod = ""
+				req.BillingInterval = 0
+				req.Currency = ""
+			},
+		},
+		{
+			name:  "null members stop sharing",
+			patch: `{"members":null}`,
+			want: func(req *SubscriptionRequest) {
+				req.Members = nil
+				req.Split = ""
+			},
+		},
+		{
+			name:  "service name respelled",
+			patch: `{"service_name":"  yandex   PLUS "}`,
+			want:  func(req *SubscriptionRequest) { req.ServiceName = "  yandex   PLUS " },
+		},
+		{
+			name:  "same user id in other case",
+			patch: `{"user_id":"00000000-0000-0000-0000-00000000000A"}`,
+			want:  func(req *SubscriptionRequest) { req.UserId = "00000000-0000-0000-0000-00000000000A" },
+		},
+		{name: "null price", patch: `{"price":null}`, wantField: "price"},
+		{name: "null start date", patch: `{"start_date":null}`, wantField: "start_date"},
+		{name: "other service", patch: `{"service_name":"Kinopoisk"}`, wantField: "service_name"},
+		{name: "other user", patch: `{"user_id":"00000000-0000-0000-0000-00000000000b"}`, wantField: "user_id"},
+		{name: "other id", patch: `{"id":"00000000-0000-0000-0000-000000000002"}`, wantField: "id"},
+		{name: "unknown member", patch: `{"colour":"red"}`, wantField: ""},
+		{name: "wrong type", patch: `{"price":"free"}`, wantField: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patch map[string]json.RawMessage
+			if err := json.Unmarshal([]byte(tt.patch), &patch); err != nil {
+				t.Fatalf("invalid patch: %v", err)
+			}
+			req := base()
+
+			err := applyMergePatch(&req, patch)
+			if tt.want == nil {
+				var vErr *domain.ValidationError
+				if !errors.As(err, &vErr) || vErr.Field != tt.wantField {
+					t.Fatalf("applyMergePatch() error = %v, want validation error on %q", err, tt.wantField)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyMergePatch() error = %v", err)
+			}
+			want := base()
+			tt.want(&want)
+			if !reflect.DeepEqual(req, want) {
+				t.Errorf("applyMergePatch() = %+v, want %+v", req, want)
+			}
+		})
+	}
+}
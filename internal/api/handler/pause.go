@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	apimw "subservice/internal/api/middleware"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"time"
+)
+
+// PauseRequest is optional; by default the pause starts in the current month
+// and lasts until the subscription is resumed.
+type PauseRequest struct {
+	From       string `json:"from,omitempty" example:"2024-06-01T00:00:00Z"`
+	ResumeFrom string `json:"resume_from,omitempty" example:"2024-09-01T00:00:00Z"`
+}
+
+// ResumeRequest is optional; by default the current month is charged again.
+type ResumeRequest struct {
+	ResumeFrom string `json:"resume_from,omitempty" example:"2024-09-01T00:00:00Z"`
+}
+
+// decodeOptionalJSON decodes the body into v unless it is empty.
+func decodeOptionalJSON(r *http.Request, v interface{}) error {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
+
+// parseMonthOrNow parses an RFC3339 date, defaulting to the current time.
+func parseMonthOrNow(field, value string) (time.Time, error) {
+	if value == "" {
+		return time.Now().UTC(), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, domain.Validation(field, "invalid %s format", field)
+	}
+	return t, nil
+}
+
+// PauseSubscription godoc
+// @Summary      Приостановить подписку
+// @Description  Приостанавливает подписку с месяца from до месяца resume_from (не включая его) или до возобновления. Приостановленные месяцы не учитываются в сумме.
+// @Description  Без тела запроса пауза начинается с текущего месяца и длится до возобновления. Версия подписки (ETag) увеличивается
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        userId       path      string        true   "User ID (UUID)"
+// @Param        serviceName  path      string        true   "Название сервиса"
+// @Param        body         body      PauseRequest  false  "Период паузы"
+// @Success      201          {object}  SuccessResponse "status: success"
+// @Failure      400          {object}  ErrorResponse   "validation error / pause outside of subscription period"
+// @Failure      404          {object}  ErrorResponse   "subscription not found"
+// @Failure      409          {object}  ErrorResponse   "pause overlaps an existing pause / several subscriptions match userId and serviceName"
+// @Failure      500          {object}  ErrorResponse   "internal server error"
+// @Router       /subscriptions/{userId}/{serviceName}/pause [post]
+func (h *RestHandler) PauseSubscription(w http.ResponseWriter, r *http.Request) {
+	l := apimw.FromContext(r.Context())
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	id, err := h.subscriptionIdFromPath(ctx, r, false)
+	if err != nil {
+		l.Warn("Handler PauseSubscription: cannot identify subscription", zap.Error(err))
+		respondServiceError(w, r, err)
+		return
+	}
+
+	var req PauseRequest
+	if err := decodeOptionalJSON(r, &req); err != nil {
+		l.Warn("Handler PauseSubscription: invalid json")
+		respondError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	var pause model.Pause
+	if pause.From, err = parseMonthOrNow("from", req.From); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+	if req.ResumeFrom != "" {
+		resumeFrom, err := time.Parse(time.RFC3339, req.ResumeFrom)
+		if err != nil {
+			respondServiceError(w, r, domain.Validation("resume_from", "invalid resume_from format"))
+			return
+		}
+		pause.ResumeFrom = &resumeFrom
+	}
+
+	if err := h.s.PauseSubscription(ctx, id, pause); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, map[string]string{"status": "success"})
+}
+
+// ResumeSubscription godoc
+// @Summary      Возобновить подписку
+// @Description  Завершает действующую паузу с месяца resume_from (по умолчанию текущий). Пауза, которая к этому месяцу ещё не началась, отменяется. Версия подписки (ETag) увеличивается
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        userId       path      string         true   "User ID (UUID)"
+// @Param        serviceName  path      string         true   "Название сервиса"
+// @Param        body         body      ResumeRequest  false  "Месяц возобновления"
+// @Success      200          {object}  SuccessResponse "status: success"
+// @Failure      400          {object}  ErrorResponse
+// @Failure      404          {object}  ErrorResponse   "subscription not found"
+// @Failure      409          {object}  ErrorResponse   "subscription is not paused / several subscriptions match userId and serviceName"
+// @Failure      500          {object}  ErrorResponse
+// @Router       /subscriptions/{userId}/{serviceName}/resume [post]
+func (h *RestHandler) ResumeSubscription(w http.ResponseWriter, r *http.Request) {
+	l := apimw.FromContext(r.Context())
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	id, err := h.subscriptionIdFromPath(ctx, r, false)
+	if err != nil {
+		l.Warn("Handler ResumeSubscription: cannot identify subscription", zap.Error(err))
+		respondServiceError(w, r, err)
+		return
+	}
+
+	var req ResumeRequest
+	if err := decodeOptionalJSON(r, &req); err != nil {
+		l.Warn("Handler ResumeSubscription: invalid json")
+		respondError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	resumeFrom, err := parseMonthOrNow("resume_from", req.ResumeFrom)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	if err := h.s.ResumeSubscription(ctx, id, resumeFrom); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]string{"status": "success"})
+}
+
+// GetPauses godoc
+// @Summary      Паузы подписки
+// @Description  Возвращает прошедшие, текущие и запланированные паузы подписки
+// @Tags         subscriptions
+// @Produce      json
+// @Param        userId       path      string  true  "User ID (UUID)"
+// @Param        serviceName  path      string  true  "Название сервиса"
+// @Success      200          {array}   model.Pause
+// @Failure      400          {object}  ErrorResponse
+// @Failure      404          {object}  ErrorResponse   "subscription not found"
+// @Failure      409          {object}  ErrorResponse   "several subscriptions match userId and serviceName"
+// @Failure      500          {object}  ErrorResponse
+// @Router       /subscriptions/{userId}/{serviceName}/pauses [get]
+func (h *RestHandler) GetPauses(w http.ResponseWriter, r *http.Request) {
+	l := apimw.FromContext(r.Context())
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	id, err := h.subscriptionIdFromPath(ctx, r, false)
+	if err != nil {
+		l.Warn("Handler GetPauses: cannot identify subscription", zap.Error(err))
+		respondServiceError(w, r, err)
+		return
+	}
+
+	pauses, err := h.s.GetPauses(ctx, id)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, pauses)
+}
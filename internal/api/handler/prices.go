@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"go.uber.org/zap"
+	"net/http"
+	apimw "subservice/internal/api/middleware"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"time"
+)
+
+type PriceChangeRequest struct {
+	Price         int64  `json:"price" example:"399"`
+	EffectiveFrom string `json:"effective_from" example:"2024-03-01T00:00:00Z"`
+}
+
+// SchedulePriceChange godoc
+// @Summary      Запланировать изменение цены
+// @Description  Устанавливает новую цену подписки начиная с указанного месяца. Прошлые месяцы считаются по прежней цене
+// @Tags         prices
+// @Accept       json
+// @Produce      json
+// @Param        userId       path      string              true  "User ID (UUID)"
+// @Param        serviceName  path      string              true  "Название сервиса"
+// @Param        body         body      PriceChangeRequest  true  "Новая цена"
+// @Success      201          {object}  SuccessResponse "status: success"
+// @Failure      400          {object}  ErrorResponse   "validation error / effective_from outside of subscription period"
+// @Failure      404          {object}  ErrorResponse   "subscription not found"
+// @Failure      409          {object}  ErrorResponse   "several subscriptions match userId and serviceName"
+// @Failure      500          {object}  ErrorResponse   "internal server error"
+// @Router       /subscriptions/{userId}/{serviceName}/prices [post]
+func (h *RestHandler) SchedulePriceChange(w http.ResponseWriter, r *http.Request) {
+	l := apimw.FromContext(r.Context())
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	id, err := h.subscriptionIdFromPath(ctx, r, false)
+	if err != nil {
+		l.Warn("Handler SchedulePriceChange: cannot identify subscription", zap.Error(err))
+		respondServiceError(w, r, err)
+		return
+	}
+
+	var req PriceChangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		l.Warn("Handler SchedulePriceChange: invalid json")
+		respondError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	effectiveFrom, err := time.Parse(time.RFC3339, req.EffectiveFrom)
+	if err != nil {
+		l.Warn("Handler SchedulePriceChange: invalid effective_from format")
+		respondServiceError(w, r, domain.Validation("effective_from", "invalid effective_from format"))
+		return
+	}
+
+	change := model.PriceChange{EffectiveFrom: effectiveFrom, Price: req.Price}
+	if err := h.s.SchedulePriceChange(ctx, id, change); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, map[string]string{"status": "success"})
+}
+
+// GetPriceHistory godoc
+// @Summary      История цен подписки
+// @Description  Возвращает запланированные и прошедшие изменения цены подписки
+// @Tags         prices
+// @Produce      json
+// @Param        userId       path      string  true  "User ID (UUID)"
+// @Param        serviceName  path      string  true  "Название сервиса"
+// @Success      200          {array}   model.PriceChange
+// @Failure      400          {object}  ErrorResponse
+// @Failure      404          {object}  ErrorResponse   "subscription not found"
+// @Failure      409          {object}  ErrorResponse   "several subscriptions match userId and serviceName"
+// @Failure      500          {object}  ErrorResponse
+// @Router       /subscriptions/{userId}/{serviceName}/prices [get]
+func (h *RestHandler) GetPriceHistory(w http.ResponseWriter, r *http.Request) {
+	l := apimw.FromContext(r.Context())
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	id, err := h.subscriptionIdFromPath(ctx, r, false)
+	if err != nil {
+		l.Warn("Handler GetPriceHistory: cannot identify subscription", zap.Error(err))
+		respondServiceError(w, r, err)
+		return
+	}
+
+	changes, err := h.s.GetPriceHistory(ctx, id)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, changes)
+}
@@ -0,0 +1,285 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+	"net/http"
+	apimw "subservice/internal/api/middleware"
+	"subservice/internal/model"
+	"time"
+)
+
+type PromotionRequest struct {
+	Code           string   `json:"code" example:"SUMMER25"`
+	Kind           string   `json:"kind" example:"percent" enums:"percent,fixed"`
+	Value          int64    `json:"value" example:"25"`
+	DurationMonths int      `json:"duration_months" example:"3"`
+	ServiceNames   []string `json:"service_names,omitempty" example:"Yandex Plus"`
+}
+
+// ApplyPromotionRequest attaches a promotion; applied_from defaults to the
+// current month.
+type ApplyPromotionRequest struct {
+	Code        string `json:"code" example:"SUMMER25"`
+	AppliedFrom string `json:"applied_from,omitempty" example:"2024-06-01T00:00:00Z"`
+}
+
+// CreatePromotion godoc
+// @Summary      Создать промокод
+// @Description  Создает скидку: percent — процент от цены, fixed — фиксированная сумма в валюте подписки с каждого списания за период оплаты.
+// @Description  Скидка действует duration_months месяцев с момента применения к подписке. Пустой service_names — для любых сервисов. Названия и синонимы из каталога сохраняются под каноническим названием, регистр и пробелы не учитываются.
+// @Tags         promotions
+// @Accept       json
+// @Produce      json
+// @Param        body  body      PromotionRequest  true  "Промокод"
+// @Success      201   {object}  model.Promotion
+// @Failure      400   {object}  ErrorResponse   "invalid json / validation error"
+// @Failure      409   {object}  ErrorResponse   "promotion already exists"
+// @Failure      500   {object}  ErrorResponse   "internal server error"
+// @Router       /promotions [post]
+func (h *RestHandler) CreatePromotion(w http.ResponseWriter, r *http.Request) {
+	l := apimw.FromContext(r.Context())
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	var req PromotionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		l.Warn("Handler CreatePromotion: invalid json")
+		respondError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	promo, err := h.s.CreatePromotion(ctx, model.Promotion{
+		Code:           req.Code,
+		Kind:           req.Kind,
+		Value:          req.Value,
+		DurationMonths: req.DurationMonths,
+		ServiceNames:   req.ServiceNames,
+	})
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, promo)
+}
+
+// ListPromotions godoc
+// @Summary      Список промокодов
+// @Tags         promotions
+// @Produce      json
+// @Success      200  {array}   model.Promotion
+// @Failure      500  {object}  ErrorResponse
+// @Router       /promotions [get]
+func (h *RestHandler) ListPromotions(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	promos, err := h.s.ListPromotions(ctx)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, promos)
+}
+
+// GetPromotion godoc
+// @Summary      Получить промокод
+// @Tags         promotions
+// @Produce      json
+// @Param        code  path      string  true  "Промокод"
+// @Success      200   {object}  model.Promotion
+// @Failure      404   {object}  ErrorResponse   "promotion not found"
+// @Failure      500   {object}  ErrorResponse
+// @Router       /promotions/{code} [get]
+func (h *RestHandler) GetPromotion(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	promo, err := h.s.GetPromotion(ctx, chi.URLParam(r, "code"))
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, promo)
+}
+
+// DeletePromotion godoc
+// @Summary      Удалить промокод
+// @Description  Удаляет промокод, если он не применен ни к одной подписке
+// @Tags         promotions
+// @Produce      json
+// @Param        code  path      string  true  "Промокод"
+// @Success      200   {object}  SuccessResponse "status: success"
+// @Failure      404   {object}  ErrorResponse   "promotion not found"
+// @Failure      409   {object}  ErrorResponse   "promotion is applied to subscriptions"
+// @Failure      500   {object}  ErrorResponse
+// @Router       /promotions/{code} [delete]
+func (h *RestHandler) DeletePromotion(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if err := h.s.DeletePromotion(ctx, chi.URLParam(r, "code")); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]string{"status": "success"})
+}
+
+// ApplyPromotion godoc
+// @Summary      Применить промокод к подписке
+// @Description  Применяет скидку к подписке с месяца applied_from (по умолчанию текущий) на duration_months месяцев. Промокоды подписки не должны пересекаться по месяцам; завершённые сохраняются. Суммы в сводке учитывают скидку
+// @Tags         promotions
+// @Accept       json
+// @Produce      json
+// @Param        userId       path      string                 true  "User ID (UUID)"
+// @Param        serviceName  path      string                 true  "Название сервиса"
+// @Param        body         body      ApplyPromotionRequest  true  "Промокод"
+// @Success      201          {object}  model.AppliedPromotion
+// @Failure      400          {object}  ErrorResponse   "validation error / promotion does not apply to the service"
+// @Failure      404          {object}  ErrorResponse   "subscription or promotion not found"
+// @Failure      409          {object}  ErrorResponse   "promotion overlaps an applied promotion / several subscriptions match userId and serviceName"
+// @Failure      500          {object}  ErrorResponse
+// @Router       /subscriptions/{userId}/{serviceName}/promotion [post]
+func (h *RestHandler) ApplyPromotion(w http.ResponseWriter, r *http.Request) {
+	l := apimw.FromContext(r.Context())
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	id, err := h.subscriptionIdFromPath(ctx, r, false)
+	if err != nil {
+		l.Warn("Handler ApplyPromotion: cannot identify subscription", zap.Error(err))
+		respondServiceError(w, r, err)
+		return
+	}
+
+	var req ApplyPromotionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		l.Warn("Handler ApplyPromotion: invalid json")
+		respondError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	appliedFrom, err := parseMonthOrNow("applied_from", req.AppliedFrom)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	applied, err := h.s.ApplyPromotion(ctx, id, req.Code, appliedFrom)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, applied)
+}
+
+// GetAppliedPromotion godoc
+// @Summary      Промокод подписки
+// @Description  Возвращает промокод, действующий в текущем месяце, или ближайший запланированный
+// @Tags         promotions
+// @Produce      json
+// @Param        userId       path      string  true  "User ID (UUID)"
+// @Param        serviceName  path      string  true  "Название сервиса"
+// @Success      200          {object}  model.AppliedPromotion
+// @Failure      400          {object}  ErrorResponse
+// @Failure      404          {object}  ErrorResponse   "subscription has no promotion"
+// @Failure      409          {object}  ErrorResponse   "several subscriptions match userId and serviceName"
+// @Failure      500          {object}  ErrorResponse
+// @Router       /subscriptions/{userId}/{serviceName}/promotion [get]
+func (h *RestHandler) GetAppliedPromotion(w http.ResponseWriter, r *http.Request) {
+	l := apimw.FromContext(r.Context())
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	id, err := h.subscriptionIdFromPath(ctx, r, false)
+	if err != nil {
+		l.Warn("Handler GetAppliedPromotion: cannot identify subscription", zap.Error(err))
+		respondServiceError(w, r, err)
+		return
+	}
+
+	applied, err := h.s.GetAppliedPromotion(ctx, id)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, applied)
+}
+
+// ListAppliedPromotions godoc
+// @Summary      Промокоды подписки
+// @Description  Возвращает прошедшие, текущие и запланированные промокоды подписки
+// @Tags         promotions
+// @Produce      json
+// @Param        userId       path      string  true  "User ID (UUID)"
+// @Param        serviceName  path      string  true  "Название сервиса"
+// @Success      200          {array}   model.AppliedPromotion
+// @Failure      400          {object}  ErrorResponse
+// @Failure      404          {object}  ErrorResponse   "subscription not found"
+// @Failure      409          {object}  ErrorResponse   "several subscriptions match userId and serviceName"
+// @Failure      500          {object}  ErrorResponse
+// @Router       /subscriptions/{userId}/{serviceName}/promotions [get]
+func (h *RestHandler) ListAppliedPromotions(w http.ResponseWriter, r *http.Request) {
+	l := apimw.FromContext(r.Context())
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	id, err := h.subscriptionIdFromPath(ctx, r, false)
+	if err != nil {
+		l.Warn("Handler ListAppliedPromotions: cannot identify subscription", zap.Error(err))
+		respondServiceError(w, r, err)
+		return
+	}
+
+	applied, err := h.s.ListAppliedPromotions(ctx, id)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, applied)
+}
+
+// RemovePromotion godoc
+// @Summary      Отменить промокод подписки
+// @Description  Завершает действующий промокод с месяца from (по умолчанию текущий); скидка прошлых месяцев сохраняется. Промокод, который к этому месяцу ещё не начал действовать, отменяется
+// @Tags         promotions
+// @Produce      json
+// @Param        userId       path      string  true   "User ID (UUID)"
+// @Param        serviceName  path      string  true   "Название сервиса"
+// @Param        from         query     string  false  "Месяц окончания скидки (RFC3339)"
+// @Success      200          {object}  SuccessResponse "status: success"
+// @Failure      400          {object}  ErrorResponse
+// @Failure      404          {object}  ErrorResponse   "subscription has no promotion"
+// @Failure      409          {object}  ErrorResponse   "several subscriptions match userId and serviceName"
+// @Failure      500          {object}  ErrorResponse
+// @Router       /subscriptions/{userId}/{serviceName}/promotion [delete]
+func (h *RestHandler) RemovePromotion(w http.ResponseWriter, r *http.Request) {
+	l := apimw.FromContext(r.Context())
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	id, err := h.subscriptionIdFromPath(ctx, r, false)
+	if err != nil {
+		l.Warn("Handler RemovePromotion: cannot identify subscription", zap.Error(err))
+		respondServiceError(w, r, err)
+		return
+	}
+
+	from, err := parseMonthOrNow("from", r.URL.Query().Get("from"))
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	if err := h.s.RemovePromotion(ctx, id, from); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]string{"status": "success"})
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	apimw "subservice/internal/api/middleware"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"time"
+)
+
+type ExchangeRateRequest struct {
+	FromCurrency string  `json:"from_currency" example:"USD"`
+	ToCurrency   string  `json:"to_currency" example:"RUB"`
+	Rate         float64 `json:"rate" example:"89.6883"`
+	ValidFrom    string  `json:"valid_from" example:"2024-01-01T00:00:00Z"`
+}
+
+// SaveExchangeRate godoc
+// @Summary      Сохранить курс валют
+// @Description  Создает или заменяет курс пересчета from_currency в to_currency, действующий с valid_from
+// @Tags         exchange-rates
+// @Accept       json
+// @Produce      json
+// @Param        body  body      ExchangeRateRequest  true  "Курс"
+// @Success      201   {object}  SuccessResponse "status: success"
+// @Failure      400   {object}  ErrorResponse   "invalid json / validation error"
+// @Failure      500   {object}  ErrorResponse   "internal server error"
+// @Router       /exchange-rates [post]
+func (h *RestHandler) SaveExchangeRate(w http.ResponseWriter, r *http.Request) {
+	l := apimw.FromContext(r.Context())
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	var req ExchangeRateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		l.Warn("Handler SaveExchangeRate: invalid json")
+		respondError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	rate := model.ExchangeRate{
+		FromCurrency: strings.ToUpper(req.FromCurrency),
+		ToCurrency:   strings.ToUpper(req.ToCurrency),
+		Rate:         req.Rate,
+	}
+	if !model.ValidCurrency(rate.FromCurrency) {
+		respondServiceError(w, r, domain.Validation("from_currency", "from_currency must be a three-letter ISO 4217 code"))
+		return
+	}
+	if !model.ValidCurrency(rate.ToCurrency) {
+		respondServiceError(w, r, domain.Validation("to_currency", "to_currency must be a three-letter ISO 4217 code"))
+		return
+	}
+
+	var err error
+	if rate.ValidFrom, err = time.Parse(time.RFC3339, req.ValidFrom); err != nil {
+		l.Warn("Handler SaveExchangeRate: invalid valid_from format")
+		respondServiceError(w, r, domain.Validation("valid_from", "invalid valid_from format"))
+		return
+	}
+
+	if err := h.s.SaveExchangeRate(ctx, rate); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, map[string]string{"status": "success"})
+}
+
+// ListExchangeRates godoc
+// @Summary      Список курсов валют
+// @Description  Возвращает сохраненные курсы, опционально для одной пары валют
+// @Tags         exchange-rates
+// @Produce      json
+// @Param        from_currency  query     string  false  "Исходная валюта"
+// @Param        to_currency    query     string  false  "Целевая валюта"
+// @Success      200            {array}   model.ExchangeRate
+// @Failure      500            {object}  ErrorResponse
+// @Router       /exchange-rates [get]
+func (h *RestHandler) ListExchangeRates(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	var fromCurrency, toCurrency *string
+	if from := strings.ToUpper(r.URL.Query().Get("from_currency")); from != "" {
+		fromCurrency = &from
+	}
+	if to := strings.ToUpper(r.URL.Query().Get("to_currency")); to != "" {
+		toCurrency = &to
+	}
+
+	rates, err := h.s.ListExchangeRates(ctx, fromCurrency, toCurrency)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, rates)
+}
+
+// DeleteExchangeRate godoc
+// @Summary      Удалить курс валют
+// @Description  Удаляет курс пары валют, действующий с valid_from
+// @Tags         exchange-rates
+// @Produce      json
+// @Param        from_currency  query     string  true  "Исходная валюта"
+// @Param        to_currency    query     string  true  "Целевая валюта"
+// @Param        valid_from     query     string  true  "Дата начала действия (RFC3339)"
+// @Success      200            {object}  SuccessResponse "status: success"
+// @Failure      400            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse   "exchange rate not found"
+// @Failure      500            {object}  ErrorResponse
+// @Router       /exchange-rates [delete]
+func (h *RestHandler) DeleteExchangeRate(w http.ResponseWriter, r *http.Request) {
+	l := apimw.FromContext(r.Context())
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	q := r.URL.Query()
+	fromCurrency := strings.ToUpper(q.Get("from_currency"))
+	toCurrency := strings.ToUpper(q.Get("to_currency"))
+	if fromCurrency == "" || toCurrency == "" {
+		l.Warn("Handler DeleteExchangeRate: missing currency parameters")
+		respondError(w, http.StatusBadRequest, "from_currency and to_currency are required")
+		return
+	}
+
+	validFrom, err := time.Parse(time.RFC3339, q.Get("valid_from"))
+	if err != nil {
+		l.Warn("Handler DeleteExchangeRate: invalid valid_from format")
+		respondServiceError(w, r, domain.Validation("valid_from", "invalid valid_from format"))
+		return
+	}
+
+	if err := h.s.DeleteExchangeRate(ctx, fromCurrency, toCurrency, validFrom); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]string{"status": "success"})
+}
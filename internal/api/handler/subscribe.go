@@ -1,12 +1,17 @@
 package handler
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 	apimw "subservice/internal/api/middleware"
+	"subservice/internal/domain"
 	"subservice/internal/model"
 	"time"
 
@@ -14,40 +19,66 @@ import (
 )
 
 type SubscriptionRequest struct {
+	// Id is assigned by the server on creation unless given; on update it
+	// selects the subscription instead of user_id and service_name.
+	Id          string `json:"id,omitempty" example:"0b6f1a3e-8c2d-4e5f-9a7b-1c2d3e4f5a6b"`
 	ServiceName string `json:"service_name" example:"Yandex Plus"`
-	Price       int64  `json:"price" example:"499"`
-	UserId      string `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
-	StartDate   string `json:"start_date" example:"2023-10-01T00:00:00Z"`
-	EndDate     string `json:"end_date,omitempty" example:"2025-10-01T00:00:00Z"`
+	// Price of a new subscription defaults to the catalog default_price of the
+	// service when omitted or zero.
+	Price     int64  `json:"price" example:"499"`
+	UserId    string `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	StartDate string `json:"start_date" example:"2023-10-01T00:00:00Z"`
+	EndDate   string `json:"end_date,omitempty" example:"2025-10-01T00:00:00Z"`
+	// TrialEndDate is the last free month and must lie within the subscription.
+	TrialEndDate string `json:"trial_end_date,omitempty" example:"2023-11-01T00:00:00Z"`
+	// BillingPeriod defaults to month and BillingInterval to 1.
+	BillingPeriod   string `json:"billing_period,omitempty" example:"month" enums:"week,month,quarter,year"`
+	BillingInterval int    `json:"billing_interval,omitempty" example:"1"`
+	// Currency is an ISO 4217 code and defaults to RUB.
+	Currency string `json:"currency,omitempty" example:"RUB"`
+	// Tags are case-insensitive labels of letters, digits, dashes and
+	// underscores; duplicates are dropped.
+	Tags []string `json:"tags,omitempty" example:"video,family"`
+	// Members share the subscription with user_id, who pays what their shares
+	// leave. Split defaults to equal; under percent a share is a percentage of
+	// every charge and under fixed an amount out of price.
+	Split   string          `json:"split,omitempty" example:"percent" enums:"equal,percent,fixed"`
+	Members []MemberRequest `json:"members,omitempty"`
 }
 
-type RequestError struct {
-	Message    string
-	StatusCode int
+type MemberRequest struct {
+	UserId string `json:"user_id" example:"8b0d3c5e-2f4a-4b6c-9d8e-7f6a5b4c3d2e"`
+	Share  int64  `json:"share,omitempty" example:"25"`
 }
 
 type ErrorResponse struct {
 	Error string `json:"error" example:"error message"`
+	Field string `json:"field,omitempty" example:"start_date"`
 }
 
 type SuccessResponse struct {
 	Status string `json:"status" example:"success"`
 }
 
-type SummeryResponse struct {
-	TotalPrice int `json:"total_price" example:"1497"`
+type CreatedResponse struct {
+	Status string `json:"status" example:"success"`
+	Id     string `json:"id" example:"0b6f1a3e-8c2d-4e5f-9a7b-1c2d3e4f5a6b"`
 }
 
 // Subscribe godoc
 // @Summary      Создать подписку
-// @Description  Создает запись о подписке пользователя
+// @Description  Создает запись о подписке пользователя и возвращает её id. У пользователя может быть несколько подписок на один сервис.
+// @Description  Без price (или с price 0) берётся default_price сервиса из каталога.
+// @Description  Повтор запроса с тем же заголовком Idempotency-Key возвращает исходный ответ; тот же ключ с другими данными даёт 422. Ключи действуют в пределах пользователя (user_id)
 // @Tags         subscriptions
 // @Accept       json
 // @Produce      json
-// @Param        body  body      SubscriptionRequest  true  "Данные подписки"
-// @Success      201   {object}  SuccessResponse   "status: success"
+// @Param        Idempotency-Key  header    string               false  "Ключ идемпотентности"
+// @Param        body             body      SubscriptionRequest  true   "Данные подписки"
+// @Success      201   {object}  CreatedResponse   "status: success, id"
 // @Failure      400   {object}  ErrorResponse   "invalid json / validation error"
-// @Failure      409   {object}  ErrorResponse   "subscription already exists"
+// @Failure      409   {object}  ErrorResponse   "subscription with this id already exists"
+// @Failure      422   {object}  ErrorResponse   "idempotency key was already used with a different request"
 // @Failure      500   {object}  ErrorResponse   "internal server error"
 // @Router       /subscriptions [post]
 func (h *RestHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
@@ -56,8 +87,6 @@ func (h *RestHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
 
-	var parsedReq = model.Subscription{}
-
 	var req SubscriptionRequest
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
@@ -66,33 +95,58 @@ func (h *RestHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if reqErr, sub := ValidateSubscriptionRequest(&req); reqErr != nil {
-		l.Warn("Handler Subscribe: validation error", zap.String("error", reqErr.Message))
-		respondError(w, reqErr.StatusCode, reqErr.Message)
+	sub, err := ValidateSubscriptionRequest(&req)
+	if err != nil {
+		l.Warn("Handler Subscribe: validation error", zap.Error(err))
+		respondServiceError(w, r, err)
 		return
-	} else {
-		parsedReq = *sub
 	}
 
-	if err := h.s.Subscribe(ctx, parsedReq); err != nil {
-		if err.Error() == "subscription already exists" {
-			respondError(w, http.StatusConflict, err.Error())
+	if sub.Id == uuid.Nil {
+		sub.Id = uuid.New()
+	}
+	created := CreatedResponse{Status: "success", Id: sub.Id.String()}
+
+	if key := r.Header.Get("Idempotency-Key"); key != "" {
+		body, _ := json.Marshal(created)
+		rec := model.IdempotencyKey{Key: key, ResponseStatus: http.StatusCreated, ResponseBody: body}
+
+		stored, replayed, err := h.s.SubscribeIdempotent(ctx, *sub, rec)
+		if err != nil {
+			respondServiceError(w, r, err)
 			return
 		}
-		respondError(w, http.StatusInternalServerError, err.Error())
+		if replayed {
+			w.Header().Set("Idempotent-Replayed", "true")
+		}
+		respondJSON(w, stored.ResponseStatus, stored.ResponseBody)
+		return
+	}
+
+	if err := h.s.Subscribe(ctx, *sub); err != nil {
+		respondServiceError(w, r, err)
 		return
 	}
-	respondJSON(w, http.StatusCreated, map[string]string{"status": "success"})
+	respondJSON(w, http.StatusCreated, created)
 }
 
 // GetSubscriptions godoc
 // @Summary      Список подписок пользователя
-// @Description  Возвращает все подписки для пользователя
+// @Description  Возвращает подписки пользователя постранично, с сортировкой и фильтрами. В список входят и совместные подписки, где пользователь — участник (members)
 // @Tags         subscriptions
 // @Produce      json
-// @Param        userId  path      string  true  "User ID (UUID)"
-// @Success      200     {array}   model.Subscription
-// @Failure      400     {object}  ErrorResponse "invalid userId parameter"
+// @Param        userId               path      string  true   "User ID (UUID)"
+// @Param        service_name_prefix  query     string  false  "Префикс названия сервиса"
+// @Param        tag                  query     string  false  "Только подписки с тегом"
+// @Param        active_at            query     string  false  "Активна на дату (RFC3339)"
+// @Param        min_price            query     int     false  "Минимальная цена"
+// @Param        max_price            query     int     false  "Максимальная цена"
+// @Param        sort                 query     string  false  "Поле сортировки" Enums(service_name, price, start_date)
+// @Param        order                query     string  false  "Направление сортировки" Enums(asc, desc)
+// @Param        limit                query     int     false  "Размер страницы (по умолчанию 50, максимум 500)"
+// @Param        cursor               query     string  false  "Курсор следующей страницы (next_cursor)"
+// @Success      200     {object}  model.SubscriptionPage
+// @Failure      400     {object}  ErrorResponse "invalid userId parameter / invalid query parameter"
 // @Failure      500     {object}  ErrorResponse "internal server error"
 // @Router       /subscriptions/{userId} [get]
 func (h *RestHandler) GetSubscriptions(w http.ResponseWriter, r *http.Request) {
@@ -109,21 +163,32 @@ func (h *RestHandler) GetSubscriptions(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	subs, err := h.s.ListSubscriptions(ctx, userId)
+	filter, err := parseListFilter(r)
+	if err != nil {
+		l.Warn("Handler GetSubscriptions: invalid parameters", zap.Error(err))
+		respondServiceError(w, r, err)
+		return
+	}
+	filter.UserId = &userId
+
+	page, err := h.s.ListSubscriptions(ctx, filter)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
-	respondJSON(w, http.StatusOK, subs)
+	respondJSON(w, http.StatusOK, page)
 }
 
 // UpdateSubscription godoc
 // @Summary      Обновить подписку
-// @Description  Обновляет запись подписки (по user_id + service_name)
+// @Description  Обновляет запись подписки по id из тела, а без него — по user_id + service_name, если у пользователя одна текущая подписка на сервис.
+// @Description  Пользователя и сервис подписки изменить нельзя. Новая цена действует с текущего месяца и попадает в историю цен, прошлые месяцы считаются по прежней.
+// @Description  Заголовок If-Match должен содержать ETag текущей версии или "*"
 // @Tags         subscriptions
 // @Accept       json
 // @Produce      json
-// @Param        body  body      SubscriptionRequest  true  "Данные подписки"
+// @Param        If-Match  header    string               true  "ETag версии подписки"
+// @Param        body      body      SubscriptionRequest  true  "Данные подписки"
 // @Success      200   {object}  SuccessResponse "status: success"
 // @Failure 400 {object} ErrorResponse "validation error"
 // @Example {json} Ошибка валидации:
@@ -139,6 +204,9 @@ func (h *RestHandler) GetSubscriptions(w http.ResponseWriter, r *http.Request) {
 //	  "error": "subscription not found"
 //	}
 //
+// @Failure      409   {object}  ErrorResponse   "several subscriptions match user_id and service_name"
+// @Failure      412   {object}  ErrorResponse   "subscription version mismatch"
+// @Failure      428   {object}  ErrorResponse   "If-Match header is required"
 // @Failure      500   {object}  ErrorResponse	 "internal server error"
 // @Example {json} Ошибка сервера:
 //
@@ -153,8 +221,6 @@ func (h *RestHandler) UpdateSubscription(w http.ResponseWriter, r *http.Request)
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
 
-	var parsedReq = model.Subscription{}
-
 	var req SubscriptionRequest
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
@@ -163,20 +229,28 @@ func (h *RestHandler) UpdateSubscription(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if reqErr, sub := ValidateSubscriptionRequest(&req); reqErr != nil {
-		l.Warn("Handler UpdateSubscription: validation error", zap.String("error", reqErr.Message))
-		respondError(w, reqErr.StatusCode, reqErr.Message)
+	sub, err := ValidateSubscriptionRequest(&req)
+	if err != nil {
+		l.Warn("Handler UpdateSubscription: validation error", zap.Error(err))
+		respondServiceError(w, r, err)
 		return
-	} else {
-		parsedReq = *sub
 	}
 
-	if err := h.s.UpdateSubscription(ctx, parsedReq); err != nil {
-		if err.Error() == "subscription not found" {
-			respondError(w, http.StatusNotFound, err.Error())
-			return
-		}
-		respondError(w, http.StatusInternalServerError, err.Error())
+	sub.Version, err = versionFromIfMatch(r)
+	if err != nil {
+		l.Warn("Handler UpdateSubscription: invalid If-Match header", zap.Error(err))
+		respondServiceError(w, r, err)
+		return
+	}
+
+	if sub.Id, err = h.subscriptionIdForUpdate(ctx, r, sub); err != nil {
+		l.Warn("Handler UpdateSubscription: cannot identify subscription", zap.Error(err))
+		respondServiceError(w, r, err)
+		return
+	}
+
+	if err := h.s.UpdateSubscription(ctx, *sub); err != nil {
+		respondServiceError(w, r, err)
 		return
 	}
 	respondJSON(w, http.StatusOK, map[string]string{"status": "success"})
@@ -184,14 +258,19 @@ func (h *RestHandler) UpdateSubscription(w http.ResponseWriter, r *http.Request)
 
 // Unsubscribe godoc
 // @Summary      Удалить подписку
-// @Description  Удаляет запись о подписке по user_id и service_name
+// @Description  Помечает подписку удалённой по user_id и service_name, если у пользователя одна текущая подписка на сервис. До очистки её можно восстановить.
+// @Description  Заголовок If-Match должен содержать ETag текущей версии или "*"
 // @Tags         subscriptions
 // @Produce      json
 // @Param        user_id       query     string  true  "User ID (UUID)"
 // @Param        service_name  query     string  true  "Название сервиса"
+// @Param        If-Match      header    string  true  "ETag версии подписки"
 // @Success      200           {object}  SuccessResponse "status: success"
 // @Failure      400           {object}  ErrorResponse "invalid user_id parameter / service_name is required"
 // @Failure      404           {object}  ErrorResponse   "subscription not found"
+// @Failure      409           {object}  ErrorResponse   "several subscriptions match user_id and service_name"
+// @Failure      412           {object}  ErrorResponse   "subscription version mismatch"
+// @Failure      428           {object}  ErrorResponse   "If-Match header is required"
 // @Failure      500           {object}  ErrorResponse "internal server error"
 // @Router       /subscriptions [delete]
 func (h *RestHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
@@ -200,28 +279,55 @@ func (h *RestHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
 
-	userIdStr := r.URL.Query().Get("user_id")
-	serviceName := r.URL.Query().Get("service_name")
+	id, err := h.subscriptionIdFromQuery(ctx, r)
+	if err != nil {
+		l.Warn("Handler Unsubscribe: cannot identify subscription", zap.Error(err))
+		respondServiceError(w, r, err)
+		return
+	}
 
-	userId, err := uuid.Parse(userIdStr)
-	if err != nil || userId == uuid.Nil {
-		l.Warn("Handler Unsubscribe: invalid user_id parameter", zap.String("user_id", userIdStr))
-		respondError(w, http.StatusBadRequest, "invalid user_id parameter")
+	version, err := versionFromIfMatch(r)
+	if err != nil {
+		l.Warn("Handler Unsubscribe: invalid If-Match header", zap.Error(err))
+		respondServiceError(w, r, err)
 		return
 	}
 
-	if serviceName == "" {
-		l.Warn("Handler Unsubscribe: service_name is empty")
-		respondError(w, http.StatusBadRequest, "service_name is required")
+	if err := h.s.Unsubscribe(ctx, id, version); err != nil {
+		respondServiceError(w, r, err)
 		return
 	}
+	respondJSON(w, http.StatusOK, map[string]string{"status": "success"})
+}
 
-	if err := h.s.Unsubscribe(ctx, userId, serviceName); err != nil {
-		if err.Error() == "subscription not found" {
-			respondError(w, http.StatusNotFound, err.Error())
-			return
-		}
-		respondError(w, http.StatusInternalServerError, err.Error())
+// RestoreSubscription godoc
+// @Summary      Восстановить подписку
+// @Description  Снимает пометку об удалении с подписки, если она ещё не очищена. Среди удалённых подписок пользователя на сервис должна быть ровно одна
+// @Tags         subscriptions
+// @Produce      json
+// @Param        userId       path      string  true  "User ID (UUID)"
+// @Param        serviceName  path      string  true  "Название сервиса"
+// @Success      200          {object}  SuccessResponse "status: success"
+// @Failure      400          {object}  ErrorResponse
+// @Failure      404          {object}  ErrorResponse   "deleted subscription not found"
+// @Failure      409          {object}  ErrorResponse   "several deleted subscriptions match"
+// @Failure      500          {object}  ErrorResponse   "internal server error"
+// @Router       /subscriptions/{userId}/{serviceName}/restore [post]
+func (h *RestHandler) RestoreSubscription(w http.ResponseWriter, r *http.Request) {
+	l := apimw.FromContext(r.Context())
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	id, err := h.subscriptionIdFromPath(ctx, r, true)
+	if err != nil {
+		l.Warn("Handler RestoreSubscription: cannot identify subscription", zap.Error(err))
+		respondServiceError(w, r, err)
+		return
+	}
+
+	if err := h.s.RestoreSubscription(ctx, id); err != nil {
+		respondServiceError(w, r, err)
 		return
 	}
 	respondJSON(w, http.StatusOK, map[string]string{"status": "success"})
@@ -229,14 +335,19 @@ func (h *RestHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
 
 // GetSubscription godoc
 // @Summary      Получить подписку
-// @Description  Возвращает одну подписку по user_id и service_name
+// @Description  Возвращает одну подписку по user_id и service_name с текущим статусом (scheduled, active, paused, ended). Версия подписки передаётся в заголовке ETag.
+// @Description  Из нескольких подписок на сервис выбирается единственная незавершённая, иначе ответ 409 и подписку нужно запрашивать по id
 // @Tags         subscriptions
 // @Produce      json
-// @Param        user_id       query     string  true  "User ID (UUID)"
-// @Param        service_name  query     string  true  "Название сервиса"
+// @Param        user_id        query     string  true   "User ID (UUID)"
+// @Param        service_name   query     string  true   "Название сервиса"
+// @Param        If-None-Match  header    string  false  "ETag известной клиенту версии"
 // @Success      200           {object}  model.Subscription
+// @Header       200           {string}  ETag  "Версия подписки"
+// @Success      304           "Подписка не изменилась"
 // @Failure      400           {object}  ErrorResponse
 // @Failure      404           {object}  ErrorResponse   "subscription not found"
+// @Failure      409           {object}  ErrorResponse   "several subscriptions match user_id and service_name"
 // @Failure      500           {object}  ErrorResponse
 // @Router       /subscriptions [get]
 func (h *RestHandler) GetSubscription(w http.ResponseWriter, r *http.Request) {
@@ -245,29 +356,22 @@ func (h *RestHandler) GetSubscription(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
 
-	userIdStr := r.URL.Query().Get("user_id")
-	serviceName := r.URL.Query().Get("service_name")
-
-	userId, err := uuid.Parse(userIdStr)
-	if err != nil || userId == uuid.Nil {
-		l.Warn("Handler GetSubscription: invalid user_id parameter", zap.String("user_id", userIdStr))
-		respondError(w, http.StatusBadRequest, "invalid user_id parameter")
+	id, err := h.subscriptionIdFromQuery(ctx, r)
+	if err != nil {
+		l.Warn("Handler GetSubscription: cannot identify subscription", zap.Error(err))
+		respondServiceError(w, r, err)
 		return
 	}
 
-	if serviceName == "" {
-		l.Warn("Handler GetSubscription: service_name is empty")
-		respondError(w, http.StatusBadRequest, "service_name is required")
+	sub, err := h.s.GetSubscription(ctx, id)
+	if err != nil {
+		respondServiceError(w, r, err)
 		return
 	}
 
-	sub, err := h.s.GetSubscription(ctx, userId, serviceName)
-	if err != nil {
-		if err.Error() == "subscription not found" {
-			respondError(w, http.StatusNotFound, err.Error())
-			return
-		}
-		respondError(w, http.StatusInternalServerError, err.Error())
+	w.Header().Set("ETag", etag(sub.Version))
+	if notModified(r, sub.Version) {
+		w.WriteHeader(http.StatusNotModified)
 		return
 	}
 	respondJSON(w, http.StatusOK, sub)
@@ -275,14 +379,24 @@ func (h *RestHandler) GetSubscription(w http.ResponseWriter, r *http.Request) {
 
 // GetSubscriptionSummary godoc
 // @Summary      Сумма подписок за период
-// @Description  Считает суммарную стоимость активных подписок по месяцам за период, с фильтрами
+// @Description  Считает суммарную стоимость активных подписок по месяцам за период, с фильтрами.
+// @Description  С параметром group_by дополнительно возвращает разбивку по месяцам, сервисам, пользователям и/или тегам.
+// @Description  В разбивке по тегам подписка учитывается в группе каждого своего тега, подписки без тегов — в группе без tag; total_price считается без повторов.
+// @Description  Пробные месяцы (до trial_end_date включительно) не оплачиваются и считаются в разбивке отдельно как trial_months.
+// @Description  Месяцы, когда подписка приостановлена, не учитываются; скидки по промокодам применяются к каждому месяцу их действия.
+// @Description  С user_id или группировкой по user_id совместная подписка учитывается для владельца и каждого участника только в размере его доли; иначе — полной стоимостью.
 // @Tags         subscriptions
 // @Produce      json
 // @Param        from          query     string  true  "Начало периода (RFC3339)"
 // @Param        to            query     string  true  "Конец периода (RFC3339)"
 // @Param        user_id       query     string  false "User ID (UUID)"
 // @Param        service_name  query     string  false "Название сервиса"
-// @Success      200           {object}  SummeryResponse
+// @Param        tag           query     string  false "Только подписки с тегом"
+// @Param        category      query     string  false "Только подписки на сервисы категории каталога"
+// @Param        group_by      query     string  false "Группировка через запятую: month, service_name, user_id, tag. При группировке по month каждый месяц периода попадает в разбивку, месяцы без списаний — с нулевыми суммами"
+// @Param        amortize      query     bool    false "Распределять стоимость квартальных, годовых и недельных планов равномерно по месяцам"
+// @Param        target_currency  query  string  false "Валюта результата (ISO 4217), суммы пересчитываются по курсу на каждый месяц; обязательна, если у подписок разные валюты"
+// @Success      200           {object}  model.Summary
 // @Failure      400           {object}  ErrorResponse
 // @Failure      500           {object}  ErrorResponse
 // @Router       /subscriptions/summary [get]
@@ -292,86 +406,295 @@ func (h *RestHandler) GetSubscriptionSummary(w http.ResponseWriter, r *http.Requ
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
 
-	fromStr := r.URL.Query().Get("from")
-	toStr := r.URL.Query().Get("to")
-	userIdStr := r.URL.Query().Get("user_id")
-	serviceName := r.URL.Query().Get("service_name")
-
-	if fromStr == "" || toStr == "" {
-		l.Warn("Handler GetSubscriptionSummary: missing from or to parameters")
-		respondError(w, http.StatusBadRequest, "from and to parameters are required")
+	filter, err := parseSummaryFilter(r)
+	if err != nil {
+		l.Warn("Handler GetSubscriptionSummary: invalid parameters", zap.Error(err))
+		respondServiceError(w, r, err)
 		return
 	}
 
-	from, err := time.Parse(time.RFC3339, fromStr)
+	summary, err := h.s.GetSubscriptionSummary(ctx, filter)
 	if err != nil {
-		l.Warn("Handler GetSubscriptionSummary: invalid from date format")
-		respondError(w, http.StatusBadRequest, "invalid from date format")
+		respondServiceError(w, r, err)
 		return
 	}
 
-	to, err := time.Parse(time.RFC3339, toStr)
-	if err != nil {
-		l.Warn("Handler GetSubscriptionSummary: invalid to date format")
-		respondError(w, http.StatusBadRequest, "invalid to date format")
-		return
+	respondJSON(w, http.StatusOK, summary)
+}
+
+// parseSummaryFilter reads the period, filter and grouping query parameters of
+// the summary endpoints.
+func parseSummaryFilter(r *http.Request) (model.SummaryFilter, error) {
+	q := r.URL.Query()
+	var filter model.SummaryFilter
+
+	fromStr := q.Get("from")
+	toStr := q.Get("to")
+	if fromStr == "" || toStr == "" {
+		return filter, domain.Validation("from", "from and to parameters are required")
+	}
+
+	var err error
+	if filter.From, err = time.Parse(time.RFC3339, fromStr); err != nil {
+		return filter, domain.Validation("from", "invalid from date format")
+	}
+	if filter.To, err = time.Parse(time.RFC3339, toStr); err != nil {
+		return filter, domain.Validation("to", "invalid to date format")
 	}
 
-	var userId *uuid.UUID
-	if userIdStr != "" {
+	if userIdStr := q.Get("user_id"); userIdStr != "" {
 		uid, err := uuid.Parse(userIdStr)
 		if err != nil || uid == uuid.Nil {
-			l.Warn("Handler GetSubscriptionSummary: invalid user_id parameter")
-			respondError(w, http.StatusBadRequest, "invalid user_id parameter")
-			return
+			return filter, domain.Validation("user_id", "invalid user_id parameter")
 		}
-		userId = &uid
+		filter.UserId = &uid
 	}
 
-	var svcName *string
-	if serviceName != "" {
-		svcName = &serviceName
+	if serviceName := q.Get("service_name"); serviceName != "" {
+		filter.ServiceName = &serviceName
 	}
 
-	summary, err := h.s.GetSubscriptionSummary(ctx, from, to, userId, svcName)
-	if err != nil {
-		l.Error("Handler GetSubscriptionSummary: internal error", zap.Error(err))
-		respondError(w, http.StatusInternalServerError, err.Error())
-		return
+	if filter.Tag, err = parseTagParam(q.Get("tag")); err != nil {
+		return filter, err
+	}
+
+	if category := strings.TrimSpace(q.Get("category")); category != "" {
+		filter.Category = &category
+	}
+
+	if currency := strings.ToUpper(q.Get("target_currency")); currency != "" {
+		if !model.ValidCurrency(currency) {
+			return filter, domain.Validation("target_currency", "target_currency must be a three-letter ISO 4217 code")
+		}
+		filter.TargetCurrency = &currency
+	}
+
+	if amortize := q.Get("amortize"); amortize != "" {
+		if filter.Amortize, err = strconv.ParseBool(amortize); err != nil {
+			return filter, domain.Validation("amortize", "invalid amortize parameter")
+		}
+	}
+
+	if groupBy := q.Get("group_by"); groupBy != "" {
+		for _, g := range strings.Split(groupBy, ",") {
+			switch strings.TrimSpace(g) {
+			case model.GroupByMonth:
+				filter.GroupByMonth = true
+			case model.GroupByServiceName:
+				filter.GroupByService = true
+			case model.GroupByUserId:
+				filter.GroupByUser = true
+			case model.GroupByTag:
+				filter.GroupByTag = true
+			default:
+				return filter, domain.Validation("group_by", "group_by must be a list of month, service_name, user_id, tag")
+			}
+		}
+	}
+
+	return filter, nil
+}
+
+// parseTags normalizes the tags of a request and returns them sorted.
+func parseTags(raw []string) ([]string, error) {
+	if len(raw) > model.MaxTags {
+		return nil, domain.Validation("tags", "at most %d tags are allowed", model.MaxTags)
+	}
+	tags := make([]string, len(raw))
+	for i, t := range raw {
+		tags[i] = model.NormalizeTag(t)
+		if !model.ValidTag(tags[i]) {
+			return nil, domain.Validation("tags", "invalid tag %q", t)
+		}
+	}
+	return model.SortedTags(tags), nil
+}
+
+// parseMembers validates the members of a subscription owned by owner and
+// returns them sorted by user id together with the split, which defaults to
+// equal. Percentages may add up to at most 100 and fixed shares to at most
+// price; the owner pays the rest.
+func parseMembers(split string, raw []MemberRequest, owner uuid.UUID, price int64) (string, []model.Member, error) {
+	if len(raw) == 0 {
+		if split != "" {
+			return "", nil, domain.Validation("split", "split requires members")
+		}
+		return "", nil, nil
+	}
+	if len(raw) > model.MaxMembers {
+		return "", nil, domain.Validation("members", "at most %d members are allowed", model.MaxMembers)
+	}
+	if split == "" {
+		split = model.SplitEqual
+	}
+	if !model.ValidSplit(split) {
+		return "", nil, domain.Validation("split", "split must be one of equal, percent, fixed")
+	}
+
+	members := make([]model.Member, len(raw))
+	seen := make(map[uuid.UUID]bool, len(raw))
+	var shares int64
+	for i, m := range raw {
+		userId, err := uuid.Parse(m.UserId)
+		if err != nil || userId == uuid.Nil {
+			return "", nil, domain.Validation("members", "invalid member user_id %q", m.UserId)
+		}
+		if userId == owner {
+			return "", nil, domain.Validation("members", "the owner cannot be a member")
+		}
+		if seen[userId] {
+			return "", nil, domain.Validation("members", "duplicate member %s", userId)
+		}
+		seen[userId] = true
+
+		if split == model.SplitEqual && m.Share != 0 {
+			return "", nil, domain.Validation("members", "share is not used with the equal split")
+		}
+		if split != model.SplitEqual && m.Share <= 0 {
+			return "", nil, domain.Validation("members", "member shares must be positive")
+		}
+		shares += m.Share
+		members[i] = model.Member{UserId: userId, Share: m.Share}
+	}
+
+	if split == model.SplitPercent && shares > 100 {
+		return "", nil, domain.Validation("members", "member shares cannot exceed 100 percent")
+	}
+	if split == model.SplitFixed && shares > price {
+		return "", nil, domain.Validation("members", "member shares cannot exceed the price")
 	}
+	sort.Slice(members, func(i, j int) bool {
+		return bytes.Compare(members[i].UserId[:], members[j].UserId[:]) < 0
+	})
+	return split, members, nil
+}
 
-	respondJSON(w, http.StatusOK, map[string]int{"total_price": summary})
+// parseTagParam reads an optional tag query parameter.
+func parseTagParam(raw string) (*string, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	tag := model.NormalizeTag(raw)
+	if !model.ValidTag(tag) {
+		return nil, domain.Validation("tag", "invalid tag parameter")
+	}
+	return &tag, nil
 }
 
-func ValidateSubscriptionRequest(req *SubscriptionRequest) (*RequestError, *model.Subscription) {
+// subscriptionIdFromQuery identifies the subscription by the {id} path
+// parameter, or by the user_id and service_name query parameters.
+func (h *RestHandler) subscriptionIdFromQuery(ctx context.Context, r *http.Request) (uuid.UUID, error) {
+	if raw := chi.URLParam(r, "id"); raw != "" {
+		return parseSubscriptionId(raw)
+	}
+	userId, err := uuid.Parse(r.URL.Query().Get("user_id"))
+	if err != nil || userId == uuid.Nil {
+		return uuid.Nil, domain.Validation("user_id", "invalid user_id parameter")
+	}
+	serviceName := r.URL.Query().Get("service_name")
+	if serviceName == "" {
+		return uuid.Nil, domain.Validation("service_name", "service_name is required")
+	}
+	return h.s.ResolveSubscriptionId(ctx, userId, serviceName, false)
+}
+
+// subscriptionIdForUpdate identifies the subscription a full update replaces:
+// by the {id} path parameter, which the body may repeat, by the id of the body,
+// or by its user_id and service_name.
+func (h *RestHandler) subscriptionIdForUpdate(ctx context.Context, r *http.Request, sub *model.Subscription) (uuid.UUID, error) {
+	if raw := chi.URLParam(r, "id"); raw != "" {
+		id, err := parseSubscriptionId(raw)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		if sub.Id != uuid.Nil && sub.Id != id {
+			return uuid.Nil, domain.Validation("id", "id does not match the path")
+		}
+		return id, nil
+	}
+	if sub.Id != uuid.Nil {
+		return sub.Id, nil
+	}
+	return h.s.ResolveSubscriptionId(ctx, sub.UserId, sub.ServiceName, false)
+}
+
+// ValidateSubscriptionRequest parses req into a model.Subscription. Failures are
+// returned as *domain.ValidationError naming the offending field.
+func ValidateSubscriptionRequest(req *SubscriptionRequest) (*model.Subscription, error) {
 	var parsedReq = model.Subscription{}
 	var err error
 
+	if req.Id != "" {
+		if parsedReq.Id, err = uuid.Parse(req.Id); err != nil || parsedReq.Id == uuid.Nil {
+			return nil, domain.Validation("id", "invalid id parameter")
+		}
+	}
+
 	parsedReq.UserId, err = uuid.Parse(req.UserId)
 	if err != nil || parsedReq.UserId == uuid.Nil {
-		return &RequestError{Message: "invalid user_id parameter", StatusCode: http.StatusBadRequest}, nil
+		return nil, domain.Validation("user_id", "invalid user_id parameter")
 	}
 
 	if req.ServiceName == "" {
-		return &RequestError{Message: "service_name is required", StatusCode: http.StatusBadRequest}, nil
+		return nil, domain.Validation("service_name", "service_name is required")
 	}
 	parsedReq.ServiceName = req.ServiceName
 
 	if req.Price < 0 {
-		return &RequestError{Message: "price cannot be negative", StatusCode: http.StatusBadRequest}, nil
+		return nil, domain.Validation("price", "price cannot be negative")
 	}
 	parsedReq.Price = req.Price
 
 	if parsedReq.StartDate, err = time.Parse(time.RFC3339, req.StartDate); err != nil {
-		return &RequestError{Message: "invalid start_date format", StatusCode: http.StatusBadRequest}, nil
+		return nil, domain.Validation("start_date", "invalid start_date format")
 	}
 
 	if req.EndDate != "" {
 		end, err := time.Parse(time.RFC3339, req.EndDate)
 		if err != nil {
-			return &RequestError{Message: "invalid end_date format", StatusCode: http.StatusBadRequest}, nil
+			return nil, domain.Validation("end_date", "invalid end_date format")
 		}
 		parsedReq.EndDate = &end
 	}
-	return nil, &parsedReq
+
+	if req.TrialEndDate != "" {
+		trialEnd, err := time.Parse(time.RFC3339, req.TrialEndDate)
+		if err != nil {
+			return nil, domain.Validation("trial_end_date", "invalid trial_end_date format")
+		}
+		parsedReq.TrialEndDate = &trialEnd
+	}
+
+	parsedReq.BillingPeriod = req.BillingPeriod
+	if parsedReq.BillingPeriod == "" {
+		parsedReq.BillingPeriod = model.BillingMonth
+	}
+	if !model.ValidBillingPeriod(parsedReq.BillingPeriod) {
+		return nil, domain.Validation("billing_period", "billing_period must be one of week, month, quarter, year")
+	}
+
+	parsedReq.BillingInterval = req.BillingInterval
+	if parsedReq.BillingInterval == 0 {
+		parsedReq.BillingInterval = 1
+	}
+	if parsedReq.BillingInterval < 0 {
+		return nil, domain.Validation("billing_interval", "billing_interval must be positive")
+	}
+
+	parsedReq.Currency = strings.ToUpper(req.Currency)
+	if parsedReq.Currency == "" {
+		parsedReq.Currency = model.DefaultCurrency
+	}
+	if !model.ValidCurrency(parsedReq.Currency) {
+		return nil, domain.Validation("currency", "currency must be a three-letter ISO 4217 code")
+	}
+
+	if parsedReq.Tags, err = parseTags(req.Tags); err != nil {
+		return nil, err
+	}
+
+	if parsedReq.Split, parsedReq.Members, err = parseMembers(req.Split, req.Members, parsedReq.UserId, parsedReq.Price); err != nil {
+		return nil, err
+	}
+	return &parsedReq, nil
 }
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"subservice/internal/api/handler"
+	"subservice/internal/testutil"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestSubscribeIdempotencyKey(t *testing.T) {
+	body := func(userId uuid.UUID, price int) string {
+		return fmt.Sprintf(`{"service_name":"Yandex Plus","price":%d,"user_id":%q,"start_date":"2024-01-01T00:00:00Z"}`, price, userId)
+	}
+
+	tests := []struct {
+		name         string
+		second       string
+		want         int
+		wantReplayed bool
+		wantSameId   bool
+	}{
+		{name: "same request is replayed", second: body(testutil.Owner, 100), want: http.StatusCreated, wantReplayed: true, wantSameId: true},
+		{name: "different request", second: body(testutil.Owner, 200), want: http.StatusUnprocessableEntity},
+		{name: "other user", second: body(testutil.MemberB, 100), want: http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter()
+			rec := serve(router, http.MethodPost, "/api/v1/subscriptions", body(testutil.Owner, 100), "Idempotency-Key", "key-1")
+			if rec.Code != http.StatusCreated {
+				t.Fatalf("first POST = %d %s, want 201", rec.Code, rec.Body)
+			}
+			var first handler.CreatedResponse
+			decode(t, rec, &first)
+
+			rec = serve(router, http.MethodPost, "/api/v1/subscriptions", tt.second, "Idempotency-Key", "key-1")
+			if rec.Code != tt.want {
+				t.Fatalf("second POST = %d %s, want %d", rec.Code, rec.Body, tt.want)
+			}
+			if replayed := rec.Header().Get("Idempotent-Replayed") == "true"; replayed != tt.wantReplayed {
+				t.Errorf("replayed = %t, want %t", replayed, tt.wantReplayed)
+			}
+			if tt.want != http.StatusCreated {
+				return
+			}
+			var second handler.CreatedResponse
+			decode(t, rec, &second)
+			if (second.Id == first.Id) != tt.wantSameId {
+				t.Errorf("second id = %s, first id = %s", second.Id, first.Id)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"subservice/internal/api/handler"
+	"subservice/internal/model"
+	"subservice/internal/testutil"
+	"testing"
+)
+
+func TestImportSubscriptions(t *testing.T) {
+	owner := testutil.Owner.String()
+
+	tests := []struct {
+		name         string
+		contentType  string
+		body         string
+		wantAccepted int
+		wantErrors   []handler.ImportRowError
+	}{
+		{
+			name:        "csv",
+			contentType: "text/csv",
+			body: strings.Join([]string{
+				"service_name,price,user_id,start_date,tags,members",
+				"Yandex Plus,100," + owner + ",2024-01-01T00:00:00Z,video;family,",
+				"Kinopoisk,abc," + owner + ",2024-01-01T00:00:00Z,,",
+				`"Okko`,
+				`Premium",100,` + owner + ",01-2024,,",
+				"Ivi,100," + owner + ",2024-01-01T00:00:00Z,," + testutil.MemberB.String() + ":x",
+				"Start,100," + owner + ",2024-01-01T00:00:00Z",
+				"Yandex Plus,100," + owner + ",2024-01-01T00:00:00Z,,",
+				"Wink,100," + owner + ",2024-01-01T00:00:00Z,,",
+			}, "\n"),
+			wantAccepted: 2,
+			wantErrors: []handler.ImportRowError{
+				{Line: 3, Field: "price"},
+				{Line: 4, Field: "start_date"},
+				{Line: 6, Field: "members"},
+				{Line: 7},
+				{Line: 8},
+			},
+		},
+		{
+			name:        "ndjson",
+			contentType: "application/x-ndjson",
+			body: strings.Join([]string{
+				`{"service_name":"Yandex Plus","price":100,"user_id":"` + owner + `","start_date":"2024-01-01T00:00:00Z"}`,
+				``,
+				`{"service_name":"Kinopoisk","price":100,"user_id":"` + owner + `","start_date":"2024-01-01T00:00:00Z","colour":"red"}`,
+				`{"service_name":"Okko",`,
+				`{"service_name":"Ivi","price":-1,"user_id":"` + owner + `","start_date":"2024-01-01T00:00:00Z"}`,
+				`{"service_name":"Wink","price":100,"user_id":"` + owner + `","start_date":"2024-01-01T00:00:00Z"}`,
+			}, "\n"),
+			wantAccepted: 2,
+			wantErrors: []handler.ImportRowError{
+				{Line: 3},
+				{Line: 4},
+				{Line: 5, Field: "price"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter()
+			rec := serve(router, http.MethodPost, "/api/v1/subscriptions/import", tt.body, "Content-Type", tt.contentType)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("POST = %d %s, want 200", rec.Code, rec.Body)
+			}
+			var report handler.ImportReport
+			decode(t, rec, &report)
+
+			if report.Accepted != tt.wantAccepted || report.Rejected != len(tt.wantErrors) || report.Total != tt.wantAccepted+len(tt.wantErrors) {
+				t.Errorf("report = %+v, want %d accepted and %d rejected", report, tt.wantAccepted, len(tt.wantErrors))
+			}
+			if len(report.Errors) != len(tt.wantErrors) {
+				t.Fatalf("errors = %+v, want %+v", report.Errors, tt.wantErrors)
+			}
+			for i, got := range report.Errors {
+				if want := tt.wantErrors[i]; got.Line != want.Line || got.Field != want.Field {
+					t.Errorf("error %d = %+v, want line %d field %q", i, got, want.Line, want.Field)
+				}
+			}
+
+			rec = serve(router, http.MethodGet, "/api/v1/subscriptions/"+owner, "")
+			var page model.SubscriptionPage
+			decode(t, rec, &page)
+			if len(page.Items) != tt.wantAccepted {
+				t.Errorf("listed %d subscriptions, want %d", len(page.Items), tt.wantAccepted)
+			}
+		})
+	}
+}
+
+func TestImportSubscriptionsDryRun(t *testing.T) {
+	router := newTestRouter()
+	body := "service_name,price,user_id,start_date\nYandex Plus,100," + testutil.Owner.String() + ",2024-01-01T00:00:00Z\n"
+	rec := serve(router, http.MethodPost, "/api/v1/subscriptions/import?format=csv&dry_run=true", body)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST = %d %s, want 200", rec.Code, rec.Body)
+	}
+	var report handler.ImportReport
+	decode(t, rec, &report)
+	if !report.DryRun || report.Accepted != 1 {
+		t.Errorf("report = %+v, want a dry run with 1 accepted row", report)
+	}
+
+	rec = serve(router, http.MethodGet, "/api/v1/subscriptions/"+testutil.Owner.String(), "")
+	var page model.SubscriptionPage
+	decode(t, rec, &page)
+	if len(page.Items) != 0 {
+		t.Errorf("dry run stored %d subscriptions", len(page.Items))
+	}
+}
+
+func TestImportSubscriptionsRejectedBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		target      string
+		contentType string
+		body        string
+		want        int
+	}{
+		{name: "unsupported content type", target: "/api/v1/subscriptions/import", contentType: "application/json", body: "{}", want: http.StatusUnsupportedMediaType},
+		{name: "unknown format", target: "/api/v1/subscriptions/import?format=xlsx", body: "{}", want: http.StatusUnsupportedMediaType},
+		{name: "unknown csv column", target: "/api/v1/subscriptions/import", contentType: "text/csv", body: "service_name,colour\n", want: http.StatusBadRequest},
+		{name: "missing csv header", target: "/api/v1/subscriptions/import", contentType: "text/csv", want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(newTestRouter(), http.MethodPost, tt.target, tt.body, "Content-Type", tt.contentType)
+			if rec.Code != tt.want {
+				t.Errorf("POST = %d %s, want %d", rec.Code, rec.Body, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+)
+
+// ActorHeader names the caller on whose behalf a request is made. The service
+// has no authentication of its own, so the value is taken as is.
+const ActorHeader = "X-Actor"
+
+type actorKey struct{}
+
+func ActorFromContext(ctx context.Context) string {
+	if v, ok := ctx.Value(actorKey{}).(string); ok {
+		return v
+	}
+	return ""
+}
+
+func WithActor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if actor := r.Header.Get(ActorHeader); actor != "" {
+			r = r.WithContext(context.WithValue(r.Context(), actorKey{}, actor))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
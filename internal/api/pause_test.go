@@ -0,0 +1,34 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestPauseIncrementsVersion(t *testing.T) {
+	router := newTestRouter()
+	id := subscribe(t, router)
+	target := "/api/v1/subscriptions/by-id/" + id
+
+	steps := []struct {
+		path     string
+		body     string
+		want     int
+		wantETag string
+	}{
+		{path: "/pause", body: `{"from":"2024-03-01T00:00:00Z"}`, want: http.StatusCreated, wantETag: `"2"`},
+		{path: "/pause", body: `{"from":"2024-04-01T00:00:00Z"}`, want: http.StatusConflict, wantETag: `"2"`},
+		{path: "/resume", body: `{"resume_from":"2024-05-01T00:00:00Z"}`, want: http.StatusOK, wantETag: `"3"`},
+	}
+
+	for _, step := range steps {
+		rec := serve(router, http.MethodPost, target+step.path, step.body)
+		if rec.Code != step.want {
+			t.Fatalf("POST %s = %d %s, want %d", step.path, rec.Code, rec.Body, step.want)
+		}
+		rec = serve(router, http.MethodGet, target, "")
+		if got := rec.Header().Get("ETag"); got != step.wantETag {
+			t.Errorf("ETag after %s = %s, want %s", step.path, got, step.wantETag)
+		}
+	}
+}
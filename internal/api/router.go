@@ -22,6 +22,7 @@ func SetupRouter(s *service.SubscriptionService, l *zap.Logger) *Router {
 
 	r.Use(middleware.RequestID)
 	r.Use(apimw.WithLogger(l))
+	r.Use(apimw.WithActor)
 	r.Use(middleware.Recoverer)
 
 	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
@@ -37,6 +38,64 @@ func SetupRouter(s *service.SubscriptionService, l *zap.Logger) *Router {
 		r.Delete("/subscriptions", h.Unsubscribe)
 		r.Get("/subscriptions", h.GetSubscription)
 		r.Get("/subscriptions/summary", h.GetSubscriptionSummary)
+		r.Get("/subscriptions/history", h.GetSubscriptionHistory)
+		r.Post("/subscriptions/batch", h.BatchSubscriptions)
+		r.Post("/subscriptions/import", h.ImportSubscriptions)
+		r.Get("/subscriptions/export", h.ExportSubscriptions)
+		r.Get("/subscriptions/summary/export", h.ExportSubscriptionSummary)
+		r.Patch("/subscriptions/{userId}/{serviceName}", h.PatchSubscription)
+		r.Post("/subscriptions/{userId}/{serviceName}/prices", h.SchedulePriceChange)
+		r.Get("/subscriptions/{userId}/{serviceName}/prices", h.GetPriceHistory)
+		r.Post("/subscriptions/{userId}/{serviceName}/restore", h.RestoreSubscription)
+		r.Post("/subscriptions/{userId}/{serviceName}/pause", h.PauseSubscription)
+		r.Post("/subscriptions/{userId}/{serviceName}/resume", h.ResumeSubscription)
+		r.Get("/subscriptions/{userId}/{serviceName}/pauses", h.GetPauses)
+		r.Post("/subscriptions/{userId}/{serviceName}/promotion", h.ApplyPromotion)
+		r.Get("/subscriptions/{userId}/{serviceName}/promotion", h.GetAppliedPromotion)
+		r.Delete("/subscriptions/{userId}/{serviceName}/promotion", h.RemovePromotion)
+		r.Get("/subscriptions/{userId}/{serviceName}/promotions", h.ListAppliedPromotions)
+
+		r.Route("/subscriptions/by-id/{id}", func(r chi.Router) {
+			r.Get("/", h.GetSubscriptionById)
+			r.Put("/", h.UpdateSubscriptionById)
+			r.Patch("/", h.PatchSubscriptionById)
+			r.Delete("/", h.UnsubscribeById)
+			r.Post("/restore", h.RestoreSubscriptionById)
+			r.Get("/history", h.GetSubscriptionHistoryById)
+			r.Post("/prices", h.SchedulePriceChangeById)
+			r.Get("/prices", h.GetPriceHistoryById)
+			r.Post("/pause", h.PauseSubscriptionById)
+			r.Post("/resume", h.ResumeSubscriptionById)
+			r.Get("/pauses", h.GetPausesById)
+			r.Post("/promotion", h.ApplyPromotionById)
+			r.Get("/promotion", h.GetAppliedPromotionById)
+			r.Delete("/promotion", h.RemovePromotionById)
+			r.Get("/promotions", h.ListAppliedPromotionsById)
+		})
+
+		r.Post("/exchange-rates", h.SaveExchangeRate)
+		r.Get("/exchange-rates", h.ListExchangeRates)
+		r.Delete("/exchange-rates", h.DeleteExchangeRate)
+
+		r.Post("/promotions", h.CreatePromotion)
+		r.Get("/promotions", h.ListPromotions)
+		r.Get("/promotions/{code}", h.GetPromotion)
+		r.Delete("/promotions/{code}", h.DeletePromotion)
+
+		r.Post("/services", h.CreateCatalogService)
+		r.Get("/services", h.ListCatalogServices)
+		r.Get("/services/{name}", h.GetCatalogService)
+		r.Put("/services/{name}", h.UpdateCatalogService)
+		r.Delete("/services/{name}", h.DeleteCatalogService)
+
+		r.Get("/users/{userId}/calendar.ics", h.GetCalendar)
+		r.Put("/users/{userId}/budgets", h.SaveBudget)
+		r.Get("/users/{userId}/budgets", h.ListBudgets)
+		r.Delete("/users/{userId}/budgets", h.DeleteBudget)
+		r.Get("/users/{userId}/budgets/evaluation", h.EvaluateBudgets)
+		r.Post("/users/{userId}/budgets/evaluation", h.RecordBudgetAlerts)
+
+		r.Get("/admin/subscriptions", h.AdminListSubscriptions)
 	})
 
 	return &Router{r: r}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"subservice/internal/api/handler"
+	"subservice/internal/model"
+	"subservice/internal/service"
+	"subservice/internal/storage/memory"
+	"subservice/internal/testutil"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// newTestRouter returns the API router over an empty in-memory storage.
+func newTestRouter() *Router {
+	return SetupRouter(service.NewSubscriptionService(memory.NewStorage(), zap.NewNop()), zap.NewNop())
+}
+
+// serve sends a request to router and records the response. header holds
+// pairs of header names and values; headers with empty values are not sent.
+func serve(router *Router, method, target, body string, header ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	for i := 0; i+1 < len(header); i += 2 {
+		if header[i+1] != "" {
+			req.Header.Set(header[i], header[i+1])
+		}
+	}
+	rec := httptest.NewRecorder()
+	router.r.ServeHTTP(rec, req)
+	return rec
+}
+
+func decode(t *testing.T, rec *httptest.ResponseRecorder, v any) {
+	t.Helper()
+	if err := json.NewDecoder(rec.Body).Decode(v); err != nil {
+		t.Fatalf("decode %q: %v", rec.Body.String(), err)
+	}
+}
+
+// subscribe creates a monthly subscription of testutil.Owner to Yandex Plus
+// and returns its id.
+func subscribe(t *testing.T, router *Router) string {
+	t.Helper()
+	body := `{"service_name":"Yandex Plus","price":100,"user_id":"` + testutil.Owner.String() + `","start_date":"2024-01-01T00:00:00Z"}`
+	rec := serve(router, http.MethodPost, "/api/v1/subscriptions", body)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST /subscriptions = %d %s, want 201", rec.Code, rec.Body)
+	}
+	var created handler.CreatedResponse
+	decode(t, rec, &created)
+	return created.Id
+}
+
+func TestSubscriptionLifecycle(t *testing.T) {
+	router := newTestRouter()
+	id := subscribe(t, router)
+	target := "/api/v1/subscriptions/by-id/" + id
+
+	rec := serve(router, http.MethodGet, target, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET = %d %s, want 200", rec.Code, rec.Body)
+	}
+	var sub model.Subscription
+	decode(t, rec, &sub)
+	if sub.Id.String() != id || sub.UserId != testutil.Owner || sub.Price != 100 || !sub.StartDate.Equal(testutil.Month(2024, time.January)) {
+		t.Errorf("GET = %+v", sub)
+	}
+
+	if rec := serve(router, http.MethodDelete, target, "", "If-Match", "*"); rec.Code != http.StatusOK {
+		t.Fatalf("DELETE = %d %s, want 200", rec.Code, rec.Body)
+	}
+	if rec := serve(router, http.MethodGet, target, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("GET after DELETE = %d, want 404", rec.Code)
+	}
+}
+
+func TestSubscribeValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		wantField string
+	}{
+		{name: "missing service name", body: `{"price":100,"user_id":"` + testutil.Owner.String() + `","start_date":"2024-01-01T00:00:00Z"}`, wantField: "service_name"},
+		{name: "invalid user id", body: `{"service_name":"Yandex Plus","price":100,"user_id":"nope","start_date":"2024-01-01T00:00:00Z"}`, wantField: "user_id"},
+		{name: "invalid start date", body: `{"service_name":"Yandex Plus","price":100,"user_id":"` + testutil.Owner.String() + `","start_date":"01-2024"}`, wantField: "start_date"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(newTestRouter(), http.MethodPost, "/api/v1/subscriptions", tt.body)
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("POST = %d %s, want 400", rec.Code, rec.Body)
+			}
+			var resp handler.ErrorResponse
+			decode(t, rec, &resp)
+			if resp.Field != tt.wantField {
+				t.Errorf("field = %q, want %q", resp.Field, tt.wantField)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"time"
 )
 
 type Config struct {
@@ -12,6 +13,17 @@ type Config struct {
 	ApiAddress  string
 	Env         string
 	LogLevel    string
+	StorageType string
+	AutoMigrate bool
+	// PurgeRetention is how long soft-deleted subscriptions are kept before
+	// the purge job removes them; zero keeps them. The job still removes
+	// expired idempotency keys.
+	PurgeRetention time.Duration
+	PurgeInterval  time.Duration
+	IdempotencyTTL time.Duration
+	// RejectUnknownServices makes Subscribe and UpdateSubscription refuse
+	// service names that are not in the service catalog.
+	RejectUnknownServices bool
 }
 
 func Load() *Config {
@@ -24,6 +36,14 @@ func Load() *Config {
 		ApiAddress:  getEnv("API_ADDRESS", ":8080"),
 		Env:         getEnv("ENV", "prod"),
 		LogLevel:    getEnv("LOG_LEVEL", "info"),
+		StorageType: getEnv("STORAGE_TYPE", "postgres"),
+		AutoMigrate: getEnvAsBool("AUTO_MIGRATE", false),
+
+		PurgeRetention: getEnvAsDuration("PURGE_RETENTION", 30*24*time.Hour),
+		PurgeInterval:  getEnvAsPositiveDuration("PURGE_INTERVAL", time.Hour),
+		IdempotencyTTL: getEnvAsPositiveDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+
+		RejectUnknownServices: getEnvAsBool("REJECT_UNKNOWN_SERVICES", false),
 	}
 
 	log.Println("Config loaded")
@@ -45,3 +65,32 @@ func getEnvAsInt(key string, fallback int) int {
 	}
 	return fallback
 }
+
+func getEnvAsBool(key string, fallback bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return fallback
+}
+
+func getEnvAsDuration(key string, fallback time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if durationValue, err := time.ParseDuration(value); err == nil {
+			return durationValue
+		}
+	}
+	return fallback
+}
+
+// getEnvAsPositiveDuration is getEnvAsDuration for settings that cannot be zero
+// or negative, such as ticker intervals and TTLs: those values fall back too.
+func getEnvAsPositiveDuration(key string, fallback time.Duration) time.Duration {
+	d := getEnvAsDuration(key, fallback)
+	if d <= 0 {
+		log.Printf("%s must be positive, got %s; using %s", key, d, fallback)
+		return fallback
+	}
+	return d
+}
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNotFound      = errors.New("not found")
+	ErrConflict      = errors.New("conflict")
+	ErrValidation    = errors.New("validation error")
+	ErrInvalidPeriod = errors.New("invalid period")
+	// ErrPreconditionFailed means the caller's version of a resource is stale.
+	ErrPreconditionFailed = errors.New("precondition failed")
+	// ErrUnprocessable means the request is well-formed but cannot be applied.
+	ErrUnprocessable = errors.New("unprocessable")
+	// ErrBatchAborted marks a batch operation that was not applied because
+	// another operation of the same all-or-nothing batch failed.
+	ErrBatchAborted = errors.New("not applied: another operation in the batch failed")
+)
+
+// Error is a domain error with a client-facing message. The kind is one of the
+// sentinel errors above, so callers match it with errors.Is.
+type Error struct {
+	kind error
+	msg  string
+}
+
+func (e *Error) Error() string {
+	return e.msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.kind
+}
+
+func NotFound(format string, args ...interface{}) error {
+	return &Error{kind: ErrNotFound, msg: fmt.Sprintf(format, args...)}
+}
+
+func Conflict(format string, args ...interface{}) error {
+	return &Error{kind: ErrConflict, msg: fmt.Sprintf(format, args...)}
+}
+
+func InvalidPeriod(format string, args ...interface{}) error {
+	return &Error{kind: ErrInvalidPeriod, msg: fmt.Sprintf(format, args...)}
+}
+
+func PreconditionFailed(format string, args ...interface{}) error {
+	return &Error{kind: ErrPreconditionFailed, msg: fmt.Sprintf(format, args...)}
+}
+
+func Unprocessable(format string, args ...interface{}) error {
+	return &Error{kind: ErrUnprocessable, msg: fmt.Sprintf(format, args...)}
+}
+
+// IsDomainError reports whether err carries a domain kind, as opposed to an
+// infrastructure failure.
+func IsDomainError(err error) bool {
+	var (
+		dErr *Error
+		vErr *ValidationError
+	)
+	return errors.As(err, &dErr) || errors.As(err, &vErr) || errors.Is(err, ErrBatchAborted)
+}
+
+// ValidationError reports which input field failed validation.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
+
+func Validation(field, format string, args ...interface{}) error {
+	return &ValidationError{Field: field, Message: fmt.Sprintf(format, args...)}
+}
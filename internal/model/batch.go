@@ -0,0 +1,15 @@
+package model
+
+const (
+	BatchCreate = "create"
+	BatchUpdate = "update"
+	BatchDelete = "delete"
+)
+
+// BatchOperation is one operation of a batch request. Updates and deletes
+// address the subscription by Subscription.Id; deletes only use its Id and
+// Version.
+type BatchOperation struct {
+	Op           string
+	Subscription Subscription
+}
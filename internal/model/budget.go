@@ -0,0 +1,44 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Budget caps what a user spends on subscriptions per month, counting their
+// share of shared subscriptions. With Category set it covers only the services
+// of that catalog category; a user has at most one budget per category and one
+// without. Limit is in Currency, which the monthly totals are converted to.
+type Budget struct {
+	UserId   uuid.UUID `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	Category string    `json:"category,omitempty" example:"video"`
+	Limit    int64     `json:"limit" example:"1500"`
+	Currency string    `json:"currency" example:"RUB"`
+}
+
+// BudgetMonth compares the spending of a month with the budget limit.
+// Overspend is the amount by which Total exceeds the limit, or zero.
+type BudgetMonth struct {
+	Month     time.Time `json:"month" example:"2024-01-01T00:00:00Z"`
+	Total     int64     `json:"total" example:"1797"`
+	Exceeded  bool      `json:"exceeded" example:"true"`
+	Overspend int64     `json:"overspend" example:"297"`
+}
+
+// BudgetEvaluation is a budget checked month by month over a period.
+type BudgetEvaluation struct {
+	Budget
+	Months         []BudgetMonth `json:"months"`
+	ExceededMonths int           `json:"exceeded_months" example:"1"`
+}
+
+// BudgetAlert reports a month in which a budget was exceeded. It is recorded
+// once per budget and month, with an EventBudgetExceeded audit event.
+type BudgetAlert struct {
+	UserId   uuid.UUID `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	Category string    `json:"category,omitempty" example:"video"`
+	Month    time.Time `json:"month" example:"2024-01-01T00:00:00Z"`
+	Limit    int64     `json:"limit" example:"1500"`
+	Total    int64     `json:"total" example:"1797"`
+	Currency string    `json:"currency" example:"RUB"`
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	CalendarCharge = "charge"
+	CalendarEnd    = "end"
+)
+
+// CalendarEvent is a dated occurrence of a subscription: a charge of Price on
+// Date, or the last day of the final paid month for CalendarEnd. Price is the
+// price in effect on Date less any promotion; for a shared subscription it is
+// the part paid by the user the calendar is built for.
+type CalendarEvent struct {
+	Kind           string
+	Date           time.Time
+	SubscriptionId uuid.UUID
+	UserId         uuid.UUID
+	ServiceName    string
+	Price          int64
+	Currency       string
+}
@@ -0,0 +1,20 @@
+package model
+
+import "strings"
+
+// CatalogService is a known service with its canonical Name. Subscriptions
+// naming the service or one of its Aliases are stored under Name. Aliases are
+// kept as ServiceKey values. DefaultPrice is the price of subscriptions to the
+// service created without one.
+type CatalogService struct {
+	Name         string   `json:"name" example:"Yandex Plus"`
+	Aliases      []string `json:"aliases,omitempty" example:"яндекс плюс"`
+	Category     string   `json:"category,omitempty" example:"video"`
+	DefaultPrice *int64   `json:"default_price,omitempty" example:"299"`
+}
+
+// ServiceKey normalizes a service name or alias for lookups: case is folded
+// and runs of whitespace collapse to a single space.
+func ServiceKey(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	EventCreate      = "create"
+	EventUpdate      = "update"
+	EventDelete      = "delete"
+	EventRestore     = "restore"
+	EventPriceChange = "price_change"
+	EventPause       = "pause"
+	EventResume      = "resume"
+	EventPromotion   = "promotion"
+	// EventBudgetExceeded is not tied to a subscription: it has no
+	// SubscriptionId and ServiceName, and After holds the model.BudgetAlert.
+	EventBudgetExceeded = "budget_exceeded"
+)
+
+// SubscriptionEvent is an audit log entry. Before and After hold JSON snapshots
+// of the subscription around the change; for price changes After holds the
+// scheduled model.PriceChange, for pauses and resumes the model.Pause and for
+// promotions the model.AppliedPromotion. SubscriptionId is empty for budget
+// events and for events of subscriptions purged before subscriptions had ids.
+type SubscriptionEvent struct {
+	Id             int64           `json:"id" example:"42"`
+	SubscriptionId *uuid.UUID      `json:"subscription_id,omitempty" example:"0b6f1a3e-8c2d-4e5f-9a7b-1c2d3e4f5a6b"`
+	UserId         uuid.UUID       `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	ServiceName    string          `json:"service_name" example:"Yandex Plus"`
+	Action         string          `json:"action" example:"update"`
+	Before         json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+	After          json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+	RequestId      string          `json:"request_id,omitempty" example:"host/abcdef-000001"`
+	Actor          string          `json:"actor,omitempty" example:"admin@example.com"`
+	CreatedAt      time.Time       `json:"created_at" example:"2024-01-01T12:00:00Z"`
+}
+
+type EventFilter struct {
+	SubscriptionId *uuid.UUID
+	UserId         *uuid.UUID
+	ServiceName    *string
+	From           *time.Time
+	To             *time.Time
+	BeforeId       *int64
+	Limit          int
+}
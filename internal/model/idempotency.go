@@ -0,0 +1,22 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyKey is the stored outcome of a request made with an
+// Idempotency-Key header. Keys are scoped to the user the subscription is
+// created for. RequestHash identifies the payload, so a key reused with a
+// different payload can be told apart from a retry.
+type IdempotencyKey struct {
+	UserId         uuid.UUID
+	Key            string
+	RequestHash    string
+	ResponseStatus int
+	ResponseBody   json.RawMessage
+	CreatedAt      time.Time
+	ExpiresAt      time.Time
+}
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	SortByServiceName = "service_name"
+	SortByPrice       = "price"
+	SortByStartDate   = "start_date"
+)
+
+// ListFilter selects a page of subscriptions. A nil UserId lists across all
+// users; otherwise the user's own subscriptions and those shared with them.
+// Soft-deleted subscriptions are skipped unless IncludeDeleted is set.
+type ListFilter struct {
+	UserId            *uuid.UUID
+	ServiceName       *string
+	ServiceNamePrefix *string
+	Tag               *string
+	ActiveAt          *time.Time
+	MinPrice          *int64
+	MaxPrice          *int64
+	IncludeDeleted    bool
+	SortBy            string
+	Desc              bool
+	Limit             int
+	After             *Cursor
+}
+
+type SubscriptionPage struct {
+	Items      []Subscription `json:"items"`
+	NextCursor string         `json:"next_cursor,omitempty" example:"eyJzb3J0IjoicHJpY2UiLCJ..."`
+}
+
+// Cursor is the keyset position of the last row of a page. Rows are ordered by
+// the sort column and then by (user_id, service_name, id), so the tuple is
+// unique.
+type Cursor struct {
+	SortBy      string    `json:"sort"`
+	Desc        bool      `json:"desc,omitempty"`
+	UserId      uuid.UUID `json:"user_id"`
+	ServiceName string    `json:"service_name"`
+	Id          uuid.UUID `json:"id"`
+	Price       int64     `json:"price"`
+	StartDate   time.Time `json:"start_date"`
+}
+
+func CursorFor(sub Subscription, sortBy string, desc bool) Cursor {
+	return Cursor{
+		SortBy:      sortBy,
+		Desc:        desc,
+		UserId:      sub.UserId,
+		ServiceName: sub.ServiceName,
+		Id:          sub.Id,
+		Price:       sub.Price,
+		StartDate:   sub.StartDate,
+	}
+}
+
+func (c Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func DecodeCursor(s string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
@@ -0,0 +1,101 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"math"
+)
+
+const (
+	SplitEqual   = "equal"
+	SplitPercent = "percent"
+	SplitFixed   = "fixed"
+)
+
+// MaxMembers is the number of users a subscription may be shared with.
+const MaxMembers = 10
+
+// Member is a user a subscription is shared with. Share is a percentage of
+// every charge under the percent split and an amount out of the cycle price
+// under the fixed split; the equal split does not use it.
+type Member struct {
+	UserId uuid.UUID `json:"user_id" example:"8b0d3c5e-2f4a-4b6c-9d8e-7f6a5b4c3d2e"`
+	Share  int64     `json:"share,omitempty" example:"25"`
+}
+
+// Payer is a user paying Fraction of every charge of a subscription.
+type Payer struct {
+	UserId   uuid.UUID
+	Fraction float64
+}
+
+func ValidSplit(s string) bool {
+	switch s {
+	case SplitEqual, SplitPercent, SplitFixed:
+		return true
+	default:
+		return false
+	}
+}
+
+// HasMember reports whether the subscription is shared with userId.
+func (s Subscription) HasMember(userId uuid.UUID) bool {
+	for _, m := range s.Members {
+		if m.UserId == userId {
+			return true
+		}
+	}
+	return false
+}
+
+// Payers divides the charges of the subscription between the owner, listed
+// first, and the members. The owner pays what the members' shares leave. Fixed
+// shares are taken out of price, the cycle price in effect; should they exceed
+// it after a price change, they are scaled down to cover the whole charge.
+// It mirrors the shares join of the postgres monthlyChargesQuery.
+func (s Subscription) Payers(price int64) []Payer {
+	payers := make([]Payer, 0, len(s.Members)+1)
+	payers = append(payers, Payer{UserId: s.UserId, Fraction: 1})
+	if len(s.Members) == 0 {
+		return payers
+	}
+
+	var shares int64
+	for _, m := range s.Members {
+		shares += m.Share
+	}
+	var whole float64
+	switch s.Split {
+	case SplitPercent:
+		whole = 100
+	case SplitFixed:
+		whole = float64(max(price, shares, 1))
+	}
+
+	for _, m := range s.Members {
+		fraction := 1 / float64(len(s.Members)+1)
+		if whole > 0 {
+			fraction = float64(m.Share) / whole
+		}
+		payers = append(payers, Payer{UserId: m.UserId, Fraction: fraction})
+	}
+	if whole > 0 {
+		payers[0].Fraction = 1 - float64(shares)/whole
+	} else {
+		payers[0].Fraction = 1 / float64(len(s.Members)+1)
+	}
+	return payers
+}
+
+// SplitAmount divides amount between payers, as returned by Payers, in whole
+// units. Members pay their share rounded and the owner, listed first, the rest
+// of the rounded amount, so the parts always add up to it.
+func SplitAmount(amount float64, payers []Payer) []int64 {
+	amounts := make([]int64, len(payers))
+	rest := int64(math.Round(amount))
+	for i := 1; i < len(payers); i++ {
+		amounts[i] = int64(math.Round(amount * payers[i].Fraction))
+		rest -= amounts[i]
+	}
+	amounts[0] = rest
+	return amounts
+}
@@ -2,13 +2,122 @@ package model
 
 import (
 	"github.com/google/uuid"
+	"regexp"
 	"time"
 )
 
+// DefaultCurrency is assumed for subscriptions created without a currency.
+const DefaultCurrency = "RUB"
+
+const (
+	BillingWeek    = "week"
+	BillingMonth   = "month"
+	BillingQuarter = "quarter"
+	BillingYear    = "year"
+)
+
+// Subscription is identified by Id; a user may hold several subscriptions to
+// the same service.
 type Subscription struct {
-	ServiceName string     `json:"service_name" db:"service_name" example:"Yandex Plus"`
-	Price       int64      `json:"price" db:"price" example:"299"`
-	UserId      uuid.UUID  `json:"user_id" db:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
-	StartDate   time.Time  `json:"start_date" db:"start_date" example:"2023-10-01T00:00:00Z"`
-	EndDate     *time.Time `json:"end_date,omitempty" db:"end_date" example:"2024-10-01T00:00:00Z"`
+	Id          uuid.UUID `json:"id" db:"id" example:"0b6f1a3e-8c2d-4e5f-9a7b-1c2d3e4f5a6b"`
+	ServiceName string    `json:"service_name" db:"service_name" example:"Yandex Plus"`
+	// Price is the price last set on the subscription. Months are charged the
+	// price in effect for them, see PriceChange.
+	Price           int64      `json:"price" db:"price" example:"299"`
+	UserId          uuid.UUID  `json:"user_id" db:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	StartDate       time.Time  `json:"start_date" db:"start_date" example:"2023-10-01T00:00:00Z"`
+	EndDate         *time.Time `json:"end_date,omitempty" db:"end_date" example:"2024-10-01T00:00:00Z"`
+	BillingPeriod   string     `json:"billing_period" db:"billing_period" example:"month"`
+	BillingInterval int        `json:"billing_interval" db:"billing_interval" example:"1"`
+	Currency        string     `json:"currency" db:"currency" example:"RUB"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty" db:"deleted_at" example:"2024-11-05T10:00:00Z"`
+	// TrialEndDate is the last free month; months from the start up to and
+	// including it are not charged.
+	TrialEndDate *time.Time `json:"trial_end_date,omitempty" db:"trial_end_date" example:"2023-11-01T00:00:00Z"`
+	// Tags are normalized free-form labels kept sorted, see NormalizeTag.
+	Tags []string `json:"tags,omitempty" db:"tags" example:"video,family"`
+	// Members share the subscription with its owner, UserId; Split tells how
+	// the charges are divided between them, see Payers. Both are empty for a
+	// subscription that is not shared.
+	Split   string   `json:"split,omitempty" db:"split" example:"equal" enums:"equal,percent,fixed"`
+	Members []Member `json:"members,omitempty" db:"members"`
+	// Status is computed on read and not stored; see StatusAt.
+	Status string `json:"status,omitempty" db:"-" example:"active" enums:"scheduled,active,paused,ended"`
+	// Version is incremented on every change. On update it carries the version
+	// the caller expects to replace; zero skips the check.
+	Version int64 `json:"version" db:"version" example:"3"`
+}
+
+// NormalizeDates truncates the dates to the first day of their month. Weekly
+// plans keep their exact start date, since it defines the charge weekday.
+func (s Subscription) NormalizeDates() Subscription {
+	if s.BillingPeriod != BillingWeek {
+		s.StartDate = FirstOfMonth(s.StartDate)
+	}
+	if s.EndDate != nil {
+		end := FirstOfMonth(*s.EndDate)
+		s.EndDate = &end
+	}
+	if s.TrialEndDate != nil {
+		trialEnd := FirstOfMonth(*s.TrialEndDate)
+		s.TrialEndDate = &trialEnd
+	}
+	return s
+}
+
+// CycleMonths is the length of a billing cycle in months. It is meaningless for
+// weekly plans, whose cycle is CycleDays.
+func (s Subscription) CycleMonths() int {
+	switch s.BillingPeriod {
+	case BillingQuarter:
+		return 3 * s.BillingInterval
+	case BillingYear:
+		return 12 * s.BillingInterval
+	default:
+		return s.BillingInterval
+	}
+}
+
+func (s Subscription) CycleDays() int {
+	return 7 * s.BillingInterval
+}
+
+// CycleStart returns the renewal month of the billing cycle that the month
+// starting at m belongs to, the month whose charge pays for m. Weekly plans
+// renew within every month, so for them it is m itself.
+func (s Subscription) CycleStart(m time.Time) time.Time {
+	m = FirstOfMonth(m)
+	if s.BillingPeriod == BillingWeek {
+		return m
+	}
+	start := FirstOfMonth(s.StartDate)
+	months := (m.Year()-start.Year())*12 + int(m.Month()-start.Month())
+	return start.AddDate(0, months-months%s.CycleMonths(), 0)
+}
+
+// InTrial reports whether the month starting at m is a free trial month.
+func (s Subscription) InTrial(m time.Time) bool {
+	return s.TrialEndDate != nil && !FirstOfMonth(m).After(FirstOfMonth(*s.TrialEndDate))
+}
+
+func ValidBillingPeriod(p string) bool {
+	switch p {
+	case BillingWeek, BillingMonth, BillingQuarter, BillingYear:
+		return true
+	default:
+		return false
+	}
+}
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// ValidCurrency reports whether c looks like an ISO 4217 code.
+func ValidCurrency(c string) bool {
+	return currencyCode.MatchString(c)
+}
+
+// FirstOfMonth truncates t to the first day of its month, the granularity at
+// which subscriptions are billed.
+func FirstOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
 }
@@ -0,0 +1,48 @@
+package model
+
+import "time"
+
+const (
+	StatusScheduled = "scheduled"
+	StatusActive    = "active"
+	StatusPaused    = "paused"
+	StatusEnded     = "ended"
+)
+
+// Pause suspends a subscription from the month of From until the month of
+// ResumeFrom, which is charged again. An open pause has no ResumeFrom.
+type Pause struct {
+	From       time.Time  `json:"from" example:"2024-06-01T00:00:00Z"`
+	ResumeFrom *time.Time `json:"resume_from,omitempty" example:"2024-09-01T00:00:00Z"`
+}
+
+// Covers reports whether the month starting at m is paused.
+func (p Pause) Covers(m time.Time) bool {
+	m = FirstOfMonth(m)
+	return !m.Before(p.From) && (p.ResumeFrom == nil || m.Before(*p.ResumeFrom))
+}
+
+// Paused reports whether any of pauses covers the month starting at m.
+func Paused(pauses []Pause, m time.Time) bool {
+	for _, p := range pauses {
+		if p.Covers(m) {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusAt returns the status of sub in the month of t.
+func (s Subscription) StatusAt(t time.Time, pauses []Pause) string {
+	m := FirstOfMonth(t)
+	switch {
+	case m.Before(FirstOfMonth(s.StartDate)):
+		return StatusScheduled
+	case s.EndDate != nil && m.After(FirstOfMonth(*s.EndDate)):
+		return StatusEnded
+	case Paused(pauses, m):
+		return StatusPaused
+	default:
+		return StatusActive
+	}
+}
@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+// PriceChange sets the subscription price from EffectiveFrom (the first day of a
+// month) onwards. Months before the first change are charged the base price.
+type PriceChange struct {
+	EffectiveFrom time.Time `json:"effective_from" example:"2024-03-01T00:00:00Z"`
+	Price         int64     `json:"price" example:"399"`
+}
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"regexp"
+	"time"
+)
+
+const (
+	PromotionPercent = "percent"
+	PromotionFixed   = "fixed"
+)
+
+// Promotion is a discount for DurationMonths months from the month it is
+// applied: Value percent off, or Value off the price of every billing cycle
+// charged in those months, in the subscription's currency. An empty
+// ServiceNames applies to any service.
+type Promotion struct {
+	Code           string    `json:"code" example:"SUMMER25"`
+	Kind           string    `json:"kind" example:"percent" enums:"percent,fixed"`
+	Value          int64     `json:"value" example:"25"`
+	DurationMonths int       `json:"duration_months" example:"3"`
+	ServiceNames   []string  `json:"service_names,omitempty" example:"Yandex Plus"`
+	CreatedAt      time.Time `json:"created_at" example:"2024-05-20T10:00:00Z"`
+}
+
+// AppliesTo reports whether the promotion may be attached to serviceName.
+// Names are compared by ServiceKey.
+func (p Promotion) AppliesTo(serviceName string) bool {
+	if len(p.ServiceNames) == 0 {
+		return true
+	}
+	key := ServiceKey(serviceName)
+	for _, name := range p.ServiceNames {
+		if ServiceKey(name) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Discount returns the price of a billing cycle with the promotion applied; it
+// never goes negative.
+func (p Promotion) Discount(amount float64) float64 {
+	if p.Kind == PromotionPercent {
+		return amount * float64(100-p.Value) / 100
+	}
+	if amount <= float64(p.Value) {
+		return 0
+	}
+	return amount - float64(p.Value)
+}
+
+// AppliedPromotion attaches a promotion to a subscription for the months from
+// AppliedFrom up to, but not including, AppliedUntil: the duration of the
+// promotion, or less if it was removed early. The promotions applied to a
+// subscription do not overlap and are kept after they end.
+type AppliedPromotion struct {
+	Code         string    `json:"code" example:"SUMMER25"`
+	AppliedFrom  time.Time `json:"applied_from" example:"2024-06-01T00:00:00Z"`
+	AppliedUntil time.Time `json:"applied_until" example:"2024-09-01T00:00:00Z"`
+}
+
+// Covers reports whether the month starting at m is discounted.
+func (a AppliedPromotion) Covers(m time.Time) bool {
+	m = FirstOfMonth(m)
+	return !m.Before(a.AppliedFrom) && m.Before(a.AppliedUntil)
+}
+
+// Overlaps reports whether a and b discount a common month.
+func (a AppliedPromotion) Overlaps(b AppliedPromotion) bool {
+	return a.AppliedFrom.Before(b.AppliedUntil) && b.AppliedFrom.Before(a.AppliedUntil)
+}
+
+var promoCode = regexp.MustCompile(`^[A-Z0-9_-]{1,64}$`)
+
+// ValidPromoCode reports whether c is an upper-case promo code of up to 64
+// letters, digits, dashes and underscores.
+func ValidPromoCode(c string) bool {
+	return promoCode.MatchString(c)
+}
@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// ExchangeRate converts FromCurrency amounts to ToCurrency from ValidFrom until
+// the next rate for the same pair.
+type ExchangeRate struct {
+	FromCurrency string    `json:"from_currency" example:"USD"`
+	ToCurrency   string    `json:"to_currency" example:"RUB"`
+	ValidFrom    time.Time `json:"valid_from" example:"2024-01-01T00:00:00Z"`
+	Rate         float64   `json:"rate" example:"89.6883"`
+}
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	GroupByMonth       = "month"
+	GroupByServiceName = "service_name"
+	GroupByUserId      = "user_id"
+	GroupByTag         = "tag"
+)
+
+// SummaryFilter selects the subscriptions and months counted by a summary.
+// Any combination of the GroupBy flags turns the total into a breakdown.
+// Grouping by tag counts a subscription once under each of its tags and once
+// under no tag when it has none, so such rows may add up to more than the total.
+// Plans billed less often than monthly are charged in their renewal months,
+// or spread evenly across the cycle when Amortize is set. With TargetCurrency
+// set every month's amount is converted at the exchange rate valid for it;
+// otherwise all the subscriptions counted must share one currency. With UserId or GroupByUser set, shared
+// subscriptions count for each of their payers with that payer's share only,
+// see Subscription.Payers; otherwise they count at the full price. Category
+// selects the subscriptions to services of that catalog category.
+type SummaryFilter struct {
+	From           time.Time
+	To             time.Time
+	UserId         *uuid.UUID
+	ServiceName    *string
+	Tag            *string
+	Category       *string
+	Amortize       bool
+	TargetCurrency *string
+	GroupByMonth   bool
+	GroupByService bool
+	GroupByUser    bool
+	GroupByTag     bool
+}
+
+// SplitShares reports whether charges are divided between the payers of shared
+// subscriptions.
+func (f SummaryFilter) SplitShares() bool {
+	return f.UserId != nil || f.GroupByUser
+}
+
+func (f SummaryFilter) Grouped() bool {
+	return f.GroupByMonth || f.GroupByService || f.GroupByUser || f.GroupByTag
+}
+
+// SummaryRow is one group of a summary breakdown. Fields that are not part of
+// the grouping are left empty, as is Tag in the group of untagged subscriptions.
+// Grouped by month, a month without charges has a row of its own with zero
+// totals and only Month set.
+type SummaryRow struct {
+	Month       *time.Time `json:"month,omitempty" example:"2024-01-01T00:00:00Z"`
+	ServiceName *string    `json:"service_name,omitempty" example:"Yandex Plus"`
+	UserId      *uuid.UUID `json:"user_id,omitempty" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	Tag         *string    `json:"tag,omitempty" example:"video"`
+	Total       int64      `json:"total" example:"1497"`
+	ActiveCount int        `json:"active_count" example:"3"`
+	// TrialMonths counts the free trial months in the group; they add nothing
+	// to Total.
+	TrialMonths int `json:"trial_months" example:"1"`
+}
+
+type Summary struct {
+	TotalPrice int          `json:"total_price" example:"1497"`
+	Currency   string       `json:"currency,omitempty" example:"RUB"`
+	Breakdown  []SummaryRow `json:"breakdown,omitempty"`
+}
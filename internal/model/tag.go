@@ -0,0 +1,49 @@
+package model
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// MaxTags is the number of tags a subscription may carry.
+const MaxTags = 20
+
+var tagPattern = regexp.MustCompile(`^[\p{Ll}\p{N}_-]{1,32}$`)
+
+// NormalizeTag folds the case of a tag and trims surrounding whitespace.
+func NormalizeTag(t string) string {
+	return strings.ToLower(strings.TrimSpace(t))
+}
+
+// ValidTag reports whether t is a normalized tag of up to 32 lower-case
+// letters, digits, dashes and underscores.
+func ValidTag(t string) bool {
+	return tagPattern.MatchString(t)
+}
+
+// SortedTags returns tags sorted and without duplicates, or nil if empty.
+func SortedTags(tags []string) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+	sorted := append([]string(nil), tags...)
+	sort.Strings(sorted)
+	unique := sorted[:1]
+	for _, t := range sorted[1:] {
+		if t != unique[len(unique)-1] {
+			unique = append(unique, t)
+		}
+	}
+	return unique
+}
+
+// HasTag reports whether the subscription is tagged with t.
+func (s Subscription) HasTag(t string) bool {
+	for _, tag := range s.Tags {
+		if tag == t {
+			return true
+		}
+	}
+	return false
+}
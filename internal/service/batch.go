@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	apimw "subservice/internal/api/middleware"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"subservice/internal/storage"
+)
+
+// MaxBatchSize bounds the number of operations in one batch request.
+const MaxBatchSize = 1000
+
+// ApplyBatch applies the operations and returns an error per operation, nil
+// for the applied ones. In atomic mode either all operations are applied or
+// none; otherwise each operation is applied on its own.
+func (ss *SubscriptionService) ApplyBatch(ctx context.Context, ops []model.BatchOperation, atomic bool) ([]error, error) {
+	l := apimw.FromContext(ctx)
+
+	if len(ops) == 0 || len(ops) > MaxBatchSize {
+		return nil, domain.Validation("operations", "batch must contain 1 to %d operations", MaxBatchSize)
+	}
+
+	errs := make([]error, len(ops))
+	for i, op := range ops {
+		errs[i] = validateBatchOperation(op)
+	}
+	if err := ss.resolveBatchServiceNames(ctx, ops, errs); err != nil {
+		return nil, err
+	}
+	if err := ss.resolveBatchIds(ctx, ops, errs); err != nil {
+		return nil, err
+	}
+
+	l.Info("Applying batch", zap.Int("operations", len(ops)), zap.Bool("atomic", atomic))
+	if atomic {
+		for i, err := range errs {
+			if err != nil {
+				storage.AbortBatch(errs, i, err)
+				return errs, nil
+			}
+		}
+		return ss.Repo.ApplyBatch(ctx, ops)
+	}
+
+	for i, op := range ops {
+		if errs[i] != nil {
+			continue
+		}
+		switch op.Op {
+		case model.BatchCreate:
+			errs[i] = ss.Repo.Insert(ctx, op.Subscription)
+		case model.BatchUpdate:
+			errs[i] = ss.Repo.Update(ctx, op.Subscription)
+		case model.BatchDelete:
+			errs[i] = ss.Repo.Delete(ctx, op.Subscription.Id, op.Subscription.Version)
+		}
+	}
+	return errs, nil
+}
+
+// resolveBatchServiceNames resolves the service names of the valid create and
+// update operations, recording rejected names in errs, and gives created
+// subscriptions without a price the catalog default.
+func (ss *SubscriptionService) resolveBatchServiceNames(ctx context.Context, ops []model.BatchOperation, errs []error) error {
+	var (
+		subs    []model.Subscription
+		indexes []int
+	)
+	for i, op := range ops {
+		if errs[i] == nil && (op.Op == model.BatchCreate || op.Op == model.BatchUpdate) {
+			subs = append(subs, op.Subscription)
+			indexes = append(indexes, i)
+		}
+	}
+	if len(subs) == 0 {
+		return nil
+	}
+
+	resolveErrs, err := ss.resolveServiceNames(ctx, subs)
+	if err != nil {
+		return err
+	}
+	for j, i := range indexes {
+		ops[i].Subscription = subs[j]
+		errs[i] = resolveErrs[j]
+	}
+
+	for i, op := range ops {
+		if errs[i] == nil && op.Op == model.BatchCreate {
+			if err := ss.applyDefaultPrice(ctx, &ops[i].Subscription); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resolveBatchIds assigns ids to the valid create operations that have none and
+// looks up the subscriptions of update and delete operations given by
+// (user_id, service_name) only. Failed lookups are recorded in errs; storage
+// errors other than domain ones are returned.
+func (ss *SubscriptionService) resolveBatchIds(ctx context.Context, ops []model.BatchOperation, errs []error) error {
+	for i, op := range ops {
+		if errs[i] != nil || op.Subscription.Id != uuid.Nil {
+			continue
+		}
+		if op.Op == model.BatchCreate {
+			ops[i].Subscription.Id = uuid.New()
+			continue
+		}
+		id, err := ss.ResolveSubscriptionId(ctx, op.Subscription.UserId, op.Subscription.ServiceName, false)
+		if err != nil && !domain.IsDomainError(err) {
+			return err
+		}
+		ops[i].Subscription.Id = id
+		errs[i] = err
+	}
+	return nil
+}
+
+func validateBatchOperation(op model.BatchOperation) error {
+	switch op.Op {
+	case model.BatchCreate, model.BatchUpdate:
+		return validatePeriod(op.Subscription)
+	case model.BatchDelete:
+		return nil
+	default:
+		return domain.Validation("op", "op must be one of create, update, delete")
+	}
+}
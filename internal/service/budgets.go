@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"strings"
+	apimw "subservice/internal/api/middleware"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"time"
+)
+
+// SaveBudget creates or replaces the user's budget for its category, which must
+// be the category of a catalog service.
+func (ss *SubscriptionService) SaveBudget(ctx context.Context, budget model.Budget) (*model.Budget, error) {
+	l := apimw.FromContext(ctx).With(zap.String("user_id", budget.UserId.String()))
+	if budget.Limit < 0 {
+		return nil, domain.Validation("limit", "limit cannot be negative")
+	}
+	if budget.Currency == "" {
+		budget.Currency = model.DefaultCurrency
+	}
+	budget.Category = strings.TrimSpace(budget.Category)
+	if budget.Category != "" {
+		services, err := ss.Repo.ListCatalogServices(ctx, &budget.Category)
+		if err != nil {
+			return nil, err
+		}
+		if len(services) == 0 {
+			return nil, domain.Validation("category", "no catalog service has category %q", budget.Category)
+		}
+	}
+
+	l.Info("Saving budget", zap.String("category", budget.Category), zap.Int64("limit", budget.Limit), zap.String("currency", budget.Currency))
+	if err := ss.Repo.SaveBudget(ctx, budget); err != nil {
+		return nil, err
+	}
+	return &budget, nil
+}
+
+func (ss *SubscriptionService) ListBudgets(ctx context.Context, userId uuid.UUID) ([]model.Budget, error) {
+	return ss.Repo.ListBudgets(ctx, userId)
+}
+
+func (ss *SubscriptionService) DeleteBudget(ctx context.Context, userId uuid.UUID, category string) error {
+	l := apimw.FromContext(ctx).With(zap.String("user_id", userId.String()), zap.String("category", category))
+	l.Info("Deleting budget")
+	return ss.Repo.DeleteBudget(ctx, userId, category)
+}
+
+// EvaluateBudgets compares the monthly summary of the user's spending with each
+// of their budgets, or only the one for category, for every month from from to
+// to. Totals count the user's share of shared subscriptions and are converted
+// to the budget currency. Nothing is recorded, see RecordBudgetAlerts.
+func (ss *SubscriptionService) EvaluateBudgets(ctx context.Context, userId uuid.UUID, from, to time.Time, category *string) ([]model.BudgetEvaluation, error) {
+	evaluations, _, err := ss.evaluateBudgets(ctx, userId, from, to, category)
+	return evaluations, err
+}
+
+// RecordBudgetAlerts evaluates the budgets like EvaluateBudgets and records the
+// exceeded months that have already begun as alerts, each once, which appends
+// a budget_exceeded audit event.
+func (ss *SubscriptionService) RecordBudgetAlerts(ctx context.Context, userId uuid.UUID, from, to time.Time, category *string) ([]model.BudgetEvaluation, error) {
+	l := apimw.FromContext(ctx).With(zap.String("user_id", userId.String()))
+	evaluations, alerts, err := ss.evaluateBudgets(ctx, userId, from, to, category)
+	if err != nil || len(alerts) == 0 {
+		return evaluations, err
+	}
+
+	recorded, err := ss.Repo.RecordBudgetAlerts(ctx, alerts)
+	if err != nil {
+		return nil, err
+	}
+	for _, alert := range recorded {
+		l.Warn("Budget exceeded", zap.String("category", alert.Category), zap.Time("month", alert.Month),
+			zap.Int64("limit", alert.Limit), zap.Int64("total", alert.Total), zap.String("currency", alert.Currency))
+	}
+	return evaluations, nil
+}
+
+// evaluateBudgets returns the evaluations of EvaluateBudgets along with an
+// alert for every exceeded month that has already begun.
+func (ss *SubscriptionService) evaluateBudgets(ctx context.Context, userId uuid.UUID, from, to time.Time, category *string) ([]model.BudgetEvaluation, []model.BudgetAlert, error) {
+	l := apimw.FromContext(ctx).With(zap.String("user_id", userId.String()))
+	from, to = model.FirstOfMonth(from), model.FirstOfMonth(to)
+	if from.After(to) {
+		return nil, nil, domain.InvalidPeriod("from date cannot be after to date")
+	}
+
+	budgets, err := ss.Repo.ListBudgets(ctx, userId)
+	if err != nil {
+		return nil, nil, err
+	}
+	if category != nil {
+		var selected []model.Budget
+		for _, b := range budgets {
+			if b.Category == *category {
+				selected = append(selected, b)
+			}
+		}
+		if len(selected) == 0 {
+			return nil, nil, domain.NotFound("budget not found")
+		}
+		budgets = selected
+	}
+	l.Info("Evaluating budgets", zap.Int("budgets", len(budgets)), zap.Time("from", from), zap.Time("to", to))
+
+	currentMonth := model.FirstOfMonth(time.Now().UTC())
+	evaluations := make([]model.BudgetEvaluation, 0, len(budgets))
+	var alerts []model.BudgetAlert
+	for _, budget := range budgets {
+		totals, err := ss.monthlyTotals(ctx, budget, from, to)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		eval := model.BudgetEvaluation{Budget: budget, Months: []model.BudgetMonth{}}
+		for m := from; !m.After(to); m = m.AddDate(0, 1, 0) {
+			month := model.BudgetMonth{Month: m, Total: totals[m]}
+			if month.Total > budget.Limit {
+				month.Exceeded = true
+				month.Overspend = month.Total - budget.Limit
+				eval.ExceededMonths++
+				if !m.After(currentMonth) {
+					alerts = append(alerts, model.BudgetAlert{
+						UserId:   userId,
+						Category: budget.Category,
+						Month:    m,
+						Limit:    budget.Limit,
+						Total:    month.Total,
+						Currency: budget.Currency,
+					})
+				}
+			}
+			eval.Months = append(eval.Months, month)
+		}
+		evaluations = append(evaluations, eval)
+	}
+
+	return evaluations, alerts, nil
+}
+
+// monthlyTotals returns the user's spending per month counted by budget, keyed
+// by the first day of the month.
+func (ss *SubscriptionService) monthlyTotals(ctx context.Context, budget model.Budget, from, to time.Time) (map[time.Time]int64, error) {
+	userId, currency := budget.UserId, budget.Currency
+	filter := model.SummaryFilter{
+		From:           from,
+		To:             to,
+		UserId:         &userId,
+		TargetCurrency: &currency,
+		GroupByMonth:   true,
+	}
+	if budget.Category != "" {
+		category := budget.Category
+		filter.Category = &category
+	}
+
+	breakdown, err := ss.Repo.GetSummaryBreakdown(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	totals := make(map[time.Time]int64, len(breakdown))
+	for _, row := range breakdown {
+		totals[model.FirstOfMonth(*row.Month)] += row.Total
+	}
+	return totals, nil
+}
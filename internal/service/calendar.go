@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"math"
+	"sort"
+	apimw "subservice/internal/api/middleware"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"time"
+)
+
+// MaxCalendarWindow bounds the period a calendar feed may cover.
+const MaxCalendarWindow = 5 * 366 * 24 * time.Hour
+
+// GetCalendar returns the charges and end dates of the user's subscriptions
+// falling within [from, to), ordered by date, including those shared with the
+// user. Charges are the user's part of the price in effect on their date,
+// discounted like the summary.
+func (ss *SubscriptionService) GetCalendar(ctx context.Context, userId uuid.UUID, from, to time.Time) ([]model.CalendarEvent, error) {
+	l := apimw.FromContext(ctx).With(zap.String("user_id", userId.String()))
+	if !from.Before(to) {
+		return nil, domain.InvalidPeriod("from date must be before to date")
+	}
+	if to.Sub(from) > MaxCalendarWindow {
+		return nil, domain.Validation("to", "calendar period cannot exceed 5 years")
+	}
+	l.Info("Building calendar", zap.Time("from", from), zap.Time("to", to))
+
+	var subs []model.Subscription
+	filter := model.ListFilter{UserId: &userId, SortBy: model.SortByServiceName}
+	if err := ss.Repo.Export(ctx, filter, func(sub model.Subscription) error {
+		subs = append(subs, sub)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	promos := make(map[string]model.Promotion)
+	var events []model.CalendarEvent
+	for _, sub := range subs {
+		prices, err := ss.Repo.GetPriceHistory(ctx, sub.Id)
+		if err != nil {
+			return nil, err
+		}
+		pauses, err := ss.Repo.GetPauses(ctx, sub.Id)
+		if err != nil {
+			return nil, err
+		}
+		discounts, err := ss.discounts(ctx, sub.Id, promos)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, calendarEvents(sub, userId, prices, pauses, discounts, from, to)...)
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Date.Before(events[j].Date)
+	})
+	return events, nil
+}
+
+// discount is a promotion applied to a subscription.
+type discount struct {
+	applied model.AppliedPromotion
+	promo   model.Promotion
+}
+
+// discounts returns the promotions applied to subscription id, looking their
+// terms up in promos first.
+func (ss *SubscriptionService) discounts(ctx context.Context, id uuid.UUID, promos map[string]model.Promotion) ([]discount, error) {
+	applied, err := ss.Repo.GetAppliedPromotions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	discounts := make([]discount, 0, len(applied))
+	for _, a := range applied {
+		promo, ok := promos[a.Code]
+		if !ok {
+			p, err := ss.Repo.GetPromotion(ctx, a.Code)
+			if err != nil {
+				return nil, err
+			}
+			promo = *p
+			promos[a.Code] = promo
+		}
+		discounts = append(discounts, discount{applied: a, promo: promo})
+	}
+	return discounts, nil
+}
+
+// calendarEvents lists the charges of sub to userId the way the summary bills
+// them: every cycle from the start date up to and including the end date's
+// month, except those in trial and paused months, less the promotion covering
+// the cycle. A payer of a shared subscription is charged their part, see
+// model.SplitAmount.
+func calendarEvents(sub model.Subscription, userId uuid.UUID, prices []model.PriceChange, pauses []model.Pause, discounts []discount, from, to time.Time) []model.CalendarEvent {
+	var until time.Time // exclusive; zero means open-ended
+	if sub.EndDate != nil {
+		until = model.FirstOfMonth(*sub.EndDate).AddDate(0, 1, 0)
+	}
+
+	event := func(kind string, date time.Time) model.CalendarEvent {
+		price := priceOn(sub.Price, prices, date)
+		return model.CalendarEvent{
+			Kind:           kind,
+			Date:           date,
+			SubscriptionId: sub.Id,
+			UserId:         sub.UserId,
+			ServiceName:    sub.ServiceName,
+			Price:          payerAmount(sub, userId, price, discountOn(sub, discounts, date, price)),
+			Currency:       sub.Currency,
+		}
+	}
+
+	var events []model.CalendarEvent
+	for i := 0; ; i++ {
+		var date time.Time
+		if sub.BillingPeriod == model.BillingWeek {
+			date = sub.StartDate.AddDate(0, 0, i*sub.CycleDays())
+		} else {
+			date = sub.StartDate.AddDate(0, i*sub.CycleMonths(), 0)
+		}
+		if !date.Before(to) || (!until.IsZero() && !date.Before(until)) {
+			break
+		}
+		if !date.Before(from) && !sub.InTrial(date) && !model.Paused(pauses, date) {
+			events = append(events, event(model.CalendarCharge, date))
+		}
+	}
+
+	if !until.IsZero() {
+		last := until.AddDate(0, 0, -1)
+		if !last.Before(from) && last.Before(to) {
+			events = append(events, event(model.CalendarEnd, last))
+		}
+	}
+	return events
+}
+
+// priceOn returns the latest price change effective on date, or the base price.
+// prices must be ordered by EffectiveFrom.
+func priceOn(base int64, prices []model.PriceChange, date time.Time) int64 {
+	price := base
+	for _, c := range prices {
+		if c.EffectiveFrom.After(date) {
+			break
+		}
+		price = c.Price
+	}
+	return price
+}
+
+// discountOn returns price less the promotion covering the billing cycle of
+// date, like the summary does.
+func discountOn(sub model.Subscription, discounts []discount, date time.Time, price int64) float64 {
+	cycle := sub.CycleStart(date)
+	for _, d := range discounts {
+		if d.applied.Covers(cycle) {
+			return d.promo.Discount(float64(price))
+		}
+	}
+	return float64(price)
+}
+
+// payerAmount returns the part of amount, a charge at price, paid by userId,
+// the owner or a member of sub.
+func payerAmount(sub model.Subscription, userId uuid.UUID, price int64, amount float64) int64 {
+	payers := sub.Payers(price)
+	for i, part := range model.SplitAmount(amount, payers) {
+		if payers[i].UserId == userId {
+			return part
+		}
+	}
+	return int64(math.Round(amount))
+}
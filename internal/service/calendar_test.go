@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"subservice/internal/model"
+	"subservice/internal/storage/memory"
+	"subservice/internal/testutil"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestGetCalendarPromotion(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		promo model.Promotion
+		want  []int64
+	}{
+		{
+			name:  "percent off the promotion months",
+			promo: model.Promotion{Code: "summer25", Kind: model.PromotionPercent, Value: 25, DurationMonths: 2},
+			want:  []int64{100, 75, 75, 100},
+		},
+		{
+			name:  "fixed amount off the promotion months",
+			promo: model.Promotion{Code: "minus30", Kind: model.PromotionFixed, Value: 30, DurationMonths: 1},
+			want:  []int64{100, 70, 100, 100},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ss := NewSubscriptionService(memory.NewStorage(), zap.NewNop())
+			if err := ss.Subscribe(ctx, testutil.Monthly(subId, 100)); err != nil {
+				t.Fatalf("Subscribe() error = %v", err)
+			}
+			if _, err := ss.CreatePromotion(ctx, tt.promo); err != nil {
+				t.Fatalf("CreatePromotion() error = %v", err)
+			}
+			if _, err := ss.ApplyPromotion(ctx, subId, tt.promo.Code, testutil.Month(2024, time.February)); err != nil {
+				t.Fatalf("ApplyPromotion() error = %v", err)
+			}
+
+			events, err := ss.GetCalendar(ctx, testutil.Owner, testutil.Month(2024, time.January), testutil.Month(2024, time.May))
+			if err != nil {
+				t.Fatalf("GetCalendar() error = %v", err)
+			}
+			if len(events) != len(tt.want) {
+				t.Fatalf("GetCalendar() returned %d events, want %d", len(events), len(tt.want))
+			}
+			for i, e := range events {
+				if e.Kind != model.CalendarCharge || e.Price != tt.want[i] {
+					t.Errorf("event %s %s = %d, want charge of %d", e.Kind, e.Date.Format("2006-01-02"), e.Price, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGetCalendarShares(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		split  string
+		share  int64
+		userId uuid.UUID
+		want   int64
+	}{
+		{name: "member half is rounded", split: model.SplitEqual, userId: testutil.MemberB, want: 50},
+		{name: "owner pays the rest of the rounded half", split: model.SplitEqual, userId: testutil.Owner, want: 49},
+		{name: "member pays a fixed share", split: model.SplitFixed, share: 30, userId: testutil.MemberB, want: 30},
+		{name: "owner pays the rest of a fixed share", split: model.SplitFixed, share: 30, userId: testutil.Owner, want: 69},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ss := NewSubscriptionService(memory.NewStorage(), zap.NewNop())
+			sub := testutil.Monthly(subId, 99)
+			sub.Split = tt.split
+			sub.Members = []model.Member{{UserId: testutil.MemberB, Share: tt.share}}
+			if err := ss.Subscribe(ctx, sub); err != nil {
+				t.Fatalf("Subscribe() error = %v", err)
+			}
+
+			events, err := ss.GetCalendar(ctx, tt.userId, testutil.Month(2024, time.January), testutil.Month(2024, time.March))
+			if err != nil {
+				t.Fatalf("GetCalendar() error = %v", err)
+			}
+			if len(events) != 2 {
+				t.Fatalf("GetCalendar() returned %d events, want 2", len(events))
+			}
+			for _, e := range events {
+				if e.Kind != model.CalendarCharge || e.Price != tt.want {
+					t.Errorf("event %s %s = %d, want charge of %d", e.Kind, e.Date.Format("2006-01-02"), e.Price, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"sort"
+	"strings"
+	apimw "subservice/internal/api/middleware"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+)
+
+func (ss *SubscriptionService) CreateCatalogService(ctx context.Context, svc model.CatalogService) (*model.CatalogService, error) {
+	svc, err := normalizeCatalogService(svc)
+	if err != nil {
+		return nil, err
+	}
+	l := apimw.FromContext(ctx).With(zap.String("name", svc.Name))
+	l.Info("Creating catalog service", zap.Strings("aliases", svc.Aliases))
+	if err := ss.Repo.CreateCatalogService(ctx, svc); err != nil {
+		return nil, err
+	}
+	return &svc, nil
+}
+
+func (ss *SubscriptionService) UpdateCatalogService(ctx context.Context, svc model.CatalogService) (*model.CatalogService, error) {
+	svc, err := normalizeCatalogService(svc)
+	if err != nil {
+		return nil, err
+	}
+	l := apimw.FromContext(ctx).With(zap.String("name", svc.Name))
+	l.Info("Updating catalog service", zap.Strings("aliases", svc.Aliases))
+	if err := ss.Repo.UpdateCatalogService(ctx, svc); err != nil {
+		return nil, err
+	}
+	return &svc, nil
+}
+
+func (ss *SubscriptionService) DeleteCatalogService(ctx context.Context, name string) error {
+	l := apimw.FromContext(ctx).With(zap.String("name", name))
+	l.Info("Deleting catalog service")
+	return ss.Repo.DeleteCatalogService(ctx, name)
+}
+
+func (ss *SubscriptionService) GetCatalogService(ctx context.Context, name string) (*model.CatalogService, error) {
+	return ss.Repo.GetCatalogService(ctx, name)
+}
+
+func (ss *SubscriptionService) ListCatalogServices(ctx context.Context, category *string) ([]model.CatalogService, error) {
+	return ss.Repo.ListCatalogServices(ctx, category)
+}
+
+// normalizeCatalogService collapses whitespace in the name, turns aliases into
+// sorted unique service keys and drops the alias equal to the name itself.
+func normalizeCatalogService(svc model.CatalogService) (model.CatalogService, error) {
+	svc.Name = strings.Join(strings.Fields(svc.Name), " ")
+	if svc.Name == "" {
+		return svc, domain.Validation("name", "name is required")
+	}
+	if svc.DefaultPrice != nil && *svc.DefaultPrice < 0 {
+		return svc, domain.Validation("default_price", "default_price cannot be negative")
+	}
+	svc.Category = strings.TrimSpace(svc.Category)
+
+	nameKey := model.ServiceKey(svc.Name)
+	seen := map[string]bool{nameKey: true}
+	aliases := make([]string, 0, len(svc.Aliases))
+	for _, alias := range svc.Aliases {
+		k := model.ServiceKey(alias)
+		if k == "" {
+			return svc, domain.Validation("aliases", "aliases cannot be empty")
+		}
+		if !seen[k] {
+			seen[k] = true
+			aliases = append(aliases, k)
+		}
+	}
+	sort.Strings(aliases)
+	svc.Aliases = nil
+	if len(aliases) > 0 {
+		svc.Aliases = aliases
+	}
+	return svc, nil
+}
+
+// resolveServiceNames replaces the service names of subs that match a catalog
+// service or alias with the canonical name. Unknown names are kept unless
+// RejectUnknownServices is set, in which case they get a validation error.
+func (ss *SubscriptionService) resolveServiceNames(ctx context.Context, subs []model.Subscription) ([]error, error) {
+	keys := make([]string, len(subs))
+	for i, sub := range subs {
+		keys[i] = model.ServiceKey(sub.ServiceName)
+	}
+	resolved, err := ss.Repo.ResolveServiceNames(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	errs := make([]error, len(subs))
+	for i := range subs {
+		if name, ok := resolved[keys[i]]; ok {
+			subs[i].ServiceName = name
+		} else if ss.RejectUnknownServices {
+			errs[i] = domain.Validation("service_name", "unknown service %q", subs[i].ServiceName)
+		}
+	}
+	return errs, nil
+}
+
+func (ss *SubscriptionService) resolveServiceName(ctx context.Context, sub *model.Subscription) error {
+	subs := []model.Subscription{*sub}
+	errs, err := ss.resolveServiceNames(ctx, subs)
+	if err != nil {
+		return err
+	}
+	if errs[0] != nil {
+		return errs[0]
+	}
+	*sub = subs[0]
+	return nil
+}
+
+// applyDefaultPrices gives the subscriptions created without a price the
+// default price of their catalog service, if it has one. Service names must be
+// resolved already.
+func (ss *SubscriptionService) applyDefaultPrices(ctx context.Context, subs []model.Subscription) error {
+	prices := make(map[string]*int64)
+	for i := range subs {
+		if subs[i].Price != 0 {
+			continue
+		}
+		price, ok := prices[subs[i].ServiceName]
+		if !ok {
+			svc, err := ss.Repo.GetCatalogService(ctx, subs[i].ServiceName)
+			if err != nil && !errors.Is(err, domain.ErrNotFound) {
+				return err
+			}
+			if svc != nil {
+				price = svc.DefaultPrice
+			}
+			prices[subs[i].ServiceName] = price
+		}
+		if price != nil {
+			subs[i].Price = *price
+		}
+	}
+	return nil
+}
+
+func (ss *SubscriptionService) applyDefaultPrice(ctx context.Context, sub *model.Subscription) error {
+	subs := []model.Subscription{*sub}
+	if err := ss.applyDefaultPrices(ctx, subs); err != nil {
+		return err
+	}
+	*sub = subs[0]
+	return nil
+}
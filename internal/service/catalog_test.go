@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"subservice/internal/storage/memory"
+	"subservice/internal/testutil"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestCreateCatalogServiceRenamesSubscriptions(t *testing.T) {
+	ctx := context.Background()
+	ss := NewSubscriptionService(memory.NewStorage(), zap.NewNop())
+	sub := testutil.Monthly(subId, 100)
+	sub.ServiceName = "яндекс  плюс"
+	if err := ss.Subscribe(ctx, sub); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	if _, err := ss.CreateCatalogService(ctx, model.CatalogService{Name: "Yandex Plus", Aliases: []string{"Яндекс Плюс"}}); err != nil {
+		t.Fatalf("CreateCatalogService() error = %v", err)
+	}
+
+	got, err := ss.GetSubscription(ctx, subId)
+	if err != nil {
+		t.Fatalf("GetSubscription() error = %v", err)
+	}
+	if got.ServiceName != "Yandex Plus" || got.Version != 2 {
+		t.Errorf("subscription = %q version %d, want \"Yandex Plus\" version 2", got.ServiceName, got.Version)
+	}
+
+	events, err := ss.GetHistory(ctx, model.EventFilter{SubscriptionId: &subId})
+	if err != nil {
+		t.Fatalf("GetHistory() error = %v", err)
+	}
+	if len(events) != 2 || events[0].Action != model.EventUpdate || events[0].ServiceName != "Yandex Plus" {
+		t.Errorf("GetHistory() = %+v, want the rename as the latest update", events)
+	}
+}
+
+func TestApplyPromotionServiceNames(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name         string
+		serviceNames []string
+		wantErr      error
+	}{
+		{name: "any service"},
+		{name: "canonical name", serviceNames: []string{"Yandex Plus"}},
+		{name: "respelled name", serviceNames: []string{"  yandex   PLUS"}},
+		{name: "alias", serviceNames: []string{"Яндекс Плюс"}},
+		{name: "other service", serviceNames: []string{"Kinopoisk"}, wantErr: domain.ErrValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ss := NewSubscriptionService(memory.NewStorage(), zap.NewNop())
+			if _, err := ss.CreateCatalogService(ctx, model.CatalogService{Name: "Yandex Plus", Aliases: []string{"Яндекс Плюс"}}); err != nil {
+				t.Fatalf("CreateCatalogService() error = %v", err)
+			}
+			if err := ss.Subscribe(ctx, testutil.Monthly(subId, 100)); err != nil {
+				t.Fatalf("Subscribe() error = %v", err)
+			}
+			if _, err := ss.CreatePromotion(ctx, model.Promotion{Code: "plus", Kind: model.PromotionPercent, Value: 10, DurationMonths: 1, ServiceNames: tt.serviceNames}); err != nil {
+				t.Fatalf("CreatePromotion() error = %v", err)
+			}
+
+			_, err := ss.ApplyPromotion(ctx, subId, "plus", testutil.Month(2024, time.February))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ApplyPromotion() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
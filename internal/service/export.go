@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"go.uber.org/zap"
+	apimw "subservice/internal/api/middleware"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+)
+
+// ExportSubscriptions calls fn for every subscription matching filter. Unlike
+// ListSubscriptions the result is not paged: a zero Limit exports all rows.
+func (ss *SubscriptionService) ExportSubscriptions(ctx context.Context, filter model.ListFilter, fn func(model.Subscription) error) error {
+	l := apimw.FromContext(ctx)
+	if filter.UserId != nil {
+		l = l.With(zap.String("user_id", filter.UserId.String()))
+	}
+
+	if filter.Limit < 0 {
+		return domain.Validation("limit", "limit cannot be negative")
+	}
+	if filter.SortBy == "" {
+		filter.SortBy = model.SortByServiceName
+	}
+	if filter.After != nil && (filter.After.SortBy != filter.SortBy || filter.After.Desc != filter.Desc) {
+		return domain.Validation("cursor", "cursor does not match sort order")
+	}
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		return domain.Validation("min_price", "min_price cannot be greater than max_price")
+	}
+
+	l.Info("Exporting subscriptions", zap.String("sort", filter.SortBy))
+	return ss.Repo.Export(ctx, filter, fn)
+}
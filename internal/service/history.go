@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+	"go.uber.org/zap"
+	apimw "subservice/internal/api/middleware"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+)
+
+// GetHistory returns audit events newest first. Pages are continued by passing
+// the id of the last returned event as BeforeId.
+func (ss *SubscriptionService) GetHistory(ctx context.Context, filter model.EventFilter) ([]model.SubscriptionEvent, error) {
+	l := apimw.FromContext(ctx)
+
+	if filter.Limit == 0 {
+		filter.Limit = DefaultListLimit
+	}
+	if filter.Limit < 0 || filter.Limit > MaxListLimit {
+		return nil, domain.Validation("limit", "limit must be between 1 and %d", MaxListLimit)
+	}
+	if filter.From != nil && filter.To != nil && filter.To.Before(*filter.From) {
+		return nil, domain.InvalidPeriod("to must not be before from")
+	}
+
+	l.Info("Fetching subscription history", zap.Int("limit", filter.Limit))
+	return ss.Repo.GetHistory(ctx, filter)
+}
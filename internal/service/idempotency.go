@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	apimw "subservice/internal/api/middleware"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"time"
+)
+
+// MaxIdempotencyKeyLength bounds the Idempotency-Key header.
+const MaxIdempotencyKeyLength = 255
+
+// SubscribeIdempotent creates the subscription unless rec.Key was already used
+// for the same subscription, in which case the stored response is returned
+// with replayed set. rec carries the response to store for future replays, so
+// the caller assigns the subscription id; it is not part of the request hash.
+func (ss *SubscriptionService) SubscribeIdempotent(ctx context.Context, subUnit model.Subscription, rec model.IdempotencyKey) (*model.IdempotencyKey, bool, error) {
+	l := apimw.FromContext(ctx).With(zap.String("user_id", subUnit.UserId.String()), zap.String("service_name", subUnit.ServiceName))
+	if rec.Key == "" || len(rec.Key) > MaxIdempotencyKeyLength {
+		return nil, false, domain.Validation("Idempotency-Key", "Idempotency-Key must be 1 to %d characters long", MaxIdempotencyKeyLength)
+	}
+	if err := validatePeriod(subUnit); err != nil {
+		l.Warn("Invalid subscription period", zap.Time("start_date", subUnit.StartDate), zap.Timep("end_date", subUnit.EndDate), zap.Timep("trial_end_date", subUnit.TrialEndDate))
+		return nil, false, err
+	}
+	if err := ss.resolveServiceName(ctx, &subUnit); err != nil {
+		return nil, false, err
+	}
+
+	hashed := subUnit
+	hashed.Id = uuid.Nil
+	payload, err := json.Marshal(hashed)
+	if err != nil {
+		return nil, false, err
+	}
+	hash := sha256.Sum256(payload)
+	rec.UserId = subUnit.UserId
+	rec.RequestHash = hex.EncodeToString(hash[:])
+	rec.CreatedAt = time.Now().UTC()
+	rec.ExpiresAt = rec.CreatedAt.Add(ss.IdempotencyTTL)
+
+	if err := ss.applyDefaultPrice(ctx, &subUnit); err != nil {
+		return nil, false, err
+	}
+
+	if subUnit.Id == uuid.Nil {
+		subUnit.Id = uuid.New()
+	}
+	l.Info("Creating new subscription with idempotency key", zap.String("idempotency_key", rec.Key))
+	stored, replayed, err := ss.Repo.InsertIdempotent(ctx, subUnit, rec)
+	if err != nil {
+		return nil, false, err
+	}
+	if replayed {
+		l.Info("Replaying stored response for idempotency key", zap.String("idempotency_key", rec.Key))
+	}
+	return stored, replayed, nil
+}
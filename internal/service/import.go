@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"go.uber.org/zap"
+	apimw "subservice/internal/api/middleware"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+)
+
+// MaxImportRows bounds the number of rows in one import.
+const MaxImportRows = 100000
+
+// ImportSubscriptions creates the subscriptions in one transaction and returns
+// an error per rejected subscription, nil for the imported ones. Rows without
+// an id get a new one unless a live subscription or an earlier row has the
+// same user, service and start date, so re-importing a file does not duplicate
+// it. With dryRun the rows are checked, including against existing
+// subscriptions, but nothing is written.
+func (ss *SubscriptionService) ImportSubscriptions(ctx context.Context, subs []model.Subscription, dryRun bool) ([]error, error) {
+	l := apimw.FromContext(ctx)
+
+	if len(subs) > MaxImportRows {
+		return nil, domain.Validation("body", "import is limited to %d rows", MaxImportRows)
+	}
+
+	errs, err := ss.resolveServiceNames(ctx, subs)
+	if err != nil {
+		return nil, err
+	}
+	valid := make([]model.Subscription, 0, len(subs))
+	indexes := make([]int, 0, len(subs))
+	for i, sub := range subs {
+		if errs[i] != nil {
+			continue
+		}
+		if err := validatePeriod(sub); err != nil {
+			errs[i] = err
+			continue
+		}
+		valid = append(valid, sub)
+		indexes = append(indexes, i)
+	}
+	if len(valid) == 0 {
+		return errs, nil
+	}
+	if err := ss.applyDefaultPrices(ctx, valid); err != nil {
+		return nil, err
+	}
+
+	l.Info("Importing subscriptions", zap.Int("rows", len(valid)), zap.Bool("dry_run", dryRun))
+	importErrs, err := ss.Repo.Import(ctx, valid, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	for j, err := range importErrs {
+		errs[indexes[j]] = err
+	}
+	return errs, nil
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	apimw "subservice/internal/api/middleware"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"time"
+)
+
+// ResolveSubscriptionId finds the id of the user's subscription to the service
+// for routes that still address subscriptions by (user_id, service_name). A
+// catalog name or alias is resolved to the canonical name first. With
+// deleted it looks among soft-deleted subscriptions, otherwise among live ones,
+// preferring those that have not ended. Subscriptions merely shared with the
+// user do not count. Several candidates are a conflict: the
+// subscription has to be addressed by id.
+func (ss *SubscriptionService) ResolveSubscriptionId(ctx context.Context, userId uuid.UUID, serviceName string, deleted bool) (uuid.UUID, error) {
+	l := apimw.FromContext(ctx).With(zap.String("user_id", userId.String()), zap.String("service_name", serviceName))
+
+	key := model.ServiceKey(serviceName)
+	resolved, err := ss.Repo.ResolveServiceNames(ctx, []string{key})
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if name, ok := resolved[key]; ok {
+		serviceName = name
+	}
+
+	filter := model.ListFilter{
+		UserId:         &userId,
+		ServiceName:    &serviceName,
+		IncludeDeleted: deleted,
+		SortBy:         model.SortByStartDate,
+		Limit:          MaxListLimit,
+	}
+	page, err := ss.Repo.GetList(ctx, filter)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	var candidates []model.Subscription
+	for _, sub := range page.Items {
+		if sub.UserId == userId && (sub.DeletedAt != nil) == deleted {
+			candidates = append(candidates, sub)
+		}
+	}
+	if len(candidates) > 1 && !deleted {
+		now := time.Now().UTC()
+		var current []model.Subscription
+		for _, sub := range candidates {
+			if sub.StatusAt(now, nil) != model.StatusEnded {
+				current = append(current, sub)
+			}
+		}
+		if len(current) > 0 {
+			candidates = current
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		if deleted {
+			return uuid.Nil, domain.NotFound("deleted subscription not found")
+		}
+		return uuid.Nil, domain.NotFound("subscription not found")
+	case 1:
+		return candidates[0].Id, nil
+	default:
+		l.Warn("Subscription key is ambiguous", zap.Int("subscriptions", len(candidates)))
+		return uuid.Nil, domain.Conflict("user has %d subscriptions to %s, address the subscription by id", len(candidates), serviceName)
+	}
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	apimw "subservice/internal/api/middleware"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"time"
+)
+
+// PauseSubscription stops charging the subscription from the month of
+// pause.From until the month of pause.ResumeFrom, or until it is resumed.
+func (ss *SubscriptionService) PauseSubscription(ctx context.Context, id uuid.UUID, pause model.Pause) error {
+	l := apimw.FromContext(ctx).With(zap.String("id", id.String()))
+
+	pause.From = model.FirstOfMonth(pause.From)
+	if pause.ResumeFrom != nil {
+		resumeFrom := model.FirstOfMonth(*pause.ResumeFrom)
+		if !resumeFrom.After(pause.From) {
+			return domain.InvalidPeriod("resume_from must be after the pause start month")
+		}
+		pause.ResumeFrom = &resumeFrom
+	}
+
+	sub, err := ss.Repo.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if pause.From.Before(model.FirstOfMonth(sub.StartDate)) || (sub.EndDate != nil && pause.From.After(model.FirstOfMonth(*sub.EndDate))) {
+		l.Warn("Pause outside of subscription period", zap.Time("from", pause.From))
+		return domain.InvalidPeriod("pause must start within the subscription period")
+	}
+
+	l.Info("Pausing subscription", zap.Time("from", pause.From), zap.Timep("resume_from", pause.ResumeFrom))
+	return ss.Repo.AddPause(ctx, id, pause)
+}
+
+// ResumeSubscription charges the subscription again from the month of from.
+// A pause that would only start later is cancelled.
+func (ss *SubscriptionService) ResumeSubscription(ctx context.Context, id uuid.UUID, from time.Time) error {
+	l := apimw.FromContext(ctx).With(zap.String("id", id.String()))
+	from = model.FirstOfMonth(from)
+	l.Info("Resuming subscription", zap.Time("from", from))
+	return ss.Repo.Resume(ctx, id, from)
+}
+
+func (ss *SubscriptionService) GetPauses(ctx context.Context, id uuid.UUID) ([]model.Pause, error) {
+	if _, err := ss.Repo.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	return ss.Repo.GetPauses(ctx, id)
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"strings"
+	apimw "subservice/internal/api/middleware"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"time"
+)
+
+// MaxPromotionMonths bounds the duration of a promotion.
+const MaxPromotionMonths = 120
+
+func (ss *SubscriptionService) CreatePromotion(ctx context.Context, promo model.Promotion) (*model.Promotion, error) {
+	promo.Code = strings.ToUpper(strings.TrimSpace(promo.Code))
+	l := apimw.FromContext(ctx).With(zap.String("code", promo.Code))
+
+	if !model.ValidPromoCode(promo.Code) {
+		return nil, domain.Validation("code", "code must be 1 to 64 letters, digits, dashes or underscores")
+	}
+	switch promo.Kind {
+	case model.PromotionPercent:
+		if promo.Value < 1 || promo.Value > 100 {
+			return nil, domain.Validation("value", "percent value must be between 1 and 100")
+		}
+	case model.PromotionFixed:
+		if promo.Value < 1 {
+			return nil, domain.Validation("value", "fixed value must be positive")
+		}
+	default:
+		return nil, domain.Validation("kind", "kind must be percent or fixed")
+	}
+	if promo.DurationMonths < 1 || promo.DurationMonths > MaxPromotionMonths {
+		return nil, domain.Validation("duration_months", "duration_months must be between 1 and %d", MaxPromotionMonths)
+	}
+
+	// Service names are stored under their canonical catalog names, like the
+	// names of subscriptions.
+	keys := make([]string, len(promo.ServiceNames))
+	for i, name := range promo.ServiceNames {
+		if strings.TrimSpace(name) == "" {
+			return nil, domain.Validation("service_names", "service_names cannot contain empty names")
+		}
+		keys[i] = model.ServiceKey(name)
+	}
+	resolved, err := ss.Repo.ResolveServiceNames(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+	serviceNames := make([]string, 0, len(promo.ServiceNames))
+	seen := make(map[string]bool, len(promo.ServiceNames))
+	for i, name := range promo.ServiceNames {
+		if canonical, ok := resolved[keys[i]]; ok {
+			name = canonical
+		}
+		if key := model.ServiceKey(name); !seen[key] {
+			seen[key] = true
+			serviceNames = append(serviceNames, name)
+		}
+	}
+	promo.ServiceNames = nil
+	if len(serviceNames) > 0 {
+		promo.ServiceNames = serviceNames
+	}
+	promo.CreatedAt = time.Now().UTC()
+
+	l.Info("Creating promotion", zap.String("kind", promo.Kind), zap.Int64("value", promo.Value), zap.Int("duration_months", promo.DurationMonths))
+	if err := ss.Repo.CreatePromotion(ctx, promo); err != nil {
+		return nil, err
+	}
+	return &promo, nil
+}
+
+func (ss *SubscriptionService) GetPromotion(ctx context.Context, code string) (*model.Promotion, error) {
+	return ss.Repo.GetPromotion(ctx, strings.ToUpper(code))
+}
+
+func (ss *SubscriptionService) ListPromotions(ctx context.Context) ([]model.Promotion, error) {
+	return ss.Repo.ListPromotions(ctx)
+}
+
+func (ss *SubscriptionService) DeletePromotion(ctx context.Context, code string) error {
+	code = strings.ToUpper(code)
+	l := apimw.FromContext(ctx).With(zap.String("code", code))
+	l.Info("Deleting promotion")
+	return ss.Repo.DeletePromotion(ctx, code)
+}
+
+// ApplyPromotion discounts the subscription from the month of from for the
+// duration of the promotion. It must not overlap a promotion applied before.
+func (ss *SubscriptionService) ApplyPromotion(ctx context.Context, id uuid.UUID, code string, from time.Time) (*model.AppliedPromotion, error) {
+	l := apimw.FromContext(ctx).With(zap.String("id", id.String()))
+	applied := model.AppliedPromotion{Code: strings.ToUpper(code), AppliedFrom: model.FirstOfMonth(from)}
+
+	promo, err := ss.Repo.GetPromotion(ctx, applied.Code)
+	if err != nil {
+		return nil, err
+	}
+	sub, err := ss.Repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !promo.AppliesTo(sub.ServiceName) {
+		return nil, domain.Validation("code", "promotion %s does not apply to %s", promo.Code, sub.ServiceName)
+	}
+	if applied.AppliedFrom.Before(model.FirstOfMonth(sub.StartDate)) || (sub.EndDate != nil && applied.AppliedFrom.After(model.FirstOfMonth(*sub.EndDate))) {
+		l.Warn("Promotion outside of subscription period", zap.Time("applied_from", applied.AppliedFrom))
+		return nil, domain.InvalidPeriod("applied_from must be within the subscription period")
+	}
+
+	applied.AppliedUntil = applied.AppliedFrom.AddDate(0, promo.DurationMonths, 0)
+
+	l.Info("Applying promotion", zap.String("code", applied.Code), zap.Time("applied_from", applied.AppliedFrom))
+	if err := ss.Repo.ApplyPromotion(ctx, id, applied); err != nil {
+		return nil, err
+	}
+	return &applied, nil
+}
+
+// GetAppliedPromotion returns the promotion in effect this month, or else the
+// next one scheduled.
+func (ss *SubscriptionService) GetAppliedPromotion(ctx context.Context, id uuid.UUID) (*model.AppliedPromotion, error) {
+	applied, err := ss.ListAppliedPromotions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	month := model.FirstOfMonth(time.Now().UTC())
+	for _, a := range applied {
+		if a.AppliedUntil.After(month) {
+			return &a, nil
+		}
+	}
+	return nil, domain.NotFound("subscription has no promotion")
+}
+
+// ListAppliedPromotions returns every promotion applied to the subscription,
+// including the ended ones, ordered by AppliedFrom.
+func (ss *SubscriptionService) ListAppliedPromotions(ctx context.Context, id uuid.UUID) ([]model.AppliedPromotion, error) {
+	if _, err := ss.Repo.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	return ss.Repo.GetAppliedPromotions(ctx, id)
+}
+
+// RemovePromotion ends the promotion in effect at the month of from, keeping
+// the discount of the months before, or cancels it if it starts later.
+func (ss *SubscriptionService) RemovePromotion(ctx context.Context, id uuid.UUID, from time.Time) error {
+	l := apimw.FromContext(ctx).With(zap.String("id", id.String()))
+	from = model.FirstOfMonth(from)
+	l.Info("Removing promotion", zap.Time("from", from))
+	return ss.Repo.RemovePromotion(ctx, id, from)
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"time"
+)
+
+// RunPurgeJob removes expired idempotency keys and hard-deletes subscriptions
+// that were soft-deleted more than retention ago, once per interval, until ctx
+// is cancelled. A zero retention keeps deleted subscriptions; expired keys are
+// removed regardless.
+func (ss *SubscriptionService) RunPurgeJob(ctx context.Context, retention, interval time.Duration) {
+	l := ss.l.With(zap.String("job", "purge"), zap.Duration("retention", retention))
+	l.Info("Purge job started", zap.Duration("interval", interval))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if retention > 0 {
+			ss.purgeDeleted(ctx, l, retention)
+		}
+		ss.purgeIdempotencyKeys(ctx, l)
+
+		select {
+		case <-ctx.Done():
+			l.Info("Purge job stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (ss *SubscriptionService) purgeDeleted(ctx context.Context, l *zap.Logger, retention time.Duration) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	purged, err := ss.Repo.PurgeDeleted(ctx, time.Now().Add(-retention))
+	if err != nil {
+		l.Error("Failed to purge deleted subscriptions", zap.Error(err))
+		return
+	}
+	if purged > 0 {
+		l.Info("Purged deleted subscriptions", zap.Int64("count", purged))
+	}
+}
+
+func (ss *SubscriptionService) purgeIdempotencyKeys(ctx context.Context, l *zap.Logger) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	purged, err := ss.Repo.PurgeIdempotencyKeys(ctx, time.Now())
+	if err != nil {
+		l.Error("Failed to purge expired idempotency keys", zap.Error(err))
+		return
+	}
+	if purged > 0 {
+		l.Info("Purged expired idempotency keys", zap.Int64("count", purged))
+	}
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"go.uber.org/zap"
+	apimw "subservice/internal/api/middleware"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"time"
+)
+
+func (ss *SubscriptionService) SaveExchangeRate(ctx context.Context, rate model.ExchangeRate) error {
+	l := apimw.FromContext(ctx).With(zap.String("from_currency", rate.FromCurrency), zap.String("to_currency", rate.ToCurrency))
+	if rate.FromCurrency == rate.ToCurrency {
+		return domain.Validation("to_currency", "to_currency must differ from from_currency")
+	}
+	if rate.Rate <= 0 {
+		return domain.Validation("rate", "rate must be positive")
+	}
+	rate.ValidFrom = toDate(rate.ValidFrom)
+
+	l.Info("Saving exchange rate", zap.Time("valid_from", rate.ValidFrom), zap.Float64("rate", rate.Rate))
+	return ss.Repo.SaveExchangeRate(ctx, rate)
+}
+
+func (ss *SubscriptionService) ListExchangeRates(ctx context.Context, fromCurrency, toCurrency *string) ([]model.ExchangeRate, error) {
+	l := apimw.FromContext(ctx)
+	l.Info("Listing exchange rates")
+	return ss.Repo.GetExchangeRates(ctx, fromCurrency, toCurrency)
+}
+
+func (ss *SubscriptionService) DeleteExchangeRate(ctx context.Context, fromCurrency, toCurrency string, validFrom time.Time) error {
+	l := apimw.FromContext(ctx).With(zap.String("from_currency", fromCurrency), zap.String("to_currency", toCurrency))
+	l.Info("Deleting exchange rate", zap.Time("valid_from", validFrom))
+	return ss.Repo.DeleteExchangeRate(ctx, fromCurrency, toCurrency, toDate(validFrom))
+}
+
+// toDate drops the time of day: rates are stored per calendar date.
+func toDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -2,77 +2,221 @@ package service
 
 import (
 	"context"
-	"errors"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 	apimw "subservice/internal/api/middleware"
+	"subservice/internal/domain"
 	"subservice/internal/model"
 	"subservice/internal/storage"
 	"time"
 )
 
+// DefaultIdempotencyTTL is how long Idempotency-Key responses are replayed.
+const DefaultIdempotencyTTL = 24 * time.Hour
+
 type SubscriptionService struct {
-	Repo storage.Facade
-	l    *zap.Logger
+	Repo           storage.Facade
+	IdempotencyTTL time.Duration
+	// RejectUnknownServices makes service names missing from the catalog a
+	// validation error instead of storing them as given.
+	RejectUnknownServices bool
+	l                     *zap.Logger
 }
 
 func NewSubscriptionService(repo storage.Facade, l *zap.Logger) *SubscriptionService {
 	return &SubscriptionService{
-		Repo: repo,
-		l:    l,
+		Repo:           repo,
+		IdempotencyTTL: DefaultIdempotencyTTL,
+		l:              l,
 	}
 }
 
+// Subscribe creates the subscription, assigning it a new id unless the caller
+// already did.
 func (ss *SubscriptionService) Subscribe(ctx context.Context, subUnit model.Subscription) error {
 	l := apimw.FromContext(ctx).With(zap.String("user_id", subUnit.UserId.String()), zap.String("service_name", subUnit.ServiceName))
-	if subUnit.EndDate != nil && subUnit.EndDate.Before(subUnit.StartDate) {
-		l.Warn("End date is before start date", zap.Time("start_date", subUnit.StartDate), zap.Timep("end_date", subUnit.EndDate))
-		return errors.New("end date cannot be before start date")
+	if err := validatePeriod(subUnit); err != nil {
+		l.Warn("Invalid subscription period", zap.Time("start_date", subUnit.StartDate), zap.Timep("end_date", subUnit.EndDate), zap.Timep("trial_end_date", subUnit.TrialEndDate))
+		return err
+	}
+	if err := ss.resolveServiceName(ctx, &subUnit); err != nil {
+		return err
+	}
+	if err := ss.applyDefaultPrice(ctx, &subUnit); err != nil {
+		return err
+	}
+	if subUnit.Id == uuid.Nil {
+		subUnit.Id = uuid.New()
 	}
 	l.Info("Creating new subscription", zap.Any("subscription", subUnit))
 	return ss.Repo.Insert(ctx, subUnit)
 }
 
-func (ss *SubscriptionService) GetSubscription(ctx context.Context, userId uuid.UUID, serviceName string) (*model.Subscription, error) {
-	l := apimw.FromContext(ctx).With(zap.String("user_id", userId.String()), zap.String("service_name", serviceName))
+// validatePeriod checks that the subscription does not end before it starts
+// and that its trial lies within [start_date, end_date].
+func validatePeriod(sub model.Subscription) error {
+	if sub.EndDate != nil && sub.EndDate.Before(sub.StartDate) {
+		return domain.InvalidPeriod("end date cannot be before start date")
+	}
+	if sub.TrialEndDate != nil {
+		if sub.TrialEndDate.Before(sub.StartDate) {
+			return domain.InvalidPeriod("trial end date cannot be before start date")
+		}
+		if sub.EndDate != nil && sub.TrialEndDate.After(*sub.EndDate) {
+			return domain.InvalidPeriod("trial end date cannot be after end date")
+		}
+	}
+	return nil
+}
+
+func (ss *SubscriptionService) GetSubscription(ctx context.Context, id uuid.UUID) (*model.Subscription, error) {
+	l := apimw.FromContext(ctx).With(zap.String("id", id.String()))
 	l.Info("Fetching subscription")
-	return ss.Repo.Get(ctx, userId, serviceName)
+	sub, err := ss.Repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	pauses, err := ss.Repo.GetPauses(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	sub.Status = sub.StatusAt(time.Now().UTC(), pauses)
+	return sub, nil
 }
 
 func (ss *SubscriptionService) UpdateSubscription(ctx context.Context, subUnit model.Subscription) error {
-	l := apimw.FromContext(ctx).With(zap.String("user_id", subUnit.UserId.String()), zap.String("service_name", subUnit.ServiceName))
-	if subUnit.EndDate != nil && subUnit.EndDate.Before(subUnit.StartDate) {
-		l.Warn("End date is before start date", zap.Time("start_date", subUnit.StartDate), zap.Timep("end_date", subUnit.EndDate))
-		return errors.New("end date cannot be before start date")
+	l := apimw.FromContext(ctx).With(zap.String("id", subUnit.Id.String()))
+	if err := validatePeriod(subUnit); err != nil {
+		l.Warn("Invalid subscription period", zap.Time("start_date", subUnit.StartDate), zap.Timep("end_date", subUnit.EndDate), zap.Timep("trial_end_date", subUnit.TrialEndDate))
+		return err
+	}
+	if err := ss.resolveServiceName(ctx, &subUnit); err != nil {
+		return err
 	}
 	l.Info("Updating subscription", zap.Any("subscription", subUnit))
 	return ss.Repo.Update(ctx, subUnit)
 }
 
-func (ss *SubscriptionService) Unsubscribe(ctx context.Context, userId uuid.UUID, serviceName string) error {
-	l := apimw.FromContext(ctx).With(zap.String("user_id", userId.String()), zap.String("service_name", serviceName))
-	l.Info("Deleting subscription")
-	return ss.Repo.Delete(ctx, userId, serviceName)
+func (ss *SubscriptionService) Unsubscribe(ctx context.Context, id uuid.UUID, version int64) error {
+	l := apimw.FromContext(ctx).With(zap.String("id", id.String()))
+	l.Info("Deleting subscription", zap.Int64("version", version))
+	return ss.Repo.Delete(ctx, id, version)
+}
+
+func (ss *SubscriptionService) RestoreSubscription(ctx context.Context, id uuid.UUID) error {
+	l := apimw.FromContext(ctx).With(zap.String("id", id.String()))
+	l.Info("Restoring subscription")
+	return ss.Repo.Restore(ctx, id)
 }
 
-func (ss *SubscriptionService) ListSubscriptions(ctx context.Context, userId uuid.UUID) (*[]model.Subscription, error) {
-	l := apimw.FromContext(ctx).With(zap.String("user_id", userId.String()))
-	l.Info("Listing subscriptions")
-	return ss.Repo.GetList(ctx, userId)
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 500
+)
+
+func (ss *SubscriptionService) ListSubscriptions(ctx context.Context, filter model.ListFilter) (*model.SubscriptionPage, error) {
+	l := apimw.FromContext(ctx)
+	if filter.UserId != nil {
+		l = l.With(zap.String("user_id", filter.UserId.String()))
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = DefaultListLimit
+	}
+	if filter.Limit < 0 || filter.Limit > MaxListLimit {
+		return nil, domain.Validation("limit", "limit must be between 1 and %d", MaxListLimit)
+	}
+	if filter.SortBy == "" {
+		filter.SortBy = model.SortByServiceName
+	}
+	if filter.After != nil && (filter.After.SortBy != filter.SortBy || filter.After.Desc != filter.Desc) {
+		return nil, domain.Validation("cursor", "cursor does not match sort order")
+	}
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		return nil, domain.Validation("min_price", "min_price cannot be greater than max_price")
+	}
+
+	l.Info("Listing subscriptions", zap.String("sort", filter.SortBy), zap.Int("limit", filter.Limit))
+	return ss.Repo.GetList(ctx, filter)
 }
 
-func (ss *SubscriptionService) GetSubscriptionSummary(ctx context.Context, from, to time.Time, userId *uuid.UUID, serviceName *string) (int, error) {
+func (ss *SubscriptionService) GetSubscriptionSummary(ctx context.Context, filter model.SummaryFilter) (*model.Summary, error) {
 	l := apimw.FromContext(ctx)
-	if userId != nil {
-		l = l.With(zap.String("user_id", userId.String()))
+	if filter.UserId != nil {
+		l = l.With(zap.String("user_id", filter.UserId.String()))
+	}
+	if filter.ServiceName != nil {
+		l = l.With(zap.String("service_name", *filter.ServiceName))
+	}
+	if filter.Tag != nil {
+		l = l.With(zap.String("tag", *filter.Tag))
+	}
+	if filter.From.After(filter.To) {
+		l.Warn("From date is after to date", zap.Time("from", filter.From), zap.Time("to", filter.To))
+		return nil, domain.InvalidPeriod("from date cannot be after to date")
+	}
+	l.Info("Getting subscription summary", zap.Time("from", filter.From), zap.Time("to", filter.To))
+
+	if !filter.Grouped() {
+		total, err := ss.Repo.GetSummary(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		summary := &model.Summary{TotalPrice: total}
+		if filter.TargetCurrency != nil {
+			summary.Currency = *filter.TargetCurrency
+		}
+		return summary, nil
+	}
+
+	breakdown, err := ss.Repo.GetSummaryBreakdown(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	summary := &model.Summary{Breakdown: breakdown}
+	if filter.TargetCurrency != nil {
+		summary.Currency = *filter.TargetCurrency
 	}
-	if serviceName != nil {
-		l = l.With(zap.String("service_name", *serviceName))
+	// A subscription with several tags appears in several tag groups, so the
+	// total is not the sum of such a breakdown.
+	if filter.GroupByTag {
+		if summary.TotalPrice, err = ss.Repo.GetSummary(ctx, filter); err != nil {
+			return nil, err
+		}
+		return summary, nil
 	}
-	if from.After(to) {
-		l.Warn("From date is after to date", zap.Time("from", from), zap.Time("to", to))
-		return 0, errors.New("from date cannot be after to date")
+	for _, row := range breakdown {
+		summary.TotalPrice += int(row.Total)
+	}
+	return summary, nil
+}
+
+func (ss *SubscriptionService) SchedulePriceChange(ctx context.Context, id uuid.UUID, change model.PriceChange) error {
+	l := apimw.FromContext(ctx).With(zap.String("id", id.String()))
+	if change.Price < 0 {
+		return domain.Validation("price", "price cannot be negative")
+	}
+
+	sub, err := ss.Repo.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	month := model.FirstOfMonth(change.EffectiveFrom)
+	if month.Before(model.FirstOfMonth(sub.StartDate)) || (sub.EndDate != nil && month.After(model.FirstOfMonth(*sub.EndDate))) {
+		l.Warn("Price change outside of subscription period", zap.Time("effective_from", change.EffectiveFrom))
+		return domain.InvalidPeriod("effective_from must be within the subscription period")
+	}
+
+	l.Info("Scheduling price change", zap.Time("effective_from", month), zap.Int64("price", change.Price))
+	return ss.Repo.AddPriceChange(ctx, id, change)
+}
+
+func (ss *SubscriptionService) GetPriceHistory(ctx context.Context, id uuid.UUID) ([]model.PriceChange, error) {
+	l := apimw.FromContext(ctx).With(zap.String("id", id.String()))
+	l.Info("Fetching price history")
+	if _, err := ss.Repo.Get(ctx, id); err != nil {
+		return nil, err
 	}
-	l.Info("Getting subscription summary", zap.Time("from", from), zap.Time("to", to))
-	return ss.Repo.GetSummary(ctx, from, to, userId, serviceName)
+	return ss.Repo.GetPriceHistory(ctx, id)
 }
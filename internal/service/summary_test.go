@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"subservice/internal/storage/memory"
+	"subservice/internal/testutil"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var subId = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+func TestGetSubscriptionSummary(t *testing.T) {
+	ctx := context.Background()
+	thisMonth := model.FirstOfMonth(time.Now().UTC())
+	threeMonthsAgo := thisMonth.AddDate(0, -3, 0)
+	rub := "RUB"
+
+	tests := []struct {
+		name         string
+		setup        func(ss *SubscriptionService) error
+		filter       model.SummaryFilter
+		want         int
+		wantCurrency string
+		wantErr      error
+	}{
+		{
+			name:    "from after to",
+			setup:   func(ss *SubscriptionService) error { return nil },
+			filter:  model.SummaryFilter{From: testutil.Month(2024, time.June), To: testutil.Month(2024, time.January)},
+			wantErr: domain.ErrInvalidPeriod,
+		},
+		{
+			name:   "monthly",
+			setup:  func(ss *SubscriptionService) error { return ss.Subscribe(ctx, testutil.Monthly(subId, 100)) },
+			filter: model.SummaryFilter{From: testutil.Month(2024, time.January), To: testutil.Month(2024, time.March)},
+			want:   300,
+		},
+		{
+			name: "updated price keeps the months before",
+			setup: func(ss *SubscriptionService) error {
+				sub := testutil.Monthly(subId, 100)
+				sub.StartDate = threeMonthsAgo
+				if err := ss.Subscribe(ctx, sub); err != nil {
+					return err
+				}
+				sub.Price = 150
+				return ss.UpdateSubscription(ctx, sub)
+			},
+			filter: model.SummaryFilter{From: threeMonthsAgo, To: thisMonth},
+			want:   3*100 + 150,
+		},
+		{
+			name: "earlier start keeps the price of the months charged before",
+			setup: func(ss *SubscriptionService) error {
+				sub := testutil.Monthly(subId, 100)
+				sub.StartDate = threeMonthsAgo
+				if err := ss.Subscribe(ctx, sub); err != nil {
+					return err
+				}
+				sub.StartDate = threeMonthsAgo.AddDate(0, -2, 0)
+				sub.Price = 150
+				return ss.UpdateSubscription(ctx, sub)
+			},
+			filter: model.SummaryFilter{From: threeMonthsAgo.AddDate(0, -2, 0), To: thisMonth},
+			want:   5*100 + 150,
+		},
+		{
+			name: "scheduled price change",
+			setup: func(ss *SubscriptionService) error {
+				if err := ss.Subscribe(ctx, testutil.Monthly(subId, 100)); err != nil {
+					return err
+				}
+				return ss.SchedulePriceChange(ctx, subId, model.PriceChange{EffectiveFrom: testutil.Month(2024, time.May), Price: 200})
+			},
+			filter: model.SummaryFilter{From: testutil.Month(2024, time.January), To: testutil.Month(2024, time.June)},
+			want:   4*100 + 2*200,
+		},
+		{
+			name: "yearly amortized",
+			setup: func(ss *SubscriptionService) error {
+				sub := testutil.Monthly(subId, 1200)
+				sub.BillingPeriod = model.BillingYear
+				return ss.Subscribe(ctx, sub)
+			},
+			filter: model.SummaryFilter{From: testutil.Month(2024, time.January), To: testutil.Month(2024, time.March), Amortize: true},
+			want:   300,
+		},
+		{
+			name: "converted to target currency",
+			setup: func(ss *SubscriptionService) error {
+				sub := testutil.Monthly(subId, 10)
+				sub.Currency = "USD"
+				if err := ss.Subscribe(ctx, sub); err != nil {
+					return err
+				}
+				return ss.SaveExchangeRate(ctx, model.ExchangeRate{FromCurrency: "USD", ToCurrency: "RUB", ValidFrom: testutil.Month(2024, time.January), Rate: 90.5})
+			},
+			filter:       model.SummaryFilter{From: testutil.Month(2024, time.January), To: testutil.Month(2024, time.February), TargetCurrency: &rub},
+			want:         2 * 905,
+			wantCurrency: "RUB",
+		},
+		{
+			name: "trial",
+			setup: func(ss *SubscriptionService) error {
+				sub := testutil.Monthly(subId, 100)
+				sub.TrialEndDate = testutil.PtrTime(testutil.Month(2024, time.January))
+				return ss.Subscribe(ctx, sub)
+			},
+			filter: model.SummaryFilter{From: testutil.Month(2024, time.January), To: testutil.Month(2024, time.March)},
+			want:   200,
+		},
+		{
+			name: "paused and resumed",
+			setup: func(ss *SubscriptionService) error {
+				if err := ss.Subscribe(ctx, testutil.Monthly(subId, 100)); err != nil {
+					return err
+				}
+				if err := ss.PauseSubscription(ctx, subId, model.Pause{From: testutil.Month(2024, time.February)}); err != nil {
+					return err
+				}
+				return ss.ResumeSubscription(ctx, subId, testutil.Month(2024, time.May))
+			},
+			filter: model.SummaryFilter{From: testutil.Month(2024, time.January), To: testutil.Month(2024, time.June)},
+			want:   3 * 100,
+		},
+		{
+			name: "promotion for its duration",
+			setup: func(ss *SubscriptionService) error {
+				if err := ss.Subscribe(ctx, testutil.Monthly(subId, 100)); err != nil {
+					return err
+				}
+				if _, err := ss.CreatePromotion(ctx, model.Promotion{Code: "summer25", Kind: model.PromotionPercent, Value: 25, DurationMonths: 2}); err != nil {
+					return err
+				}
+				_, err := ss.ApplyPromotion(ctx, subId, "summer25", testutil.Month(2024, time.March))
+				return err
+			},
+			filter: model.SummaryFilter{From: testutil.Month(2024, time.January), To: testutil.Month(2024, time.June)},
+			want:   4*100 + 2*75,
+		},
+		{
+			name: "shares by user add up to the charges",
+			setup: func(ss *SubscriptionService) error {
+				sub := testutil.Monthly(subId, 100)
+				sub.Split = model.SplitPercent
+				sub.Members = []model.Member{{UserId: testutil.MemberB, Share: 33}}
+				return ss.Subscribe(ctx, sub)
+			},
+			filter: model.SummaryFilter{From: testutil.Month(2024, time.January), To: testutil.Month(2024, time.March), GroupByUser: true},
+			want:   300,
+		},
+		{
+			name: "member share",
+			setup: func(ss *SubscriptionService) error {
+				sub := testutil.Monthly(subId, 99)
+				sub.Split = model.SplitEqual
+				sub.Members = []model.Member{{UserId: testutil.MemberB}}
+				return ss.Subscribe(ctx, sub)
+			},
+			filter: model.SummaryFilter{From: testutil.Month(2024, time.January), To: testutil.Month(2024, time.March), UserId: &testutil.MemberB},
+			want:   3 * 50,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ss := NewSubscriptionService(memory.NewStorage(), zap.NewNop())
+			if err := tt.setup(ss); err != nil {
+				t.Fatalf("setup: %v", err)
+			}
+
+			got, err := ss.GetSubscriptionSummary(ctx, tt.filter)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("GetSubscriptionSummary() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetSubscriptionSummary() error = %v", err)
+			}
+			if got.TotalPrice != tt.want || got.Currency != tt.wantCurrency {
+				t.Errorf("GetSubscriptionSummary() = %d %q, want %d %q", got.TotalPrice, got.Currency, tt.want, tt.wantCurrency)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	apimw "subservice/internal/api/middleware"
+	"subservice/internal/model"
+	"time"
+
+	chimw "github.com/go-chi/chi/v5/middleware"
+)
+
+// NewEvent builds an audit event for a change of sub made within ctx. before
+// and after are marshalled to JSON; nil values are left out.
+func NewEvent(ctx context.Context, action string, sub model.Subscription, before, after interface{}) (model.SubscriptionEvent, error) {
+	id := sub.Id
+	event := model.SubscriptionEvent{
+		SubscriptionId: &id,
+		UserId:         sub.UserId,
+		ServiceName:    sub.ServiceName,
+		Action:         action,
+		RequestId:      chimw.GetReqID(ctx),
+		Actor:          apimw.ActorFromContext(ctx),
+		CreatedAt:      time.Now().UTC(),
+	}
+
+	var err error
+	if before != nil {
+		if event.Before, err = json.Marshal(before); err != nil {
+			return event, err
+		}
+	}
+	if after != nil {
+		if event.After, err = json.Marshal(after); err != nil {
+			return event, err
+		}
+	}
+	return event, nil
+}
+
+// NewBudgetEvent builds the audit event reporting alert.
+func NewBudgetEvent(ctx context.Context, alert model.BudgetAlert) (model.SubscriptionEvent, error) {
+	event := model.SubscriptionEvent{
+		UserId:    alert.UserId,
+		Action:    model.EventBudgetExceeded,
+		RequestId: chimw.GetReqID(ctx),
+		Actor:     apimw.ActorFromContext(ctx),
+		CreatedAt: time.Now().UTC(),
+	}
+
+	var err error
+	event.After, err = json.Marshal(alert)
+	return event, err
+}
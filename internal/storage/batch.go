@@ -0,0 +1,18 @@
+package storage
+
+import (
+	"errors"
+	"subservice/internal/domain"
+)
+
+// errBatchFailed rolls back the transaction of a batch with a failed operation.
+var errBatchFailed = errors.New("batch operation failed")
+
+// AbortBatch records err for operation failed and marks every other operation
+// of the batch as aborted.
+func AbortBatch(errs []error, failed int, err error) {
+	for i := range errs {
+		errs[i] = domain.ErrBatchAborted
+	}
+	errs[failed] = err
+}
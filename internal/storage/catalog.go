@@ -0,0 +1,22 @@
+package storage
+
+import (
+	"subservice/internal/domain"
+	"subservice/internal/model"
+)
+
+// CheckCatalogKeys fails with domain.ErrConflict if the name or an alias of svc
+// already resolves to another service. resolved maps keys to canonical names.
+func CheckCatalogKeys(svc model.CatalogService, resolved map[string]string) error {
+	for _, k := range CatalogKeys(svc) {
+		if name, ok := resolved[k]; ok && name != svc.Name {
+			return domain.Conflict("%q already refers to service %s", k, name)
+		}
+	}
+	return nil
+}
+
+// CatalogKeys returns the lookup keys of svc: its normalized name and aliases.
+func CatalogKeys(svc model.CatalogService) []string {
+	return append([]string{model.ServiceKey(svc.Name)}, svc.Aliases...)
+}
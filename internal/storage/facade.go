@@ -2,19 +2,93 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"github.com/google/uuid"
+	"subservice/internal/domain"
 	"subservice/internal/model"
 	"subservice/internal/storage/postgres"
 	"time"
 )
 
+// Facade is the storage used by the service layer. Subscriptions are addressed
+// by id; Insert expects the caller to have assigned one. Implementations report
+// missing and duplicate subscriptions as domain.ErrNotFound and
+// domain.ErrConflict so callers can match them with errors.Is. Every change
+// of a subscription is recorded in the audit log atomically with the change.
+// Deleted subscriptions are kept, invisible to reads, until they are purged.
+// Update and Delete check the caller's version and report a stale one as
+// domain.ErrPreconditionFailed; a zero version skips the check. Update cannot
+// move a subscription to another user or service, and a new price takes effect
+// from the current month, see PriceChangesOnUpdate.
 type Facade interface {
 	Insert(ctx context.Context, subUnit model.Subscription) error
-	Get(ctx context.Context, userId uuid.UUID, serviceId string) (*model.Subscription, error)
+	// InsertIdempotent inserts subUnit and stores rec in one transaction. If an
+	// unexpired record with the same key exists, it is returned instead with
+	// replayed set, or domain.ErrUnprocessable if its request hash differs.
+	InsertIdempotent(ctx context.Context, subUnit model.Subscription, rec model.IdempotencyKey) (stored *model.IdempotencyKey, replayed bool, err error)
+	PurgeIdempotencyKeys(ctx context.Context, expiredBefore time.Time) (int64, error)
+	Get(ctx context.Context, id uuid.UUID) (*model.Subscription, error)
 	Update(ctx context.Context, subUnit model.Subscription) error
-	Delete(ctx context.Context, userId uuid.UUID, serviceId string) error
-	GetList(ctx context.Context, userId uuid.UUID) (*[]model.Subscription, error)
-	GetSummary(ctx context.Context, from time.Time, to time.Time, userId *uuid.UUID, serviceId *string) (int, error)
+	Delete(ctx context.Context, id uuid.UUID, version int64) error
+	// ApplyBatch applies the operations all-or-nothing. The returned slice has
+	// an error per failed or rolled back operation and is all nil on success.
+	ApplyBatch(ctx context.Context, ops []model.BatchOperation) ([]error, error)
+	// Import bulk-inserts subs in one transaction and returns an error per
+	// rejected subscription. Subscriptions without an id get a new one unless
+	// they repeat a live one, see ImportRows. With dryRun nothing is written.
+	Import(ctx context.Context, subs []model.Subscription, dryRun bool) ([]error, error)
+	Restore(ctx context.Context, id uuid.UUID) error
+	PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error)
+	GetList(ctx context.Context, filter model.ListFilter) (*model.SubscriptionPage, error)
+	// Export calls fn for every subscription matching filter in listing order
+	// without loading them all at once; an error from fn stops the export.
+	Export(ctx context.Context, filter model.ListFilter, fn func(model.Subscription) error) error
+	GetSummary(ctx context.Context, filter model.SummaryFilter) (int, error)
+	GetSummaryBreakdown(ctx context.Context, filter model.SummaryFilter) ([]model.SummaryRow, error)
+	AddPriceChange(ctx context.Context, id uuid.UUID, change model.PriceChange) error
+	GetPriceHistory(ctx context.Context, id uuid.UUID) ([]model.PriceChange, error)
+	// AddPause stores a pause that must not overlap the existing ones. Pausing
+	// and resuming may change the status of the subscription, so both increment
+	// its version.
+	AddPause(ctx context.Context, id uuid.UUID, pause model.Pause) error
+	// Resume ends the pause in effect at month from, or cancels it if it has
+	// not started by then.
+	Resume(ctx context.Context, id uuid.UUID, from time.Time) error
+	GetPauses(ctx context.Context, id uuid.UUID) ([]model.Pause, error)
+	CreatePromotion(ctx context.Context, promo model.Promotion) error
+	GetPromotion(ctx context.Context, code string) (*model.Promotion, error)
+	ListPromotions(ctx context.Context) ([]model.Promotion, error)
+	// DeletePromotion reports domain.ErrConflict while the promotion is applied.
+	DeletePromotion(ctx context.Context, code string) error
+	// ApplyPromotion attaches a promotion that must not overlap the ones
+	// applied before.
+	ApplyPromotion(ctx context.Context, id uuid.UUID, applied model.AppliedPromotion) error
+	GetAppliedPromotions(ctx context.Context, id uuid.UUID) ([]model.AppliedPromotion, error)
+	// RemovePromotion ends the promotion in effect at month from, or cancels it
+	// if it has not started by then.
+	RemovePromotion(ctx context.Context, id uuid.UUID, from time.Time) error
+	// CreateCatalogService and UpdateCatalogService report domain.ErrConflict
+	// if the name or an alias already refers to another service. Subscriptions
+	// stored under the name or an alias are renamed to the canonical name.
+	CreateCatalogService(ctx context.Context, svc model.CatalogService) error
+	UpdateCatalogService(ctx context.Context, svc model.CatalogService) error
+	DeleteCatalogService(ctx context.Context, name string) error
+	GetCatalogService(ctx context.Context, name string) (*model.CatalogService, error)
+	ListCatalogServices(ctx context.Context, category *string) ([]model.CatalogService, error)
+	// ResolveServiceNames maps the model.ServiceKey values among keys that name a
+	// catalog service or alias to the canonical name; unknown keys are omitted.
+	ResolveServiceNames(ctx context.Context, keys []string) (map[string]string, error)
+	SaveExchangeRate(ctx context.Context, rate model.ExchangeRate) error
+	GetExchangeRates(ctx context.Context, fromCurrency, toCurrency *string) ([]model.ExchangeRate, error)
+	DeleteExchangeRate(ctx context.Context, fromCurrency, toCurrency string, validFrom time.Time) error
+	// SaveBudget creates or replaces the user's budget for its category.
+	SaveBudget(ctx context.Context, budget model.Budget) error
+	ListBudgets(ctx context.Context, userId uuid.UUID) ([]model.Budget, error)
+	DeleteBudget(ctx context.Context, userId uuid.UUID, category string) error
+	// RecordBudgetAlerts stores the alerts for months not reported before and
+	// appends an audit event for each of them, which it returns.
+	RecordBudgetAlerts(ctx context.Context, alerts []model.BudgetAlert) ([]model.BudgetAlert, error)
+	GetHistory(ctx context.Context, filter model.EventFilter) ([]model.SubscriptionEvent, error)
 }
 
 type StorageFacade struct {
@@ -30,25 +104,508 @@ func NewStorageFacade(txManager postgres.TransactionManager, pgRepository postgr
 }
 
 func (f *StorageFacade) Insert(ctx context.Context, subUnit model.Subscription) error {
-	return f.pgRepository.InsertSubscription(ctx, subUnit)
+	return f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+		return f.insert(ctxTx, subUnit)
+	})
 }
 
-func (f *StorageFacade) Get(ctx context.Context, userId uuid.UUID, serviceId string) (*model.Subscription, error) {
-	return f.pgRepository.GetSubscription(ctx, userId, serviceId)
+func (f *StorageFacade) InsertIdempotent(ctx context.Context, subUnit model.Subscription, rec model.IdempotencyKey) (*model.IdempotencyKey, bool, error) {
+	var (
+		stored   *model.IdempotencyKey
+		replayed bool
+	)
+	err := f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+		existing, err := f.pgRepository.GetIdempotencyKey(ctxTx, rec.UserId, rec.Key)
+		switch {
+		case err == nil:
+			if existing.RequestHash != rec.RequestHash {
+				return domain.Unprocessable("idempotency key was already used with a different request")
+			}
+			stored, replayed = existing, true
+			return nil
+		case !errors.Is(err, domain.ErrNotFound):
+			return err
+		}
+
+		if err := f.insert(ctxTx, subUnit); err != nil {
+			return err
+		}
+		if err := f.pgRepository.SaveIdempotencyKey(ctxTx, rec); err != nil {
+			return err
+		}
+		stored, replayed = &rec, false
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return stored, replayed, nil
+}
+
+func (f *StorageFacade) PurgeIdempotencyKeys(ctx context.Context, expiredBefore time.Time) (int64, error) {
+	return f.pgRepository.DeleteExpiredIdempotencyKeys(ctx, expiredBefore)
+}
+
+func (f *StorageFacade) insert(ctx context.Context, subUnit model.Subscription) error {
+	if err := f.pgRepository.InsertSubscription(ctx, subUnit); err != nil {
+		return err
+	}
+	after, err := f.pgRepository.GetSubscription(ctx, subUnit.Id)
+	if err != nil {
+		return err
+	}
+	return f.recordEvent(ctx, model.EventCreate, *after, nil, after)
+}
+
+func (f *StorageFacade) Get(ctx context.Context, id uuid.UUID) (*model.Subscription, error) {
+	return f.pgRepository.GetSubscription(ctx, id)
 }
 
 func (f *StorageFacade) Update(ctx context.Context, subUnit model.Subscription) error {
-	return f.pgRepository.UpdateSubscription(ctx, subUnit)
+	return f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+		return f.update(ctxTx, subUnit)
+	})
+}
+
+func (f *StorageFacade) update(ctx context.Context, subUnit model.Subscription) error {
+	before, err := f.pgRepository.GetSubscription(ctx, subUnit.Id)
+	if err != nil {
+		return err
+	}
+	if err := CheckVersion(before, subUnit.Version); err != nil {
+		return err
+	}
+	if err := CheckIdentity(before, subUnit); err != nil {
+		return err
+	}
+	history, err := f.pgRepository.GetPriceChanges(ctx, subUnit.Id)
+	if err != nil {
+		return err
+	}
+	changes, err := PriceChangesOnUpdate(before, history, subUnit, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	for _, change := range changes {
+		if err := f.pgRepository.InsertPriceChange(ctx, subUnit.Id, change); err != nil {
+			return err
+		}
+	}
+	if err := f.pgRepository.UpdateSubscription(ctx, subUnit); err != nil {
+		return err
+	}
+	after, err := f.pgRepository.GetSubscription(ctx, subUnit.Id)
+	if err != nil {
+		return err
+	}
+	return f.recordEvent(ctx, model.EventUpdate, *after, before, after)
+}
+
+func (f *StorageFacade) Delete(ctx context.Context, id uuid.UUID, version int64) error {
+	return f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+		return f.delete(ctxTx, id, version)
+	})
+}
+
+func (f *StorageFacade) delete(ctx context.Context, id uuid.UUID, version int64) error {
+	before, err := f.pgRepository.GetSubscription(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := CheckVersion(before, version); err != nil {
+		return err
+	}
+	if err := f.pgRepository.DeleteSubscription(ctx, id, version); err != nil {
+		return err
+	}
+	return f.recordEvent(ctx, model.EventDelete, *before, before, nil)
+}
+
+// ApplyBatch runs all operations in one transaction. On the first failing
+// operation the transaction is rolled back; that operation gets its error and
+// every other one domain.ErrBatchAborted.
+func (f *StorageFacade) ApplyBatch(ctx context.Context, ops []model.BatchOperation) ([]error, error) {
+	var errs []error
+	err := f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+		errs = make([]error, len(ops))
+		for i, op := range ops {
+			if err := f.apply(ctxTx, op); err != nil {
+				if !domain.IsDomainError(err) {
+					return err
+				}
+				AbortBatch(errs, i, err)
+				return errBatchFailed
+			}
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errBatchFailed) {
+		return nil, err
+	}
+	return errs, nil
+}
+
+func (f *StorageFacade) Import(ctx context.Context, subs []model.Subscription, dryRun bool) ([]error, error) {
+	var errs []error
+	err := f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+		live, err := f.pgRepository.LiveSubscriptionsExist(ctxTx, subs)
+		if err != nil {
+			return err
+		}
+		rows, indexes, rowErrs := ImportRows(subs, live)
+		inserted, err := f.pgRepository.ImportSubscriptions(ctxTx, rows)
+		if err != nil {
+			return err
+		}
+		for j, err := range ImportErrors(rows, inserted) {
+			rowErrs[indexes[j]] = err
+		}
+		errs = rowErrs
+		if dryRun {
+			return errDryRun
+		}
+
+		events := make([]model.SubscriptionEvent, 0, len(rows))
+		for i, sub := range rows {
+			if !inserted[i] {
+				continue
+			}
+			snapshot := ImportedSnapshot(sub)
+			event, err := NewEvent(ctx, model.EventCreate, snapshot, nil, snapshot)
+			if err != nil {
+				return err
+			}
+			events = append(events, event)
+		}
+		return f.pgRepository.InsertEvents(ctxTx, events)
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+	return errs, nil
+}
+
+func (f *StorageFacade) apply(ctx context.Context, op model.BatchOperation) error {
+	switch op.Op {
+	case model.BatchCreate:
+		return f.insert(ctx, op.Subscription)
+	case model.BatchUpdate:
+		return f.update(ctx, op.Subscription)
+	case model.BatchDelete:
+		return f.delete(ctx, op.Subscription.Id, op.Subscription.Version)
+	default:
+		return domain.Validation("op", "unknown operation %q", op.Op)
+	}
+}
+
+func (f *StorageFacade) Restore(ctx context.Context, id uuid.UUID) error {
+	return f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+		if err := f.pgRepository.RestoreSubscription(ctxTx, id); err != nil {
+			return err
+		}
+		after, err := f.pgRepository.GetSubscription(ctxTx, id)
+		if err != nil {
+			return err
+		}
+		return f.recordEvent(ctxTx, model.EventRestore, *after, nil, after)
+	})
+}
+
+func (f *StorageFacade) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	return f.pgRepository.PurgeSubscriptions(ctx, deletedBefore)
+}
+
+func (f *StorageFacade) GetList(ctx context.Context, filter model.ListFilter) (*model.SubscriptionPage, error) {
+	return f.pgRepository.GetSubscriptionsList(ctx, filter)
+}
+
+func (f *StorageFacade) Export(ctx context.Context, filter model.ListFilter, fn func(model.Subscription) error) error {
+	return f.pgRepository.StreamSubscriptions(ctx, filter, fn)
+}
+
+func (f *StorageFacade) GetSummary(ctx context.Context, filter model.SummaryFilter) (int, error) {
+	return f.pgRepository.GetSubscriptionsSummary(ctx, filter)
+}
+
+func (f *StorageFacade) GetSummaryBreakdown(ctx context.Context, filter model.SummaryFilter) ([]model.SummaryRow, error) {
+	return f.pgRepository.GetSubscriptionsSummaryBreakdown(ctx, filter)
+}
+
+func (f *StorageFacade) AddPriceChange(ctx context.Context, id uuid.UUID, change model.PriceChange) error {
+	return f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+		// The foreign key does not see soft deletes.
+		sub, err := f.pgRepository.GetSubscription(ctxTx, id)
+		if err != nil {
+			return err
+		}
+		if err := f.pgRepository.InsertPriceChange(ctxTx, id, change); err != nil {
+			return err
+		}
+		return f.recordEvent(ctxTx, model.EventPriceChange, *sub, nil, change)
+	})
+}
+
+func (f *StorageFacade) GetPriceHistory(ctx context.Context, id uuid.UUID) ([]model.PriceChange, error) {
+	return f.pgRepository.GetPriceChanges(ctx, id)
 }
 
-func (f *StorageFacade) Delete(ctx context.Context, userId uuid.UUID, serviceId string) error {
-	return f.pgRepository.DeleteSubscription(ctx, userId, serviceId)
+func (f *StorageFacade) AddPause(ctx context.Context, id uuid.UUID, pause model.Pause) error {
+	return f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+		sub, err := f.pgRepository.GetSubscription(ctxTx, id)
+		if err != nil {
+			return err
+		}
+		pauses, err := f.pgRepository.GetPauses(ctxTx, id)
+		if err != nil {
+			return err
+		}
+		if err := CheckPauseOverlap(pauses, pause); err != nil {
+			return err
+		}
+		if err := f.pgRepository.InsertPause(ctxTx, id, pause); err != nil {
+			return err
+		}
+		if err := f.pgRepository.IncrementVersion(ctxTx, id); err != nil {
+			return err
+		}
+		return f.recordEvent(ctxTx, model.EventPause, *sub, nil, pause)
+	})
 }
 
-func (f *StorageFacade) GetList(ctx context.Context, userId uuid.UUID) (*[]model.Subscription, error) {
-	return f.pgRepository.GetSubscriptionsList(ctx, &userId, nil)
+func (f *StorageFacade) Resume(ctx context.Context, id uuid.UUID, from time.Time) error {
+	return f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+		sub, err := f.pgRepository.GetSubscription(ctxTx, id)
+		if err != nil {
+			return err
+		}
+		pauses, err := f.pgRepository.GetPauses(ctxTx, id)
+		if err != nil {
+			return err
+		}
+		i, cancel, err := PauseToResume(pauses, from)
+		if err != nil {
+			return err
+		}
+		if err := f.pgRepository.IncrementVersion(ctxTx, id); err != nil {
+			return err
+		}
+		pause := pauses[i]
+		if cancel {
+			if err := f.pgRepository.DeletePause(ctxTx, id, pause.From); err != nil {
+				return err
+			}
+			return f.recordEvent(ctxTx, model.EventResume, *sub, pause, nil)
+		}
+		if err := f.pgRepository.UpdatePauseEnd(ctxTx, id, pause.From, from); err != nil {
+			return err
+		}
+		resumed := pause
+		resumed.ResumeFrom = &from
+		return f.recordEvent(ctxTx, model.EventResume, *sub, pause, resumed)
+	})
 }
 
-func (f *StorageFacade) GetSummary(ctx context.Context, from time.Time, to time.Time, userId *uuid.UUID, serviceId *string) (int, error) {
-	return f.pgRepository.GetSubscriptionsSummary(ctx, from, to, userId, serviceId)
+func (f *StorageFacade) GetPauses(ctx context.Context, id uuid.UUID) ([]model.Pause, error) {
+	return f.pgRepository.GetPauses(ctx, id)
+}
+
+func (f *StorageFacade) CreatePromotion(ctx context.Context, promo model.Promotion) error {
+	return f.pgRepository.InsertPromotion(ctx, promo)
+}
+
+func (f *StorageFacade) GetPromotion(ctx context.Context, code string) (*model.Promotion, error) {
+	return f.pgRepository.GetPromotion(ctx, code)
+}
+
+func (f *StorageFacade) ListPromotions(ctx context.Context) ([]model.Promotion, error) {
+	return f.pgRepository.GetPromotions(ctx)
+}
+
+func (f *StorageFacade) DeletePromotion(ctx context.Context, code string) error {
+	return f.pgRepository.DeletePromotion(ctx, code)
+}
+
+func (f *StorageFacade) ApplyPromotion(ctx context.Context, id uuid.UUID, applied model.AppliedPromotion) error {
+	return f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+		// The foreign key does not see soft deletes.
+		sub, err := f.pgRepository.GetSubscription(ctxTx, id)
+		if err != nil {
+			return err
+		}
+		existing, err := f.pgRepository.GetAppliedPromotions(ctxTx, id)
+		if err != nil {
+			return err
+		}
+		if err := CheckPromotionOverlap(existing, applied); err != nil {
+			return err
+		}
+		if err := f.pgRepository.InsertAppliedPromotion(ctxTx, id, applied); err != nil {
+			return err
+		}
+		return f.recordEvent(ctxTx, model.EventPromotion, *sub, nil, applied)
+	})
+}
+
+func (f *StorageFacade) GetAppliedPromotions(ctx context.Context, id uuid.UUID) ([]model.AppliedPromotion, error) {
+	return f.pgRepository.GetAppliedPromotions(ctx, id)
+}
+
+func (f *StorageFacade) RemovePromotion(ctx context.Context, id uuid.UUID, from time.Time) error {
+	return f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+		sub, err := f.pgRepository.GetSubscription(ctxTx, id)
+		if err != nil {
+			return err
+		}
+		applied, err := f.pgRepository.GetAppliedPromotions(ctxTx, id)
+		if err != nil {
+			return err
+		}
+		i, cancel, err := PromotionToRemove(applied, from)
+		if err != nil {
+			return err
+		}
+		before := applied[i]
+		if cancel {
+			if err := f.pgRepository.DeleteAppliedPromotion(ctxTx, id, before.AppliedFrom); err != nil {
+				return err
+			}
+			return f.recordEvent(ctxTx, model.EventPromotion, *sub, before, nil)
+		}
+		if err := f.pgRepository.UpdateAppliedPromotionEnd(ctxTx, id, before.AppliedFrom, from); err != nil {
+			return err
+		}
+		ended := before
+		ended.AppliedUntil = from
+		return f.recordEvent(ctxTx, model.EventPromotion, *sub, before, ended)
+	})
+}
+
+func (f *StorageFacade) CreateCatalogService(ctx context.Context, svc model.CatalogService) error {
+	return f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+		if err := f.checkCatalogKeys(ctxTx, svc); err != nil {
+			return err
+		}
+		if err := f.pgRepository.InsertCatalogService(ctxTx, svc); err != nil {
+			return err
+		}
+		return f.renameServiceSubscriptions(ctxTx, svc)
+	})
+}
+
+func (f *StorageFacade) UpdateCatalogService(ctx context.Context, svc model.CatalogService) error {
+	return f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+		if err := f.checkCatalogKeys(ctxTx, svc); err != nil {
+			return err
+		}
+		if err := f.pgRepository.UpdateCatalogService(ctxTx, svc); err != nil {
+			return err
+		}
+		return f.renameServiceSubscriptions(ctxTx, svc)
+	})
+}
+
+// renameServiceSubscriptions stores the subscriptions named by a key of svc
+// under its canonical name and records an update event for each of them.
+func (f *StorageFacade) renameServiceSubscriptions(ctx context.Context, svc model.CatalogService) error {
+	before, after, err := f.pgRepository.RenameServiceSubscriptions(ctx, CatalogKeys(svc), svc.Name)
+	if err != nil {
+		return err
+	}
+	for i := range after {
+		if err := f.recordEvent(ctx, model.EventUpdate, after[i], before[i], after[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *StorageFacade) checkCatalogKeys(ctx context.Context, svc model.CatalogService) error {
+	resolved, err := f.pgRepository.ResolveServiceNames(ctx, CatalogKeys(svc))
+	if err != nil {
+		return err
+	}
+	return CheckCatalogKeys(svc, resolved)
+}
+
+func (f *StorageFacade) DeleteCatalogService(ctx context.Context, name string) error {
+	return f.pgRepository.DeleteCatalogService(ctx, name)
+}
+
+func (f *StorageFacade) GetCatalogService(ctx context.Context, name string) (*model.CatalogService, error) {
+	return f.pgRepository.GetCatalogService(ctx, name)
+}
+
+func (f *StorageFacade) ListCatalogServices(ctx context.Context, category *string) ([]model.CatalogService, error) {
+	return f.pgRepository.GetCatalogServices(ctx, category)
+}
+
+func (f *StorageFacade) ResolveServiceNames(ctx context.Context, keys []string) (map[string]string, error) {
+	return f.pgRepository.ResolveServiceNames(ctx, keys)
+}
+
+func (f *StorageFacade) SaveExchangeRate(ctx context.Context, rate model.ExchangeRate) error {
+	return f.pgRepository.UpsertExchangeRate(ctx, rate)
+}
+
+func (f *StorageFacade) GetExchangeRates(ctx context.Context, fromCurrency, toCurrency *string) ([]model.ExchangeRate, error) {
+	return f.pgRepository.GetExchangeRates(ctx, fromCurrency, toCurrency)
+}
+
+func (f *StorageFacade) DeleteExchangeRate(ctx context.Context, fromCurrency, toCurrency string, validFrom time.Time) error {
+	return f.pgRepository.DeleteExchangeRate(ctx, fromCurrency, toCurrency, validFrom)
+}
+
+func (f *StorageFacade) SaveBudget(ctx context.Context, budget model.Budget) error {
+	return f.pgRepository.UpsertBudget(ctx, budget)
+}
+
+func (f *StorageFacade) ListBudgets(ctx context.Context, userId uuid.UUID) ([]model.Budget, error) {
+	return f.pgRepository.GetBudgets(ctx, userId)
+}
+
+func (f *StorageFacade) DeleteBudget(ctx context.Context, userId uuid.UUID, category string) error {
+	return f.pgRepository.DeleteBudget(ctx, userId, category)
+}
+
+func (f *StorageFacade) RecordBudgetAlerts(ctx context.Context, alerts []model.BudgetAlert) ([]model.BudgetAlert, error) {
+	var recorded []model.BudgetAlert
+	err := f.txManager.RunSerializable(ctx, func(ctxTx context.Context) error {
+		recorded = nil
+		for _, alert := range alerts {
+			isNew, err := f.pgRepository.InsertBudgetAlert(ctxTx, alert)
+			if err != nil {
+				return err
+			}
+			if !isNew {
+				continue
+			}
+			event, err := NewBudgetEvent(ctxTx, alert)
+			if err != nil {
+				return err
+			}
+			if err := f.pgRepository.InsertEvent(ctxTx, event); err != nil {
+				return err
+			}
+			recorded = append(recorded, alert)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return recorded, nil
+}
+
+func (f *StorageFacade) GetHistory(ctx context.Context, filter model.EventFilter) ([]model.SubscriptionEvent, error) {
+	return f.pgRepository.GetEvents(ctx, filter)
+}
+
+// recordEvent appends an audit event for sub; it must run inside the
+// transaction of the change it describes.
+func (f *StorageFacade) recordEvent(ctx context.Context, action string, sub model.Subscription, before, after interface{}) error {
+	event, err := NewEvent(ctx, action, sub, before, after)
+	if err != nil {
+		return err
+	}
+	return f.pgRepository.InsertEvent(ctx, event)
 }
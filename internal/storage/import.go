@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"time"
+)
+
+// errDryRun rolls back the transaction of a dry-run import.
+var errDryRun = errors.New("dry run")
+
+// ImportErrors explains why the rows of an import that were not inserted were
+// rejected: either an earlier row has the same subscription id, or a
+// subscription with that id already exists.
+func ImportErrors(subs []model.Subscription, inserted []bool) []error {
+	seenIds := make(map[uuid.UUID]bool, len(subs))
+	errs := make([]error, len(subs))
+	for i, sub := range subs {
+		seen := seenIds[sub.Id]
+		seenIds[sub.Id] = true
+		switch {
+		case inserted[i]:
+		case seen:
+			errs[i] = domain.Conflict("subscription is repeated in an earlier row")
+		default:
+			errs[i] = domain.Conflict("subscription with this id already exists")
+		}
+	}
+	return errs
+}
+
+// importKey identifies a row imported without an id.
+type importKey struct {
+	userId      uuid.UUID
+	serviceName string
+	startDate   time.Time
+}
+
+// ImportRows prepares the rows of an import given without an id (uuid.Nil),
+// which would otherwise be imported again on every re-import of the same
+// file. Those repeating a live subscription, as reported by live, or an
+// earlier row without an id by user, service and start date are rejected in
+// errs; the others get a new id. rows are the subscriptions left to insert
+// and indexes their positions in subs.
+func ImportRows(subs []model.Subscription, live []bool) (rows []model.Subscription, indexes []int, errs []error) {
+	seen := make(map[importKey]bool)
+	errs = make([]error, len(subs))
+	for i, sub := range subs {
+		if sub.Id == uuid.Nil {
+			key := importKey{sub.UserId, sub.ServiceName, sub.NormalizeDates().StartDate}
+			switch {
+			case live[i]:
+				errs[i] = domain.Conflict("subscription to %s starting %s already exists", sub.ServiceName, key.startDate.Format("2006-01-02"))
+				continue
+			case seen[key]:
+				errs[i] = domain.Conflict("subscription is repeated in an earlier row")
+				continue
+			}
+			seen[key] = true
+			sub.Id = uuid.New()
+		}
+		rows = append(rows, sub)
+		indexes = append(indexes, i)
+	}
+	return rows, indexes, errs
+}
+
+// ImportedSnapshot is the state of sub right after it was imported.
+func ImportedSnapshot(sub model.Subscription) model.Subscription {
+	sub = sub.NormalizeDates()
+	sub.Version = 1
+	sub.DeletedAt = nil
+	return sub
+}
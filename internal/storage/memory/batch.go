@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"subservice/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+// ApplyBatch applies the operations under one lock and restores the previous
+// state if any of them fails, like a rolled back transaction.
+func (s *Storage) ApplyBatch(ctx context.Context, ops []model.BatchOperation) ([]error, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subs := make(map[uuid.UUID]model.Subscription, len(s.subs))
+	for k, sub := range s.subs {
+		subs[k] = sub
+	}
+	prices := make(map[uuid.UUID][]model.PriceChange, len(s.prices))
+	for k, changes := range s.prices {
+		prices[k] = changes
+	}
+	pauses := make(map[uuid.UUID][]model.Pause, len(s.pauses))
+	for k, p := range s.pauses {
+		pauses[k] = p
+	}
+	applied := make(map[uuid.UUID][]model.AppliedPromotion, len(s.applied))
+	for k, a := range s.applied {
+		applied[k] = a
+	}
+	events := len(s.events)
+
+	errs := make([]error, len(ops))
+	for i, op := range ops {
+		if err := s.apply(ctx, op); err != nil {
+			s.subs, s.prices, s.pauses, s.applied, s.events = subs, prices, pauses, applied, s.events[:events]
+			storage.AbortBatch(errs, i, err)
+			return errs, nil
+		}
+	}
+	return errs, nil
+}
+
+// apply must be called with s.mu held for writing.
+func (s *Storage) apply(ctx context.Context, op model.BatchOperation) error {
+	switch op.Op {
+	case model.BatchCreate:
+		return s.insert(ctx, op.Subscription)
+	case model.BatchUpdate:
+		return s.update(ctx, op.Subscription)
+	case model.BatchDelete:
+		return s.softDelete(ctx, op.Subscription.Id, op.Subscription.Version)
+	default:
+		return domain.Validation("op", "unknown operation %q", op.Op)
+	}
+}
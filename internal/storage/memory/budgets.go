@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"subservice/internal/storage"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type budgetKey struct {
+	userId   uuid.UUID
+	category string
+}
+
+type budgetAlertKey struct {
+	budgetKey
+	month time.Time
+}
+
+func (s *Storage) SaveBudget(ctx context.Context, budget model.Budget) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.budgets[budgetKey{budget.UserId, budget.Category}] = budget
+	return nil
+}
+
+func (s *Storage) ListBudgets(ctx context.Context, userId uuid.UUID) ([]model.Budget, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	budgets := []model.Budget{}
+	for key, b := range s.budgets {
+		if key.userId == userId {
+			budgets = append(budgets, b)
+		}
+	}
+	sort.Slice(budgets, func(i, j int) bool { return budgets[i].Category < budgets[j].Category })
+	return budgets, nil
+}
+
+// DeleteBudget also forgets the budget's alerts, like the postgres cascade.
+func (s *Storage) DeleteBudget(ctx context.Context, userId uuid.UUID, category string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := budgetKey{userId, category}
+	if _, ok := s.budgets[key]; !ok {
+		return domain.NotFound("budget not found")
+	}
+	delete(s.budgets, key)
+	for alertKey := range s.alerts {
+		if alertKey.budgetKey == key {
+			delete(s.alerts, alertKey)
+		}
+	}
+	return nil
+}
+
+func (s *Storage) RecordBudgetAlerts(ctx context.Context, alerts []model.BudgetAlert) ([]model.BudgetAlert, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var recorded []model.BudgetAlert
+	for _, alert := range alerts {
+		key := budgetAlertKey{budgetKey{alert.UserId, alert.Category}, alert.Month}
+		if _, ok := s.alerts[key]; ok {
+			continue
+		}
+		event, err := storage.NewBudgetEvent(ctx, alert)
+		if err != nil {
+			return nil, err
+		}
+		s.alerts[key] = struct{}{}
+		s.appendEvent(event)
+		recorded = append(recorded, alert)
+	}
+	return recorded, nil
+}
@@ -0,0 +1,146 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"subservice/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+func (s *Storage) CreateCatalogService(ctx context.Context, svc model.CatalogService) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.catalog[svc.Name]; ok {
+		return domain.Conflict("service %s already exists", svc.Name)
+	}
+	if err := storage.CheckCatalogKeys(svc, s.resolve(storage.CatalogKeys(svc))); err != nil {
+		return err
+	}
+	if err := s.renameSubscriptions(ctx, svc); err != nil {
+		return err
+	}
+	s.catalog[svc.Name] = svc
+	return nil
+}
+
+func (s *Storage) UpdateCatalogService(ctx context.Context, svc model.CatalogService) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.catalog[svc.Name]; !ok {
+		return domain.NotFound("service not found")
+	}
+	if err := storage.CheckCatalogKeys(svc, s.resolve(storage.CatalogKeys(svc))); err != nil {
+		return err
+	}
+	if err := s.renameSubscriptions(ctx, svc); err != nil {
+		return err
+	}
+	s.catalog[svc.Name] = svc
+	return nil
+}
+
+func (s *Storage) DeleteCatalogService(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.catalog[name]; !ok {
+		return domain.NotFound("service not found")
+	}
+	delete(s.catalog, name)
+	return nil
+}
+
+func (s *Storage) GetCatalogService(ctx context.Context, name string) (*model.CatalogService, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	svc, ok := s.catalog[name]
+	if !ok {
+		return nil, domain.NotFound("service not found")
+	}
+	return &svc, nil
+}
+
+func (s *Storage) ListCatalogServices(ctx context.Context, category *string) ([]model.CatalogService, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	services := []model.CatalogService{}
+	for _, svc := range s.catalog {
+		if category == nil || svc.Category == *category {
+			services = append(services, svc)
+		}
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+	return services, nil
+}
+
+func (s *Storage) ResolveServiceNames(ctx context.Context, keys []string) (map[string]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.resolve(keys), nil
+}
+
+// renameSubscriptions stores the subscriptions named by a key of svc under its
+// canonical name and records an update event for each, like the postgres
+// RenameServiceSubscriptions. It must be called with s.mu held for writing.
+func (s *Storage) renameSubscriptions(ctx context.Context, svc model.CatalogService) error {
+	keys := make(map[string]bool)
+	for _, k := range storage.CatalogKeys(svc) {
+		keys[k] = true
+	}
+	var (
+		renamed []model.Subscription
+		events  []model.SubscriptionEvent
+	)
+	ids := make([]uuid.UUID, 0, len(s.subs))
+	for id := range s.subs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return bytes.Compare(ids[i][:], ids[j][:]) < 0 })
+	for _, id := range ids {
+		before := s.subs[id]
+		if before.ServiceName == svc.Name || !keys[model.ServiceKey(before.ServiceName)] {
+			continue
+		}
+		after := before
+		after.ServiceName = svc.Name
+		after.Version++
+		event, err := storage.NewEvent(ctx, model.EventUpdate, after, before, after)
+		if err != nil {
+			return err
+		}
+		renamed = append(renamed, after)
+		events = append(events, event)
+	}
+	for i, sub := range renamed {
+		s.subs[sub.Id] = sub
+		s.appendEvent(events[i])
+	}
+	return nil
+}
+
+// resolve must be called with s.mu held.
+func (s *Storage) resolve(keys []string) map[string]string {
+	wanted := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		wanted[k] = true
+	}
+
+	resolved := make(map[string]string, len(keys))
+	for _, svc := range s.catalog {
+		for _, k := range storage.CatalogKeys(svc) {
+			if wanted[k] {
+				resolved[k] = svc.Name
+			}
+		}
+	}
+	return resolved
+}
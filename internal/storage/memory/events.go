@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"context"
+	"subservice/internal/model"
+)
+
+// appendEvent assigns the next id to event and stores it. The caller must hold
+// the write lock, so the event is recorded atomically with its change.
+func (s *Storage) appendEvent(event model.SubscriptionEvent) {
+	event.Id = int64(len(s.events)) + 1
+	s.events = append(s.events, event)
+}
+
+func (s *Storage) GetHistory(ctx context.Context, filter model.EventFilter) ([]model.SubscriptionEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	events := []model.SubscriptionEvent{}
+	for i := len(s.events) - 1; i >= 0 && len(events) < filter.Limit; i-- {
+		if e := s.events[i]; matchesEvent(e, filter) {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+func matchesEvent(e model.SubscriptionEvent, filter model.EventFilter) bool {
+	if filter.SubscriptionId != nil && (e.SubscriptionId == nil || *e.SubscriptionId != *filter.SubscriptionId) {
+		return false
+	}
+	if filter.UserId != nil && e.UserId != *filter.UserId {
+		return false
+	}
+	if filter.ServiceName != nil && e.ServiceName != *filter.ServiceName {
+		return false
+	}
+	if filter.From != nil && e.CreatedAt.Before(*filter.From) {
+		return false
+	}
+	if filter.To != nil && e.CreatedAt.After(*filter.To) {
+		return false
+	}
+	if filter.BeforeId != nil && e.Id >= *filter.BeforeId {
+		return false
+	}
+	return true
+}
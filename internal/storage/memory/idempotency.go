@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"context"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// idempotencyKey scopes an Idempotency-Key to the user, like the primary key
+// of the postgres idempotency_keys table.
+type idempotencyKey struct {
+	userId uuid.UUID
+	key    string
+}
+
+func (s *Storage) InsertIdempotent(ctx context.Context, subUnit model.Subscription, rec model.IdempotencyKey) (*model.IdempotencyKey, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := idempotencyKey{userId: rec.UserId, key: rec.Key}
+	if existing, ok := s.keys[k]; ok && existing.ExpiresAt.After(time.Now()) {
+		if existing.RequestHash != rec.RequestHash {
+			return nil, false, domain.Unprocessable("idempotency key was already used with a different request")
+		}
+		return &existing, true, nil
+	}
+
+	if err := s.insert(ctx, subUnit); err != nil {
+		return nil, false, err
+	}
+	s.keys[k] = rec
+	return &rec, false, nil
+}
+
+func (s *Storage) PurgeIdempotencyKeys(ctx context.Context, expiredBefore time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged int64
+	for k, rec := range s.keys {
+		if !rec.ExpiresAt.After(expiredBefore) {
+			delete(s.keys, k)
+			purged++
+		}
+	}
+	return purged, nil
+}
@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"subservice/internal/model"
+	"subservice/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+func (s *Storage) Import(ctx context.Context, subs []model.Subscription, dryRun bool) ([]error, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	live := make([]bool, len(subs))
+	for i, sub := range subs {
+		live[i] = s.liveSubscriptionExists(sub)
+	}
+	rows, indexes, errs := storage.ImportRows(subs, live)
+
+	inserted := make([]bool, len(rows))
+	taken := make(map[uuid.UUID]bool, len(rows))
+	for i, sub := range rows {
+		if _, ok := s.subs[sub.Id]; ok || taken[sub.Id] {
+			continue
+		}
+		taken[sub.Id] = true
+		inserted[i] = true
+	}
+	for j, err := range storage.ImportErrors(rows, inserted) {
+		errs[indexes[j]] = err
+	}
+	if dryRun {
+		return errs, nil
+	}
+
+	for i, sub := range rows {
+		if !inserted[i] {
+			continue
+		}
+		if err := s.insert(ctx, sub); err != nil {
+			return nil, err
+		}
+	}
+	return errs, nil
+}
+
+// liveSubscriptionExists reports whether a live subscription has the user,
+// service and start date of sub, like the postgres LiveSubscriptionsExist. It
+// must be called with s.mu held.
+func (s *Storage) liveSubscriptionExists(sub model.Subscription) bool {
+	start := sub.NormalizeDates().StartDate
+	for _, existing := range s.subs {
+		if existing.DeletedAt == nil && existing.UserId == sub.UserId && existing.ServiceName == sub.ServiceName && existing.StartDate.Equal(start) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"subservice/internal/storage"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (s *Storage) AddPause(ctx context.Context, id uuid.UUID, pause model.Pause) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, ok := s.subs[id]
+	if !ok || sub.DeletedAt != nil {
+		return domain.NotFound("subscription not found")
+	}
+	pauses := s.pauses[id]
+	if err := storage.CheckPauseOverlap(pauses, pause); err != nil {
+		return err
+	}
+	event, err := storage.NewEvent(ctx, model.EventPause, sub, nil, pause)
+	if err != nil {
+		return err
+	}
+
+	// Copy so that batch snapshots sharing the old slice are not modified.
+	pauses = append(append([]model.Pause(nil), pauses...), pause)
+	sort.Slice(pauses, func(i, j int) bool { return pauses[i].From.Before(pauses[j].From) })
+	sub.Version++
+	s.subs[id] = sub
+	s.pauses[id] = pauses
+	s.appendEvent(event)
+	return nil
+}
+
+func (s *Storage) Resume(ctx context.Context, id uuid.UUID, from time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, ok := s.subs[id]
+	if !ok || sub.DeletedAt != nil {
+		return domain.NotFound("subscription not found")
+	}
+	pauses := append([]model.Pause(nil), s.pauses[id]...)
+	i, cancel, err := storage.PauseToResume(pauses, from)
+	if err != nil {
+		return err
+	}
+
+	pause := pauses[i]
+	var event model.SubscriptionEvent
+	if cancel {
+		event, err = storage.NewEvent(ctx, model.EventResume, sub, pause, nil)
+		pauses = append(pauses[:i], pauses[i+1:]...)
+	} else {
+		resumed := pause
+		resumed.ResumeFrom = &from
+		event, err = storage.NewEvent(ctx, model.EventResume, sub, pause, resumed)
+		pauses[i] = resumed
+	}
+	if err != nil {
+		return err
+	}
+	sub.Version++
+	s.subs[id] = sub
+	s.pauses[id] = pauses
+	s.appendEvent(event)
+	return nil
+}
+
+func (s *Storage) GetPauses(ctx context.Context, id uuid.UUID) ([]model.Pause, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pauses := make([]model.Pause, len(s.pauses[id]))
+	copy(pauses, s.pauses[id])
+	return pauses, nil
+}
@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"subservice/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+func (s *Storage) AddPriceChange(ctx context.Context, id uuid.UUID, change model.PriceChange) error {
+	change.EffectiveFrom = model.FirstOfMonth(change.EffectiveFrom)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, ok := s.subs[id]
+	if !ok || sub.DeletedAt != nil {
+		return domain.NotFound("subscription not found")
+	}
+	event, err := storage.NewEvent(ctx, model.EventPriceChange, sub, nil, change)
+	if err != nil {
+		return err
+	}
+	s.appendEvent(event)
+	s.setPrice(id, change)
+	return nil
+}
+
+// setPrice adds change to the price history of subscription id, replacing the
+// change effective in the same month. It must be called with s.mu held for
+// writing.
+func (s *Storage) setPrice(id uuid.UUID, change model.PriceChange) {
+	// Copy so that batch snapshots sharing the old slice are not modified.
+	changes := make([]model.PriceChange, 0, len(s.prices[id])+1)
+	for _, c := range s.prices[id] {
+		if !c.EffectiveFrom.Equal(change.EffectiveFrom) {
+			changes = append(changes, c)
+		}
+	}
+	changes = append(changes, change)
+	sort.Slice(changes, func(i, j int) bool { return changes[i].EffectiveFrom.Before(changes[j].EffectiveFrom) })
+	s.prices[id] = changes
+}
+
+func (s *Storage) GetPriceHistory(ctx context.Context, id uuid.UUID) ([]model.PriceChange, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	changes := make([]model.PriceChange, len(s.prices[id]))
+	copy(changes, s.prices[id])
+	return changes, nil
+}
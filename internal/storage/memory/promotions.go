@@ -0,0 +1,145 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"subservice/internal/storage"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (s *Storage) CreatePromotion(ctx context.Context, promo model.Promotion) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.promos[promo.Code]; ok {
+		return domain.Conflict("promotion %s already exists", promo.Code)
+	}
+	s.promos[promo.Code] = promo
+	return nil
+}
+
+func (s *Storage) GetPromotion(ctx context.Context, code string) (*model.Promotion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	promo, ok := s.promos[code]
+	if !ok {
+		return nil, domain.NotFound("promotion not found")
+	}
+	return &promo, nil
+}
+
+func (s *Storage) ListPromotions(ctx context.Context) ([]model.Promotion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	promos := make([]model.Promotion, 0, len(s.promos))
+	for _, promo := range s.promos {
+		promos = append(promos, promo)
+	}
+	sort.Slice(promos, func(i, j int) bool { return promos[i].Code < promos[j].Code })
+	return promos, nil
+}
+
+func (s *Storage) DeletePromotion(ctx context.Context, code string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.promos[code]; !ok {
+		return domain.NotFound("promotion not found")
+	}
+	for _, applied := range s.applied {
+		for _, a := range applied {
+			if a.Code == code {
+				return domain.Conflict("promotion %s is applied to subscriptions", code)
+			}
+		}
+	}
+	delete(s.promos, code)
+	return nil
+}
+
+func (s *Storage) ApplyPromotion(ctx context.Context, id uuid.UUID, applied model.AppliedPromotion) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, ok := s.subs[id]
+	if !ok || sub.DeletedAt != nil {
+		return domain.NotFound("subscription not found")
+	}
+	if _, ok := s.promos[applied.Code]; !ok {
+		return domain.NotFound("subscription or promotion not found")
+	}
+	if err := storage.CheckPromotionOverlap(s.applied[id], applied); err != nil {
+		return err
+	}
+	event, err := storage.NewEvent(ctx, model.EventPromotion, sub, nil, applied)
+	if err != nil {
+		return err
+	}
+	existing := append([]model.AppliedPromotion(nil), s.applied[id]...)
+	existing = append(existing, applied)
+	sort.Slice(existing, func(i, j int) bool { return existing[i].AppliedFrom.Before(existing[j].AppliedFrom) })
+	s.applied[id] = existing
+	s.appendEvent(event)
+	return nil
+}
+
+func (s *Storage) GetAppliedPromotions(ctx context.Context, id uuid.UUID) ([]model.AppliedPromotion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	applied := make([]model.AppliedPromotion, len(s.applied[id]))
+	copy(applied, s.applied[id])
+	return applied, nil
+}
+
+func (s *Storage) RemovePromotion(ctx context.Context, id uuid.UUID, from time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, ok := s.subs[id]
+	if !ok || sub.DeletedAt != nil {
+		return domain.NotFound("subscription not found")
+	}
+	applied := append([]model.AppliedPromotion(nil), s.applied[id]...)
+	i, cancel, err := storage.PromotionToRemove(applied, from)
+	if err != nil {
+		return err
+	}
+
+	before := applied[i]
+	var event model.SubscriptionEvent
+	if cancel {
+		event, err = storage.NewEvent(ctx, model.EventPromotion, sub, before, nil)
+		applied = append(applied[:i], applied[i+1:]...)
+	} else {
+		ended := before
+		ended.AppliedUntil = from
+		event, err = storage.NewEvent(ctx, model.EventPromotion, sub, before, ended)
+		applied[i] = ended
+	}
+	if err != nil {
+		return err
+	}
+	s.applied[id] = applied
+	s.appendEvent(event)
+	return nil
+}
+
+// discountedPrice applies the promotion of subscription id covering the
+// billing cycle that contains month m to price. It must be called with s.mu
+// held.
+func (s *Storage) discountedPrice(id uuid.UUID, sub model.Subscription, m time.Time, price int64) float64 {
+	cycle := sub.CycleStart(m)
+	for _, a := range s.applied[id] {
+		if a.Covers(cycle) {
+			return s.promos[a.Code].Discount(float64(price))
+		}
+	}
+	return float64(price)
+}
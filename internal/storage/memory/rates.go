@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"time"
+)
+
+type currencyPair struct {
+	from string
+	to   string
+}
+
+func (s *Storage) SaveExchangeRate(ctx context.Context, rate model.ExchangeRate) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pair := currencyPair{rate.FromCurrency, rate.ToCurrency}
+	rates := s.rates[pair]
+	for i, r := range rates {
+		if r.ValidFrom.Equal(rate.ValidFrom) {
+			rates[i] = rate
+			return nil
+		}
+	}
+	rates = append(rates, rate)
+	sort.Slice(rates, func(i, j int) bool { return rates[i].ValidFrom.Before(rates[j].ValidFrom) })
+	s.rates[pair] = rates
+	return nil
+}
+
+func (s *Storage) GetExchangeRates(ctx context.Context, fromCurrency, toCurrency *string) ([]model.ExchangeRate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rates := []model.ExchangeRate{}
+	for pair, pairRates := range s.rates {
+		if fromCurrency != nil && pair.from != *fromCurrency {
+			continue
+		}
+		if toCurrency != nil && pair.to != *toCurrency {
+			continue
+		}
+		rates = append(rates, pairRates...)
+	}
+	sort.Slice(rates, func(i, j int) bool {
+		a, b := rates[i], rates[j]
+		if a.FromCurrency != b.FromCurrency {
+			return a.FromCurrency < b.FromCurrency
+		}
+		if a.ToCurrency != b.ToCurrency {
+			return a.ToCurrency < b.ToCurrency
+		}
+		return a.ValidFrom.Before(b.ValidFrom)
+	})
+	return rates, nil
+}
+
+func (s *Storage) DeleteExchangeRate(ctx context.Context, fromCurrency, toCurrency string, validFrom time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pair := currencyPair{fromCurrency, toCurrency}
+	rates := s.rates[pair]
+	for i, r := range rates {
+		if r.ValidFrom.Equal(validFrom) {
+			s.rates[pair] = append(rates[:i], rates[i+1:]...)
+			return nil
+		}
+	}
+	return domain.NotFound("exchange rate not found")
+}
+
+// rateAt returns the latest rate for the pair valid at m. It must be called
+// with s.mu held.
+func (s *Storage) rateAt(from, to string, m time.Time) (float64, bool) {
+	rate, ok := 0.0, false
+	for _, r := range s.rates[currencyPair{from, to}] {
+		if r.ValidFrom.After(m) {
+			break
+		}
+		rate, ok = r.Rate, true
+	}
+	return rate, ok
+}
@@ -0,0 +1,335 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"strings"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"subservice/internal/storage"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var _ storage.Facade = (*Storage)(nil)
+
+// Storage is a concurrency-safe in-memory storage.Facade. It mirrors the
+// date normalisation and summary math of the postgres repository.
+type Storage struct {
+	mu      sync.RWMutex
+	subs    map[uuid.UUID]model.Subscription
+	prices  map[uuid.UUID][]model.PriceChange
+	pauses  map[uuid.UUID][]model.Pause
+	promos  map[string]model.Promotion
+	applied map[uuid.UUID][]model.AppliedPromotion
+	catalog map[string]model.CatalogService
+	rates   map[currencyPair][]model.ExchangeRate
+	budgets map[budgetKey]model.Budget
+	alerts  map[budgetAlertKey]struct{}
+	events  []model.SubscriptionEvent
+	keys    map[idempotencyKey]model.IdempotencyKey
+}
+
+func NewStorage() *Storage {
+	return &Storage{
+		subs:    make(map[uuid.UUID]model.Subscription),
+		prices:  make(map[uuid.UUID][]model.PriceChange),
+		pauses:  make(map[uuid.UUID][]model.Pause),
+		promos:  make(map[string]model.Promotion),
+		applied: make(map[uuid.UUID][]model.AppliedPromotion),
+		catalog: make(map[string]model.CatalogService),
+		rates:   make(map[currencyPair][]model.ExchangeRate),
+		budgets: make(map[budgetKey]model.Budget),
+		alerts:  make(map[budgetAlertKey]struct{}),
+		keys:    make(map[idempotencyKey]model.IdempotencyKey),
+	}
+}
+
+func (s *Storage) Insert(ctx context.Context, subUnit model.Subscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.insert(ctx, subUnit)
+}
+
+// insert must be called with s.mu held for writing.
+func (s *Storage) insert(ctx context.Context, subUnit model.Subscription) error {
+	subUnit = subUnit.NormalizeDates()
+
+	if _, ok := s.subs[subUnit.Id]; ok {
+		return domain.Conflict("subscription with this id already exists")
+	}
+	subUnit.Version = 1
+	subUnit.DeletedAt = nil
+	event, err := storage.NewEvent(ctx, model.EventCreate, subUnit, nil, subUnit)
+	if err != nil {
+		return err
+	}
+	s.subs[subUnit.Id] = subUnit
+	s.appendEvent(event)
+	return nil
+}
+
+func (s *Storage) Get(ctx context.Context, id uuid.UUID) (*model.Subscription, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sub, ok := s.subs[id]
+	if !ok || sub.DeletedAt != nil {
+		return nil, domain.NotFound("subscription not found")
+	}
+	return &sub, nil
+}
+
+func (s *Storage) Update(ctx context.Context, subUnit model.Subscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.update(ctx, subUnit)
+}
+
+// update must be called with s.mu held for writing.
+func (s *Storage) update(ctx context.Context, subUnit model.Subscription) error {
+	subUnit = subUnit.NormalizeDates()
+
+	before, ok := s.subs[subUnit.Id]
+	if !ok || before.DeletedAt != nil {
+		return domain.NotFound("subscription not found")
+	}
+	if err := storage.CheckVersion(&before, subUnit.Version); err != nil {
+		return err
+	}
+	if err := storage.CheckIdentity(&before, subUnit); err != nil {
+		return err
+	}
+	changes, err := storage.PriceChangesOnUpdate(&before, s.prices[subUnit.Id], subUnit, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	subUnit.Version = before.Version + 1
+	event, err := storage.NewEvent(ctx, model.EventUpdate, subUnit, before, subUnit)
+	if err != nil {
+		return err
+	}
+	for _, change := range changes {
+		s.setPrice(subUnit.Id, change)
+	}
+	s.subs[subUnit.Id] = subUnit
+	s.appendEvent(event)
+	return nil
+}
+
+func (s *Storage) Delete(ctx context.Context, id uuid.UUID, version int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.softDelete(ctx, id, version)
+}
+
+// softDelete must be called with s.mu held for writing.
+func (s *Storage) softDelete(ctx context.Context, id uuid.UUID, version int64) error {
+	before, ok := s.subs[id]
+	if !ok || before.DeletedAt != nil {
+		return domain.NotFound("subscription not found")
+	}
+	if err := storage.CheckVersion(&before, version); err != nil {
+		return err
+	}
+	event, err := storage.NewEvent(ctx, model.EventDelete, before, before, nil)
+	if err != nil {
+		return err
+	}
+	deleted := before
+	now := time.Now().UTC()
+	deleted.DeletedAt = &now
+	deleted.Version++
+	s.subs[id] = deleted
+	s.appendEvent(event)
+	return nil
+}
+
+func (s *Storage) Restore(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, ok := s.subs[id]
+	if !ok || sub.DeletedAt == nil {
+		return domain.NotFound("deleted subscription not found")
+	}
+	sub.DeletedAt = nil
+	sub.Version++
+	event, err := storage.NewEvent(ctx, model.EventRestore, sub, nil, sub)
+	if err != nil {
+		return err
+	}
+	s.subs[id] = sub
+	s.appendEvent(event)
+	return nil
+}
+
+func (s *Storage) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged int64
+	for id, sub := range s.subs {
+		if sub.DeletedAt != nil && sub.DeletedAt.Before(deletedBefore) {
+			delete(s.subs, id)
+			delete(s.prices, id)
+			delete(s.pauses, id)
+			delete(s.applied, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+func (s *Storage) GetList(ctx context.Context, filter model.ListFilter) (*model.SubscriptionPage, error) {
+	s.mu.RLock()
+	subs := s.list(filter)
+	s.mu.RUnlock()
+
+	page := &model.SubscriptionPage{Items: subs}
+	if len(subs) > filter.Limit {
+		page.Items = subs[:filter.Limit]
+		page.NextCursor = model.CursorFor(page.Items[filter.Limit-1], filter.SortBy, filter.Desc).Encode()
+	}
+	return page, nil
+}
+
+// Export calls fn on a snapshot, so a slow consumer does not hold the lock.
+func (s *Storage) Export(ctx context.Context, filter model.ListFilter, fn func(model.Subscription) error) error {
+	s.mu.RLock()
+	subs := s.list(filter)
+	s.mu.RUnlock()
+
+	if filter.Limit > 0 && len(subs) > filter.Limit {
+		subs = subs[:filter.Limit]
+	}
+	for _, sub := range subs {
+		if err := fn(sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// list returns the subscriptions matching filter in listing order. It must be
+// called with s.mu held.
+func (s *Storage) list(filter model.ListFilter) []model.Subscription {
+	subs := []model.Subscription{}
+	for _, sub := range s.subs {
+		if matchesFilter(sub, filter) {
+			subs = append(subs, sub)
+		}
+	}
+	sort.Slice(subs, func(i, j int) bool {
+		return compareKeys(subs[i], model.CursorFor(subs[j], filter.SortBy, filter.Desc), filter) < 0
+	})
+	return subs
+}
+
+func matchesFilter(sub model.Subscription, filter model.ListFilter) bool {
+	if sub.DeletedAt != nil && !filter.IncludeDeleted {
+		return false
+	}
+	if filter.UserId != nil && sub.UserId != *filter.UserId && !sub.HasMember(*filter.UserId) {
+		return false
+	}
+	if filter.ServiceName != nil && sub.ServiceName != *filter.ServiceName {
+		return false
+	}
+	if filter.ServiceNamePrefix != nil && !strings.HasPrefix(sub.ServiceName, *filter.ServiceNamePrefix) {
+		return false
+	}
+	if filter.Tag != nil && !sub.HasTag(*filter.Tag) {
+		return false
+	}
+	if filter.ActiveAt != nil && !activeAt(sub, toDate(*filter.ActiveAt)) {
+		return false
+	}
+	if filter.MinPrice != nil && sub.Price < *filter.MinPrice {
+		return false
+	}
+	if filter.MaxPrice != nil && sub.Price > *filter.MaxPrice {
+		return false
+	}
+	if filter.After != nil && compareKeys(sub, *filter.After, filter) <= 0 {
+		return false
+	}
+	return true
+}
+
+// compareKeys orders sub against the cursor position the same way the postgres
+// listing does: by the sort column, then by (user_id, service_name, id). The
+// result is negated for descending order, so a positive value always means
+// "after".
+func compareKeys(sub model.Subscription, c model.Cursor, filter model.ListFilter) int {
+	var res int
+	switch filter.SortBy {
+	case model.SortByPrice:
+		res = compareInt64(sub.Price, c.Price)
+	case model.SortByStartDate:
+		res = sub.StartDate.Compare(c.StartDate)
+	default:
+		res = strings.Compare(sub.ServiceName, c.ServiceName)
+	}
+	if res == 0 {
+		res = bytes.Compare(sub.UserId[:], c.UserId[:])
+	}
+	if res == 0 {
+		res = strings.Compare(sub.ServiceName, c.ServiceName)
+	}
+	if res == 0 {
+		res = bytes.Compare(sub.Id[:], c.Id[:])
+	}
+	if filter.Desc {
+		res = -res
+	}
+	return res
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// activeAt matches the generate_series join condition of the postgres summary.
+func activeAt(sub model.Subscription, m time.Time) bool {
+	return !m.Before(model.FirstOfMonth(sub.StartDate)) && (sub.EndDate == nil || !m.After(*sub.EndDate))
+}
+
+// monthSeries reproduces generate_series(from::date, to::date, interval '1 month'):
+// each step adds a month to the previous value, clamping to the month's last day.
+func monthSeries(from, to time.Time) []time.Time {
+	start := toDate(from)
+	end := toDate(to)
+
+	var series []time.Time
+	for m := start; !m.After(end); m = addMonth(m) {
+		series = append(series, m)
+	}
+	return series
+}
+
+func addMonth(t time.Time) time.Time {
+	y, mon, d := t.Date()
+	next := time.Date(y, mon+1, 1, 0, 0, 0, 0, time.UTC)
+	if last := next.AddDate(0, 1, -1).Day(); d > last {
+		d = last
+	}
+	return time.Date(next.Year(), next.Month(), d, 0, 0, 0, 0, time.UTC)
+}
+
+func toDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,284 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// charge is one active month of a subscription paid by userId, like a row of
+// the postgres monthlyChargesQuery.
+type charge struct {
+	id       uuid.UUID
+	userId   uuid.UUID
+	currency string
+	month    time.Time
+	amount   int64
+	trial    bool
+}
+
+// monthlyCharges must be called with s.mu held.
+func (s *Storage) monthlyCharges(filter model.SummaryFilter) ([]charge, error) {
+	var charges []charge
+	for _, m := range monthSeries(filter.From, filter.To) {
+		for id, sub := range s.subs {
+			if !s.matchesSummary(sub, filter) || !activeAt(sub, m) || model.Paused(s.pauses[id], m) {
+				continue
+			}
+			price := s.priceAt(id, sub, m)
+			payers := summaryPayers(sub, price, filter)
+			if sub.InTrial(m) {
+				for _, p := range payers {
+					if countsFor(p, filter) {
+						charges = append(charges, charge{id: id, userId: p.UserId, currency: sub.Currency, month: m, trial: true})
+					}
+				}
+				continue
+			}
+			amount := chargeAmount(sub, s.discountedPrice(id, sub, m, price), m, filter.Amortize)
+			if filter.TargetCurrency != nil && sub.Currency != *filter.TargetCurrency {
+				rate, ok := s.rateAt(sub.Currency, *filter.TargetCurrency, m)
+				if !ok {
+					return nil, domain.Validation("target_currency", "no exchange rate to %s for %s", *filter.TargetCurrency, m.Format("2006-01"))
+				}
+				amount *= rate
+			}
+			for i, amount := range model.SplitAmount(amount, payers) {
+				if countsFor(payers[i], filter) {
+					charges = append(charges, charge{id: id, userId: payers[i].UserId, currency: sub.Currency, month: m, amount: amount})
+				}
+			}
+		}
+	}
+	if filter.TargetCurrency == nil {
+		for _, c := range charges {
+			if c.currency != charges[0].currency {
+				return nil, domain.Validation("target_currency", "subscriptions are priced in several currencies, target_currency is required")
+			}
+		}
+	}
+	return charges, nil
+}
+
+// summaryPayers returns the payers of sub the summary divides charges between:
+// with shares split all of them, otherwise the owner at the full price.
+func summaryPayers(sub model.Subscription, price int64, filter model.SummaryFilter) []model.Payer {
+	if !filter.SplitShares() {
+		return []model.Payer{{UserId: sub.UserId, Fraction: 1}}
+	}
+	return sub.Payers(price)
+}
+
+// countsFor reports whether the summary counts the share of p: with a user set
+// only that user's share counts.
+func countsFor(p model.Payer, filter model.SummaryFilter) bool {
+	return filter.UserId == nil || p.UserId == *filter.UserId
+}
+
+// priceAt returns the latest price change effective at m, or the base price.
+func (s *Storage) priceAt(id uuid.UUID, sub model.Subscription, m time.Time) int64 {
+	price := sub.Price
+	for _, c := range s.prices[id] {
+		if c.EffectiveFrom.After(m) {
+			break
+		}
+		price = c.Price
+	}
+	return price
+}
+
+// chargeAmount mirrors the billing CASE of the postgres monthlyChargesQuery
+// for the price of a billing cycle. The result is rounded by the caller after
+// currency conversion.
+func chargeAmount(sub model.Subscription, price float64, m time.Time, amortize bool) float64 {
+	if sub.BillingPeriod == model.BillingWeek {
+		monthStart := model.FirstOfMonth(m)
+		monthEnd := monthStart.AddDate(0, 1, 0)
+		cycleDays := int64(sub.CycleDays())
+		if amortize {
+			return price * float64(daysBetween(monthStart, monthEnd)) / float64(cycleDays)
+		}
+		charges := ceilDiv(daysBetween(sub.StartDate, monthEnd), cycleDays)
+		if before := daysBetween(sub.StartDate, monthStart); before > 0 {
+			charges -= ceilDiv(before, cycleDays)
+		}
+		return price * float64(charges)
+	}
+
+	cycleMonths := int64(sub.CycleMonths())
+	if amortize {
+		return price / float64(cycleMonths)
+	}
+	monthsSince := int64(m.Year()*12+int(m.Month())) - int64(sub.StartDate.Year()*12+int(sub.StartDate.Month()))
+	if monthsSince%cycleMonths == 0 {
+		return price
+	}
+	return 0
+}
+
+func daysBetween(from, to time.Time) int64 {
+	return int64(toDate(to).Sub(toDate(from)).Hours() / 24)
+}
+
+// ceilDiv expects a non-negative a and a positive b.
+func ceilDiv(a, b int64) int64 {
+	return (a + b - 1) / b
+}
+
+func (s *Storage) GetSummary(ctx context.Context, filter model.SummaryFilter) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	charges, err := s.monthlyCharges(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, c := range charges {
+		total += int(c.amount)
+	}
+	return total, nil
+}
+
+func (s *Storage) GetSummaryBreakdown(ctx context.Context, filter model.SummaryFilter) ([]model.SummaryRow, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	type group struct {
+		month       time.Time
+		serviceName string
+		userId      uuid.UUID
+		tag         string
+	}
+	totals := make(map[group]int64)
+	trials := make(map[group]int)
+	active := make(map[group]map[uuid.UUID]struct{})
+
+	charges, err := s.monthlyCharges(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range charges {
+		sub := s.subs[c.id]
+		var g group
+		if filter.GroupByMonth {
+			g.month = c.month
+		}
+		if filter.GroupByService {
+			g.serviceName = sub.ServiceName
+		}
+		if filter.GroupByUser {
+			g.userId = c.userId
+		}
+		// As in the postgres join, a charge counts once per tag and untagged
+		// subscriptions form the group with an empty tag.
+		tags := []string{""}
+		if filter.GroupByTag && len(sub.Tags) > 0 {
+			tags = sub.Tags
+		}
+		for _, tag := range tags {
+			g.tag = tag
+			totals[g] += c.amount
+			if c.trial {
+				trials[g]++
+			}
+			if active[g] == nil {
+				active[g] = make(map[uuid.UUID]struct{})
+			}
+			active[g][c.id] = struct{}{}
+		}
+	}
+
+	// As in the postgres query, grouped by month every month of the period
+	// gets a row; a month without charges has only its month set.
+	empty := make(map[group]bool)
+	if filter.GroupByMonth {
+		charged := make(map[time.Time]bool)
+		for g := range totals {
+			charged[g.month] = true
+		}
+		for _, m := range monthSeries(filter.From, filter.To) {
+			if !charged[m] {
+				g := group{month: m}
+				totals[g] = 0
+				empty[g] = true
+			}
+		}
+	}
+
+	groups := make([]group, 0, len(totals))
+	for g := range totals {
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		a, b := groups[i], groups[j]
+		if !a.month.Equal(b.month) {
+			return a.month.Before(b.month)
+		}
+		if a.serviceName != b.serviceName {
+			return a.serviceName < b.serviceName
+		}
+		if a.userId != b.userId {
+			return bytes.Compare(a.userId[:], b.userId[:]) < 0
+		}
+		// Postgres sorts the NULL tag of untagged subscriptions last.
+		if a.tag == "" || b.tag == "" {
+			return b.tag == "" && a.tag != ""
+		}
+		return a.tag < b.tag
+	})
+
+	breakdown := make([]model.SummaryRow, 0, len(groups))
+	for _, g := range groups {
+		row := model.SummaryRow{Total: totals[g], ActiveCount: len(active[g]), TrialMonths: trials[g]}
+		if filter.GroupByMonth {
+			month := g.month
+			row.Month = &month
+		}
+		if empty[g] {
+			breakdown = append(breakdown, row)
+			continue
+		}
+		if filter.GroupByService {
+			serviceName := g.serviceName
+			row.ServiceName = &serviceName
+		}
+		if filter.GroupByUser {
+			userId := g.userId
+			row.UserId = &userId
+		}
+		if g.tag != "" {
+			tag := g.tag
+			row.Tag = &tag
+		}
+		breakdown = append(breakdown, row)
+	}
+	return breakdown, nil
+}
+
+// matchesSummary must be called with s.mu held.
+func (s *Storage) matchesSummary(sub model.Subscription, filter model.SummaryFilter) bool {
+	if sub.DeletedAt != nil {
+		return false
+	}
+	if filter.ServiceName != nil && sub.ServiceName != *filter.ServiceName {
+		return false
+	}
+	if filter.Tag != nil && !sub.HasTag(*filter.Tag) {
+		return false
+	}
+	if filter.Category != nil {
+		svc, ok := s.catalog[sub.ServiceName]
+		if !ok || svc.Category != *filter.Category {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,417 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"subservice/internal/testutil"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestGetSummary(t *testing.T) {
+	ctx := context.Background()
+	first := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	second := uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	str := func(s string) *string { return &s }
+
+	tests := []struct {
+		name    string
+		setup   func(s *Storage) error
+		filter  model.SummaryFilter
+		want    int
+		wantErr error
+	}{
+		{
+			name:  "monthly",
+			setup: func(s *Storage) error { return s.Insert(ctx, testutil.Monthly(first, 100)) },
+			want:  600,
+		},
+		{
+			name: "start date is truncated to the month",
+			setup: func(s *Storage) error {
+				sub := testutil.Monthly(first, 100)
+				sub.StartDate = time.Date(2024, time.March, 20, 12, 0, 0, 0, time.UTC)
+				return s.Insert(ctx, sub)
+			},
+			want: 400,
+		},
+		{
+			name: "end date month is charged",
+			setup: func(s *Storage) error {
+				sub := testutil.Monthly(first, 100)
+				sub.EndDate = testutil.PtrTime(testutil.Month(2024, time.March))
+				return s.Insert(ctx, sub)
+			},
+			want: 300,
+		},
+		{
+			name: "price history",
+			setup: func(s *Storage) error {
+				if err := s.Insert(ctx, testutil.Monthly(first, 100)); err != nil {
+					return err
+				}
+				return s.AddPriceChange(ctx, first, model.PriceChange{EffectiveFrom: testutil.Month(2024, time.April), Price: 150})
+			},
+			want: 750,
+		},
+		{
+			name: "quarterly charged on renewal",
+			setup: func(s *Storage) error {
+				sub := testutil.Monthly(first, 300)
+				sub.StartDate = testutil.Month(2024, time.February)
+				sub.BillingPeriod = model.BillingQuarter
+				return s.Insert(ctx, sub)
+			},
+			want: 600,
+		},
+		{
+			name: "quarterly amortized",
+			setup: func(s *Storage) error {
+				sub := testutil.Monthly(first, 300)
+				sub.StartDate = testutil.Month(2024, time.February)
+				sub.BillingPeriod = model.BillingQuarter
+				return s.Insert(ctx, sub)
+			},
+			filter: model.SummaryFilter{Amortize: true},
+			want:   500,
+		},
+		{
+			name: "every two months",
+			setup: func(s *Storage) error {
+				sub := testutil.Monthly(first, 100)
+				sub.BillingInterval = 2
+				return s.Insert(ctx, sub)
+			},
+			want: 300,
+		},
+		{
+			name: "yearly amortized",
+			setup: func(s *Storage) error {
+				sub := testutil.Monthly(first, 1200)
+				sub.BillingPeriod = model.BillingYear
+				return s.Insert(ctx, sub)
+			},
+			filter: model.SummaryFilter{Amortize: true},
+			want:   600,
+		},
+		{
+			name: "weekly charged every week",
+			setup: func(s *Storage) error {
+				sub := testutil.Monthly(first, 10)
+				sub.BillingPeriod = model.BillingWeek
+				return s.Insert(ctx, sub)
+			},
+			filter: model.SummaryFilter{To: testutil.Month(2024, time.January)},
+			want:   50,
+		},
+		{
+			name: "weekly amortized",
+			setup: func(s *Storage) error {
+				sub := testutil.Monthly(first, 10)
+				sub.BillingPeriod = model.BillingWeek
+				return s.Insert(ctx, sub)
+			},
+			filter: model.SummaryFilter{To: testutil.Month(2024, time.January), Amortize: true},
+			want:   44,
+		},
+		{
+			name: "several currencies without target",
+			setup: func(s *Storage) error {
+				usd := testutil.Monthly(second, 10)
+				usd.Currency = "USD"
+				return insertAll(ctx, s, testutil.Monthly(first, 100), usd)
+			},
+			wantErr: domain.ErrValidation,
+		},
+		{
+			name: "converted to target currency",
+			setup: func(s *Storage) error {
+				usd := testutil.Monthly(second, 10)
+				usd.Currency = "USD"
+				if err := insertAll(ctx, s, testutil.Monthly(first, 100), usd); err != nil {
+					return err
+				}
+				if err := s.SaveExchangeRate(ctx, model.ExchangeRate{FromCurrency: "USD", ToCurrency: "RUB", ValidFrom: testutil.Month(2023, time.December), Rate: 90}); err != nil {
+					return err
+				}
+				return s.SaveExchangeRate(ctx, model.ExchangeRate{FromCurrency: "USD", ToCurrency: "RUB", ValidFrom: testutil.Month(2024, time.April), Rate: 100})
+			},
+			filter: model.SummaryFilter{TargetCurrency: str("RUB")},
+			want:   600 + 3*900 + 3*1000,
+		},
+		{
+			name: "no exchange rate for a month",
+			setup: func(s *Storage) error {
+				usd := testutil.Monthly(first, 10)
+				usd.Currency = "USD"
+				if err := s.Insert(ctx, usd); err != nil {
+					return err
+				}
+				return s.SaveExchangeRate(ctx, model.ExchangeRate{FromCurrency: "USD", ToCurrency: "RUB", ValidFrom: testutil.Month(2024, time.April), Rate: 90})
+			},
+			filter:  model.SummaryFilter{TargetCurrency: str("RUB")},
+			wantErr: domain.ErrValidation,
+		},
+		{
+			name: "trial months are free",
+			setup: func(s *Storage) error {
+				sub := testutil.Monthly(first, 100)
+				sub.TrialEndDate = testutil.PtrTime(testutil.Month(2024, time.February))
+				return s.Insert(ctx, sub)
+			},
+			want: 400,
+		},
+		{
+			name: "paused months are not charged",
+			setup: func(s *Storage) error {
+				if err := s.Insert(ctx, testutil.Monthly(first, 100)); err != nil {
+					return err
+				}
+				return s.AddPause(ctx, first, model.Pause{From: testutil.Month(2024, time.March), ResumeFrom: testutil.PtrTime(testutil.Month(2024, time.May))})
+			},
+			want: 400,
+		},
+		{
+			name: "open-ended pause",
+			setup: func(s *Storage) error {
+				if err := s.Insert(ctx, testutil.Monthly(first, 100)); err != nil {
+					return err
+				}
+				return s.AddPause(ctx, first, model.Pause{From: testutil.Month(2024, time.April)})
+			},
+			want: 300,
+		},
+		{
+			name: "percent promotion",
+			setup: func(s *Storage) error {
+				return applyPromotion(ctx, s, testutil.Monthly(first, 100),
+					model.Promotion{Code: "SUMMER25", Kind: model.PromotionPercent, Value: 25, DurationMonths: 3},
+					testutil.Month(2024, time.February))
+			},
+			want: 100 + 3*75 + 2*100,
+		},
+		{
+			name: "fixed promotion off every cycle",
+			setup: func(s *Storage) error {
+				sub := testutil.Monthly(first, 300)
+				sub.BillingPeriod = model.BillingQuarter
+				return applyPromotion(ctx, s, sub,
+					model.Promotion{Code: "MINUS90", Kind: model.PromotionFixed, Value: 90, DurationMonths: 3},
+					testutil.Month(2024, time.January))
+			},
+			filter: model.SummaryFilter{Amortize: true},
+			want:   3*70 + 3*100,
+		},
+		{
+			name: "promotion ended early",
+			setup: func(s *Storage) error {
+				err := applyPromotion(ctx, s, testutil.Monthly(first, 100),
+					model.Promotion{Code: "HALF", Kind: model.PromotionPercent, Value: 50, DurationMonths: 6},
+					testutil.Month(2024, time.January))
+				if err != nil {
+					return err
+				}
+				return s.RemovePromotion(ctx, first, testutil.Month(2024, time.March))
+			},
+			want: 2*50 + 4*100,
+		},
+		{
+			name:   "owner share of an equal split gets the remainder",
+			setup:  func(s *Storage) error { return s.Insert(ctx, testutil.Shared(first, 100, model.SplitEqual, 0, 0)) },
+			filter: model.SummaryFilter{UserId: &testutil.Owner},
+			want:   6 * 34,
+		},
+		{
+			name:   "member share of an equal split",
+			setup:  func(s *Storage) error { return s.Insert(ctx, testutil.Shared(first, 100, model.SplitEqual, 0, 0)) },
+			filter: model.SummaryFilter{UserId: &testutil.MemberB},
+			want:   6 * 33,
+		},
+		{
+			name:  "shared subscription without a user counts in full",
+			setup: func(s *Storage) error { return s.Insert(ctx, testutil.Shared(first, 100, model.SplitEqual, 0, 0)) },
+			want:  600,
+		},
+		{
+			name:   "percent split",
+			setup:  func(s *Storage) error { return s.Insert(ctx, testutil.Shared(first, 100, model.SplitPercent, 25, 15)) },
+			filter: model.SummaryFilter{UserId: &testutil.Owner},
+			want:   6 * 60,
+		},
+		{
+			name: "fixed split scaled down below the shares",
+			setup: func(s *Storage) error {
+				if err := s.Insert(ctx, testutil.Shared(first, 100, model.SplitFixed, 40, 20)); err != nil {
+					return err
+				}
+				return s.AddPriceChange(ctx, first, model.PriceChange{EffectiveFrom: testutil.Month(2024, time.April), Price: 30})
+			},
+			filter: model.SummaryFilter{UserId: &testutil.MemberB},
+			want:   3*40 + 3*20,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewStorage()
+			if err := tt.setup(s); err != nil {
+				t.Fatalf("setup: %v", err)
+			}
+			filter := tt.filter
+			filter.From = testutil.Month(2024, time.January)
+			if filter.To.IsZero() {
+				filter.To = testutil.Month(2024, time.June)
+			}
+
+			got, err := s.GetSummary(ctx, filter)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("GetSummary() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetSummary() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GetSummary() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetSummaryBreakdown(t *testing.T) {
+	ctx := context.Background()
+	first := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	second := uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	str := func(s string) *string { return &s }
+
+	tests := []struct {
+		name   string
+		subs   []model.Subscription
+		filter model.SummaryFilter
+		want   []model.SummaryRow
+	}{
+		{
+			name: "months without charges are listed",
+			subs: func() []model.Subscription {
+				ended := testutil.Monthly(first, 100)
+				ended.EndDate = testutil.PtrTime(testutil.Month(2024, time.January))
+				later := testutil.Monthly(second, 200)
+				later.StartDate = testutil.Month(2024, time.March)
+				return []model.Subscription{ended, later}
+			}(),
+			filter: model.SummaryFilter{GroupByMonth: true},
+			want: []model.SummaryRow{
+				{Month: testutil.PtrTime(testutil.Month(2024, time.January)), Total: 100, ActiveCount: 1},
+				{Month: testutil.PtrTime(testutil.Month(2024, time.February))},
+				{Month: testutil.PtrTime(testutil.Month(2024, time.March)), Total: 200, ActiveCount: 1},
+			},
+		},
+		{
+			name: "by service",
+			subs: func() []model.Subscription {
+				other := testutil.Monthly(second, 50)
+				other.ServiceName = "Kinopoisk"
+				return []model.Subscription{testutil.Monthly(first, 100), other}
+			}(),
+			filter: model.SummaryFilter{GroupByService: true},
+			want: []model.SummaryRow{
+				{ServiceName: str("Kinopoisk"), Total: 150, ActiveCount: 1},
+				{ServiceName: str("Yandex Plus"), Total: 300, ActiveCount: 1},
+			},
+		},
+		{
+			name: "trial months by month",
+			subs: func() []model.Subscription {
+				sub := testutil.Monthly(first, 100)
+				sub.TrialEndDate = testutil.PtrTime(testutil.Month(2024, time.January))
+				return []model.Subscription{sub}
+			}(),
+			filter: model.SummaryFilter{GroupByMonth: true},
+			want: []model.SummaryRow{
+				{Month: testutil.PtrTime(testutil.Month(2024, time.January)), Total: 0, ActiveCount: 1, TrialMonths: 1},
+				{Month: testutil.PtrTime(testutil.Month(2024, time.February)), Total: 100, ActiveCount: 1},
+				{Month: testutil.PtrTime(testutil.Month(2024, time.March)), Total: 100, ActiveCount: 1},
+			},
+		},
+		{
+			name:   "shares by user add up to the charges",
+			subs:   []model.Subscription{testutil.Shared(first, 100, model.SplitEqual, 0, 0)},
+			filter: model.SummaryFilter{GroupByUser: true},
+			want: []model.SummaryRow{
+				{UserId: &testutil.Owner, Total: 3 * 34, ActiveCount: 1},
+				{UserId: &testutil.MemberB, Total: 3 * 33, ActiveCount: 1},
+				{UserId: &testutil.MemberC, Total: 3 * 33, ActiveCount: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewStorage()
+			for _, sub := range tt.subs {
+				if err := s.Insert(ctx, sub); err != nil {
+					t.Fatalf("Insert() error = %v", err)
+				}
+			}
+			filter := tt.filter
+			filter.From = testutil.Month(2024, time.January)
+			filter.To = testutil.Month(2024, time.March)
+
+			got, err := s.GetSummaryBreakdown(ctx, filter)
+			if err != nil {
+				t.Fatalf("GetSummaryBreakdown() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("GetSummaryBreakdown() returned %d rows, want %d", len(got), len(tt.want))
+			}
+			for i, row := range got {
+				want := tt.want[i]
+				if !equalTime(row.Month, want.Month) || !equalString(row.ServiceName, want.ServiceName) || !equalUUID(row.UserId, want.UserId) ||
+					row.Total != want.Total || row.ActiveCount != want.ActiveCount || row.TrialMonths != want.TrialMonths {
+					t.Errorf("row %d = %+v, want %+v", i, row, want)
+				}
+			}
+		})
+	}
+}
+
+func insertAll(ctx context.Context, s *Storage, subs ...model.Subscription) error {
+	for _, sub := range subs {
+		if err := s.Insert(ctx, sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyPromotion(ctx context.Context, s *Storage, sub model.Subscription, promo model.Promotion, from time.Time) error {
+	if err := s.Insert(ctx, sub); err != nil {
+		return err
+	}
+	if err := s.CreatePromotion(ctx, promo); err != nil {
+		return err
+	}
+	return s.ApplyPromotion(ctx, sub.Id, model.AppliedPromotion{
+		Code:         promo.Code,
+		AppliedFrom:  from,
+		AppliedUntil: from.AddDate(0, promo.DurationMonths, 0),
+	})
+}
+
+func equalTime(a, b *time.Time) bool {
+	return a == nil && b == nil || a != nil && b != nil && a.Equal(*b)
+}
+
+func equalString(a, b *string) bool {
+	return a == nil && b == nil || a != nil && b != nil && *a == *b
+}
+
+func equalUUID(a, b *uuid.UUID) bool {
+	return a == nil && b == nil || a != nil && b != nil && *a == *b
+}
@@ -0,0 +1,32 @@
+package storage
+
+import (
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"time"
+)
+
+// CheckPauseOverlap fails with domain.ErrConflict if p overlaps one of pauses.
+func CheckPauseOverlap(pauses []model.Pause, p model.Pause) error {
+	for _, e := range pauses {
+		startsBefore := p.ResumeFrom == nil || e.From.Before(*p.ResumeFrom)
+		endsAfter := e.ResumeFrom == nil || p.From.Before(*e.ResumeFrom)
+		if startsBefore && endsAfter {
+			return domain.Conflict("pause overlaps the pause from %s", e.From.Format("2006-01"))
+		}
+	}
+	return nil
+}
+
+// PauseToResume returns the index of the first pause in pauses (ordered by
+// From) still in effect at month m. When that pause starts at m or later,
+// resuming cancels it and cancel is set. domain.ErrConflict is returned if
+// nothing is paused at or after m.
+func PauseToResume(pauses []model.Pause, m time.Time) (i int, cancel bool, err error) {
+	for i, p := range pauses {
+		if p.ResumeFrom == nil || p.ResumeFrom.After(m) {
+			return i, !p.From.Before(m), nil
+		}
+	}
+	return 0, false, domain.Conflict("subscription is not paused")
+}
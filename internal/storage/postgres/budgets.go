@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	apimw "subservice/internal/api/middleware"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+
+	"github.com/google/uuid"
+)
+
+func (r *PgRepository) UpsertBudget(ctx context.Context, budget model.Budget) error {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	query := `
+		INSERT INTO budgets (user_id, category, monthly_limit, currency)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, category) DO UPDATE
+		SET monthly_limit = EXCLUDED.monthly_limit, currency = EXCLUDED.currency
+	`
+
+	_, err := tx.Exec(ctx, query, budget.UserId, budget.Category, budget.Limit, budget.Currency)
+	if err != nil {
+		l.Error("Failed to upsert budget", zap.Error(err))
+		return fmt.Errorf("upsert budget: %w", err)
+	}
+	l.Info("Budget saved successfully", zap.String("user_id", budget.UserId.String()), zap.String("category", budget.Category))
+	return nil
+}
+
+func (r *PgRepository) GetBudgets(ctx context.Context, userId uuid.UUID) ([]model.Budget, error) {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	query := `
+		SELECT user_id, category, monthly_limit, currency
+		FROM budgets
+		WHERE user_id = $1
+		ORDER BY category
+	`
+
+	rows, err := tx.Query(ctx, query, userId)
+	if err != nil {
+		l.Error("Failed to query budgets", zap.Error(err))
+		return nil, fmt.Errorf("query budgets: %w", err)
+	}
+	defer rows.Close()
+
+	budgets := []model.Budget{}
+	for rows.Next() {
+		var b model.Budget
+		if err := rows.Scan(&b.UserId, &b.Category, &b.Limit, &b.Currency); err != nil {
+			return nil, fmt.Errorf("scan budget: %w", err)
+		}
+		budgets = append(budgets, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query budgets: %w", err)
+	}
+	return budgets, nil
+}
+
+func (r *PgRepository) DeleteBudget(ctx context.Context, userId uuid.UUID, category string) error {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	cmdTag, err := tx.Exec(ctx, "DELETE FROM budgets WHERE user_id = $1 AND category = $2", userId, category)
+	if err != nil {
+		l.Error("Failed to delete budget", zap.Error(err))
+		return fmt.Errorf("delete budget: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return domain.NotFound("budget not found")
+	}
+	l.Info("Budget deleted successfully", zap.String("user_id", userId.String()), zap.String("category", category))
+	return nil
+}
+
+// InsertBudgetAlert records alert and reports whether it is new, that is the
+// month was not reported for the budget before.
+func (r *PgRepository) InsertBudgetAlert(ctx context.Context, alert model.BudgetAlert) (bool, error) {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	query := `
+		INSERT INTO budget_alerts (user_id, category, month)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, category, month) DO NOTHING
+	`
+
+	cmdTag, err := tx.Exec(ctx, query, alert.UserId, alert.Category, alert.Month)
+	if err != nil {
+		l.Error("Failed to insert budget alert", zap.Error(err))
+		return false, fmt.Errorf("insert budget alert: %w", err)
+	}
+	return cmdTag.RowsAffected() == 1, nil
+}
@@ -0,0 +1,275 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"go.uber.org/zap"
+	apimw "subservice/internal/api/middleware"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+)
+
+const catalogQuery = `
+	SELECT s.name, s.category, s.default_price,
+	       COALESCE(array_agg(a.alias ORDER BY a.alias) FILTER (WHERE a.alias IS NOT NULL), '{}')
+	FROM services s
+	LEFT JOIN service_aliases a ON a.service_name = s.name
+`
+
+func scanCatalogService(row pgx.Row, svc *model.CatalogService) error {
+	var category *string
+	if err := row.Scan(&svc.Name, &category, &svc.DefaultPrice, &svc.Aliases); err != nil {
+		return err
+	}
+	if category != nil {
+		svc.Category = *category
+	}
+	if len(svc.Aliases) == 0 {
+		svc.Aliases = nil
+	}
+	return nil
+}
+
+func nullableCategory(svc model.CatalogService) *string {
+	if svc.Category == "" {
+		return nil
+	}
+	return &svc.Category
+}
+
+func (r *PgRepository) InsertCatalogService(ctx context.Context, svc model.CatalogService) error {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	query := `
+		INSERT INTO services (name, name_key, category, default_price)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	_, err := tx.Exec(ctx, query, svc.Name, model.ServiceKey(svc.Name), nullableCategory(svc), svc.DefaultPrice)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return domain.Conflict("service %s already exists", svc.Name)
+		}
+		l.Error("Failed to insert catalog service", zap.Error(err))
+		return fmt.Errorf("insert catalog service: %w", err)
+	}
+	if err := r.insertAliases(ctx, svc); err != nil {
+		return err
+	}
+	l.Info("Catalog service created", zap.String("name", svc.Name))
+	return nil
+}
+
+func (r *PgRepository) UpdateCatalogService(ctx context.Context, svc model.CatalogService) error {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	cmdTag, err := tx.Exec(ctx, "UPDATE services SET category = $2, default_price = $3 WHERE name = $1",
+		svc.Name, nullableCategory(svc), svc.DefaultPrice)
+	if err != nil {
+		l.Error("Failed to update catalog service", zap.Error(err))
+		return fmt.Errorf("update catalog service: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return domain.NotFound("service not found")
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM service_aliases WHERE service_name = $1", svc.Name); err != nil {
+		l.Error("Failed to remove service aliases", zap.Error(err))
+		return fmt.Errorf("update catalog service: %w", err)
+	}
+	if err := r.insertAliases(ctx, svc); err != nil {
+		return err
+	}
+	l.Info("Catalog service updated", zap.String("name", svc.Name))
+	return nil
+}
+
+func (r *PgRepository) insertAliases(ctx context.Context, svc model.CatalogService) error {
+	if len(svc.Aliases) == 0 {
+		return nil
+	}
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	_, err := tx.Exec(ctx, "INSERT INTO service_aliases (alias, service_name) SELECT unnest($1::text[]), $2", svc.Aliases, svc.Name)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return domain.Conflict("alias is already used by another service")
+		}
+		apimw.FromContext(ctx).Error("Failed to insert service aliases", zap.Error(err))
+		return fmt.Errorf("insert service aliases: %w", err)
+	}
+	return nil
+}
+
+// subscriptionKeyExpr is model.ServiceKey of subscriptions.service_name.
+const subscriptionKeyExpr = `lower(btrim(regexp_replace(service_name, '\s+', ' ', 'g')))`
+
+// RenameServiceSubscriptions stores the subscriptions whose service name has
+// one of keys (model.ServiceKey values) under name, incrementing their version.
+// It returns the renamed subscriptions as they were before and after.
+func (r *PgRepository) RenameServiceSubscriptions(ctx context.Context, keys []string, name string) ([]model.Subscription, []model.Subscription, error) {
+	l := apimw.FromContext(ctx)
+
+	before, err := r.selectSubscriptions(ctx, `
+		SELECT `+subscriptionColumns+`
+		FROM subscriptions
+		WHERE `+subscriptionKeyExpr+` = ANY($1) AND service_name <> $2
+		ORDER BY id
+		FOR UPDATE
+	`, keys, name)
+	if err != nil {
+		l.Error("Failed to select subscriptions to rename", zap.Error(err))
+		return nil, nil, fmt.Errorf("rename service subscriptions: %w", err)
+	}
+	if len(before) == 0 {
+		return nil, nil, nil
+	}
+	ids := make([]uuid.UUID, len(before))
+	for i, sub := range before {
+		ids[i] = sub.Id
+	}
+
+	tx := r.txManager.GetQueryEngine(ctx)
+	if _, err := tx.Exec(ctx, "UPDATE subscriptions SET service_name = $2, version = version + 1 WHERE id = ANY($1)", ids, name); err != nil {
+		l.Error("Failed to rename service subscriptions", zap.Error(err))
+		return nil, nil, fmt.Errorf("rename service subscriptions: %w", err)
+	}
+
+	after, err := r.selectSubscriptions(ctx, `
+		SELECT `+subscriptionColumns+`
+		FROM subscriptions
+		WHERE id = ANY($1)
+		ORDER BY id
+	`, ids)
+	if err != nil {
+		l.Error("Failed to select renamed subscriptions", zap.Error(err))
+		return nil, nil, fmt.Errorf("rename service subscriptions: %w", err)
+	}
+	l.Info("Subscriptions renamed to catalog name", zap.String("name", name), zap.Int("subscriptions", len(after)))
+	return before, after, nil
+}
+
+func (r *PgRepository) selectSubscriptions(ctx context.Context, query string, args ...interface{}) ([]model.Subscription, error) {
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []model.Subscription
+	for rows.Next() {
+		var sub model.Subscription
+		if err := scanSubscription(rows, &sub); err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+func (r *PgRepository) DeleteCatalogService(ctx context.Context, name string) error {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	cmdTag, err := tx.Exec(ctx, "DELETE FROM services WHERE name = $1", name)
+	if err != nil {
+		l.Error("Failed to delete catalog service", zap.Error(err))
+		return fmt.Errorf("delete catalog service: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return domain.NotFound("service not found")
+	}
+	l.Info("Catalog service deleted", zap.String("name", name))
+	return nil
+}
+
+func (r *PgRepository) GetCatalogService(ctx context.Context, name string) (*model.CatalogService, error) {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	var svc model.CatalogService
+	err := scanCatalogService(tx.QueryRow(ctx, catalogQuery+" WHERE s.name = $1 GROUP BY s.name", name), &svc)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NotFound("service not found")
+		}
+		l.Error("Failed to get catalog service", zap.Error(err))
+		return nil, fmt.Errorf("get catalog service: %w", err)
+	}
+	return &svc, nil
+}
+
+func (r *PgRepository) GetCatalogServices(ctx context.Context, category *string) ([]model.CatalogService, error) {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	rows, err := tx.Query(ctx, catalogQuery+" WHERE ($1::text IS NULL OR s.category = $1) GROUP BY s.name ORDER BY s.name", category)
+	if err != nil {
+		l.Error("Failed to query catalog services", zap.Error(err))
+		return nil, fmt.Errorf("query catalog services: %w", err)
+	}
+	defer rows.Close()
+
+	services := []model.CatalogService{}
+	for rows.Next() {
+		var svc model.CatalogService
+		if err := scanCatalogService(rows, &svc); err != nil {
+			return nil, fmt.Errorf("scan catalog service: %w", err)
+		}
+		services = append(services, svc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query catalog services: %w", err)
+	}
+	return services, nil
+}
+
+// ResolveServiceNames maps each of keys (model.ServiceKey values) that names a
+// catalog service or one of its aliases to the canonical service name.
+func (r *PgRepository) ResolveServiceNames(ctx context.Context, keys []string) (map[string]string, error) {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	query := `
+		SELECT name_key, name FROM services WHERE name_key = ANY($1)
+		UNION ALL
+		SELECT alias, service_name FROM service_aliases WHERE alias = ANY($1)
+	`
+
+	rows, err := tx.Query(ctx, query, keys)
+	if err != nil {
+		l.Error("Failed to resolve service names", zap.Error(err))
+		return nil, fmt.Errorf("resolve service names: %w", err)
+	}
+	defer rows.Close()
+
+	resolved := make(map[string]string, len(keys))
+	for rows.Next() {
+		var k, name string
+		if err := rows.Scan(&k, &name); err != nil {
+			return nil, fmt.Errorf("scan service name: %w", err)
+		}
+		resolved[k] = name
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("resolve service names: %w", err)
+	}
+	return resolved, nil
+}
@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go.uber.org/zap"
+	apimw "subservice/internal/api/middleware"
+	"subservice/internal/model"
+)
+
+func (r *PgRepository) InsertEvent(ctx context.Context, event model.SubscriptionEvent) error {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	query := `
+		INSERT INTO subscription_events (subscription_id, user_id, service_name, action, before, after, request_id, actor, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), $9)
+	`
+
+	_, err := tx.Exec(ctx, query,
+		event.SubscriptionId,
+		event.UserId,
+		event.ServiceName,
+		event.Action,
+		nullableJSON(event.Before),
+		nullableJSON(event.After),
+		event.RequestId,
+		event.Actor,
+		event.CreatedAt,
+	)
+	if err != nil {
+		l.Error("Failed to insert subscription event", zap.Error(err))
+		return fmt.Errorf("insert subscription event: %w", err)
+	}
+	return nil
+}
+
+func (r *PgRepository) GetEvents(ctx context.Context, filter model.EventFilter) ([]model.SubscriptionEvent, error) {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	query := `
+		SELECT id, subscription_id, user_id, service_name, action, before, after,
+		       COALESCE(request_id, ''), COALESCE(actor, ''), created_at
+		FROM subscription_events
+		WHERE ($1::uuid IS NULL OR user_id = $1)
+		  AND ($2::text IS NULL OR service_name = $2)
+		  AND ($3::timestamptz IS NULL OR created_at >= $3)
+		  AND ($4::timestamptz IS NULL OR created_at <= $4)
+		  AND ($5::bigint IS NULL OR id < $5)
+		  AND ($7::uuid IS NULL OR subscription_id = $7)
+		ORDER BY id DESC
+		LIMIT $6
+	`
+
+	rows, err := tx.Query(ctx, query, filter.UserId, filter.ServiceName, filter.From, filter.To, filter.BeforeId, filter.Limit, filter.SubscriptionId)
+	if err != nil {
+		l.Error("Failed to query subscription events", zap.Error(err))
+		return nil, fmt.Errorf("query subscription events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []model.SubscriptionEvent{}
+	for rows.Next() {
+		var (
+			e             model.SubscriptionEvent
+			before, after []byte
+		)
+		err := rows.Scan(&e.Id, &e.SubscriptionId, &e.UserId, &e.ServiceName, &e.Action, &before, &after, &e.RequestId, &e.Actor, &e.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("scan subscription event: %w", err)
+		}
+		e.Before, e.After = before, after
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query subscription events: %w", err)
+	}
+	l.Info("Fetched subscription events successfully", zap.Int("count", len(events)))
+	return events, nil
+}
+
+// nullableJSON passes an empty snapshot as SQL NULL.
+func nullableJSON(raw json.RawMessage) interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+	return string(raw)
+}
+
+// nullableString passes an empty string as SQL NULL.
+func nullableString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
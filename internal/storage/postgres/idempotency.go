@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	apimw "subservice/internal/api/middleware"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+// GetIdempotencyKey returns an unexpired key of the user or domain.ErrNotFound.
+func (r *PgRepository) GetIdempotencyKey(ctx context.Context, userId uuid.UUID, key string) (*model.IdempotencyKey, error) {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	query := `
+		SELECT user_id, key, request_hash, response_status, response_body, created_at, expires_at
+		FROM idempotency_keys
+		WHERE user_id = $1 AND key = $2 AND expires_at > now()
+	`
+
+	var (
+		rec  model.IdempotencyKey
+		body []byte
+	)
+	err := tx.QueryRow(ctx, query, userId, key).Scan(&rec.UserId, &rec.Key, &rec.RequestHash, &rec.ResponseStatus, &body, &rec.CreatedAt, &rec.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NotFound("idempotency key not found")
+		}
+		l.Error("Failed to get idempotency key", zap.Error(err))
+		return nil, fmt.Errorf("get idempotency key: %w", err)
+	}
+	rec.ResponseBody = body
+	return &rec, nil
+}
+
+// SaveIdempotencyKey stores rec, replacing an expired record with the same user
+// and key.
+func (r *PgRepository) SaveIdempotencyKey(ctx context.Context, rec model.IdempotencyKey) error {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	query := `
+		INSERT INTO idempotency_keys (user_id, key, request_hash, response_status, response_body, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (user_id, key) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash,
+		    response_status = EXCLUDED.response_status,
+		    response_body = EXCLUDED.response_body,
+		    created_at = EXCLUDED.created_at,
+		    expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= now()
+	`
+
+	cmdTag, err := tx.Exec(ctx, query, rec.UserId, rec.Key, rec.RequestHash, rec.ResponseStatus, string(rec.ResponseBody), rec.CreatedAt, rec.ExpiresAt)
+	if err != nil {
+		l.Error("Failed to save idempotency key", zap.Error(err))
+		return fmt.Errorf("save idempotency key: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return domain.Conflict("idempotency key is already in use")
+	}
+	return nil
+}
+
+func (r *PgRepository) DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) (int64, error) {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	cmdTag, err := tx.Exec(ctx, "DELETE FROM idempotency_keys WHERE expires_at <= $1", before)
+	if err != nil {
+		l.Error("Failed to delete expired idempotency keys", zap.Error(err))
+		return 0, fmt.Errorf("delete expired idempotency keys: %w", err)
+	}
+	return cmdTag.RowsAffected(), nil
+}
@@ -0,0 +1,206 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go.uber.org/zap"
+	apimw "subservice/internal/api/middleware"
+	"subservice/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+// ImportSubscriptions bulk-inserts subs with COPY and reports which of them
+// were inserted. Subscriptions whose id already exists are skipped, and of
+// several rows with the same id only the first one is inserted. It creates a
+// temporary table dropped on commit, so it must run inside a transaction.
+func (r *PgRepository) ImportSubscriptions(ctx context.Context, subs []model.Subscription) ([]bool, error) {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	_, err := tx.Exec(ctx, `
+		CREATE TEMP TABLE import_staging (
+			idx INTEGER NOT NULL,
+			id UUID NOT NULL,
+			user_id UUID NOT NULL,
+			service_name TEXT NOT NULL,
+			price INTEGER NOT NULL,
+			start_date DATE NOT NULL,
+			end_date DATE,
+			billing_period TEXT NOT NULL,
+			billing_interval INTEGER NOT NULL,
+			currency CHAR(3) NOT NULL,
+			trial_end_date DATE,
+			split TEXT,
+			tags TEXT[],
+			members JSONB
+		) ON COMMIT DROP
+	`)
+	if err != nil {
+		l.Error("Failed to create import staging table", zap.Error(err))
+		return nil, fmt.Errorf("import subscriptions: %w", err)
+	}
+
+	columns := []string{"idx", "id", "user_id", "service_name", "price", "start_date", "end_date", "billing_period", "billing_interval", "currency", "trial_end_date", "split", "tags", "members"}
+	_, err = tx.CopyFrom(ctx, pgx.Identifier{"import_staging"}, columns, pgx.CopyFromSlice(len(subs), func(i int) ([]interface{}, error) {
+		sub := subs[i].NormalizeDates()
+		var members []byte
+		if len(sub.Members) > 0 {
+			var err error
+			if members, err = json.Marshal(sub.Members); err != nil {
+				return nil, err
+			}
+		}
+		return []interface{}{
+			i,
+			sub.Id,
+			sub.UserId,
+			sub.ServiceName,
+			sub.Price,
+			sub.StartDate,
+			sub.EndDate,
+			sub.BillingPeriod,
+			sub.BillingInterval,
+			sub.Currency,
+			sub.TrialEndDate,
+			nullableString(sub.Split),
+			sub.Tags,
+			nullableJSON(members),
+		}, nil
+	}))
+	if err != nil {
+		l.Error("Failed to copy subscriptions into staging table", zap.Error(err))
+		return nil, fmt.Errorf("import subscriptions: %w", err)
+	}
+
+	// The tags and members of the inserted rows are added by the same statement.
+	rows, err := tx.Query(ctx, `
+		WITH first AS (
+			SELECT DISTINCT ON (id) *
+			FROM import_staging
+			ORDER BY id, idx
+		), inserted AS (
+			INSERT INTO subscriptions (id, user_id, service_name, price, start_date, end_date, billing_period, billing_interval, currency, trial_end_date, split)
+			SELECT id, user_id, service_name, price, start_date, end_date, billing_period, billing_interval, currency, trial_end_date, split
+			FROM first
+			ON CONFLICT (id) DO NOTHING
+			RETURNING id
+		), tagged AS (
+			INSERT INTO subscription_tags (subscription_id, tag)
+			SELECT f.id, unnest(f.tags)
+			FROM first f
+			JOIN inserted i ON i.id = f.id
+		), shared AS (
+			INSERT INTO subscription_members (subscription_id, user_id, share)
+			SELECT f.id, m.user_id, m.share
+			FROM first f
+			JOIN inserted i ON i.id = f.id
+			CROSS JOIN jsonb_to_recordset(f.members) AS m (user_id UUID, share BIGINT)
+		)
+		SELECT id FROM inserted
+	`)
+	if err != nil {
+		l.Error("Failed to insert imported subscriptions", zap.Error(err))
+		return nil, fmt.Errorf("import subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	first := make(map[uuid.UUID]int, len(subs))
+	for i := len(subs) - 1; i >= 0; i-- {
+		first[subs[i].Id] = i
+	}
+
+	inserted := make([]bool, len(subs))
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan imported subscription: %w", err)
+		}
+		inserted[first[id]] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("import subscriptions: %w", err)
+	}
+	l.Info("Subscriptions imported", zap.Int("rows", len(subs)))
+	return inserted, nil
+}
+
+// LiveSubscriptionsExist reports for each of subs whether a live subscription
+// has its user, service and start date.
+func (r *PgRepository) LiveSubscriptionsExist(ctx context.Context, subs []model.Subscription) ([]bool, error) {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	userIds := make([]uuid.UUID, len(subs))
+	serviceNames := make([]string, len(subs))
+	startDates := make([]time.Time, len(subs))
+	for i, sub := range subs {
+		sub = sub.NormalizeDates()
+		userIds[i], serviceNames[i], startDates[i] = sub.UserId, sub.ServiceName, sub.StartDate
+	}
+
+	query := `
+		SELECT k.idx
+		FROM unnest($1::uuid[], $2::text[], $3::date[]) WITH ORDINALITY AS k (user_id, service_name, start_date, idx)
+		WHERE EXISTS (
+			SELECT 1
+			FROM subscriptions s
+			WHERE s.deleted_at IS NULL
+			  AND s.user_id = k.user_id
+			  AND s.service_name = k.service_name
+			  AND s.start_date = k.start_date
+		)
+	`
+
+	rows, err := tx.Query(ctx, query, userIds, serviceNames, startDates)
+	if err != nil {
+		l.Error("Failed to look up live subscriptions", zap.Error(err))
+		return nil, fmt.Errorf("look up live subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	live := make([]bool, len(subs))
+	for rows.Next() {
+		var idx int
+		if err := rows.Scan(&idx); err != nil {
+			return nil, fmt.Errorf("scan live subscription: %w", err)
+		}
+		live[idx-1] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("look up live subscriptions: %w", err)
+	}
+	return live, nil
+}
+
+func (r *PgRepository) InsertEvents(ctx context.Context, events []model.SubscriptionEvent) error {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	columns := []string{"subscription_id", "user_id", "service_name", "action", "before", "after", "request_id", "actor", "created_at"}
+	_, err := tx.CopyFrom(ctx, pgx.Identifier{"subscription_events"}, columns, pgx.CopyFromSlice(len(events), func(i int) ([]interface{}, error) {
+		e := events[i]
+		return []interface{}{
+			e.SubscriptionId,
+			e.UserId,
+			e.ServiceName,
+			e.Action,
+			nullableJSON(e.Before),
+			nullableJSON(e.After),
+			nullableString(e.RequestId),
+			nullableString(e.Actor),
+			e.CreatedAt,
+		}, nil
+	}))
+	if err != nil {
+		l.Error("Failed to copy subscription events", zap.Error(err))
+		return fmt.Errorf("insert subscription events: %w", err)
+	}
+	return nil
+}
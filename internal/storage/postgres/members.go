@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	apimw "subservice/internal/api/middleware"
+	"subservice/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// subscriptionMembersColumn selects the members of the row of an unaliased
+// subscriptions table as a JSON array ordered by user_id, or NULL if the
+// subscription is not shared.
+const subscriptionMembersColumn = `(
+	SELECT json_agg(json_build_object('user_id', m.user_id, 'share', m.share) ORDER BY m.user_id)
+	FROM subscription_members m
+	WHERE m.subscription_id = subscriptions.id
+) AS members`
+
+// replaceMembers sets the members of a subscription to exactly members.
+func (r *PgRepository) replaceMembers(ctx context.Context, id uuid.UUID, members []model.Member) error {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	_, err := tx.Exec(ctx, "DELETE FROM subscription_members WHERE subscription_id = $1", id)
+	if err != nil {
+		l.Error("Failed to delete subscription members", zap.Error(err))
+		return fmt.Errorf("replace subscription members: %w", err)
+	}
+	if len(members) == 0 {
+		return nil
+	}
+
+	userIds := make([]string, len(members))
+	shares := make([]*int64, len(members))
+	for i, m := range members {
+		userIds[i] = m.UserId.String()
+		if m.Share != 0 {
+			share := m.Share
+			shares[i] = &share
+		}
+	}
+
+	query := `
+		INSERT INTO subscription_members (subscription_id, user_id, share)
+		SELECT $1, m.user_id, m.share
+		FROM unnest($2::uuid[], $3::bigint[]) AS m (user_id, share)
+	`
+	if _, err := tx.Exec(ctx, query, id, userIds, shares); err != nil {
+		l.Error("Failed to insert subscription members", zap.Error(err))
+		return fmt.Errorf("replace subscription members: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"subservice/migrations"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jackc/pgx/v4/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+	"go.uber.org/zap"
+)
+
+const (
+	MigrateUp     = "up"
+	MigrateDown   = "down"
+	MigrateStatus = "status"
+)
+
+// Migrate runs a goose command against the migrations embedded into the
+// binary. A Postgres advisory lock is held for the duration of the command,
+// so replicas starting at the same time apply migrations only once.
+func Migrate(ctx context.Context, pool *pgxpool.Pool, command string, l *zap.Logger) error {
+	db := stdlib.OpenDB(*pool.Config().ConnConfig)
+	defer db.Close()
+
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return fmt.Errorf("create migration lock: %w", err)
+	}
+
+	provider, err := goose.NewProvider(goose.DialectPostgres, db, migrations.FS, goose.WithSessionLocker(locker))
+	if err != nil {
+		return fmt.Errorf("create migration provider: %w", err)
+	}
+
+	switch command {
+	case MigrateUp:
+		results, err := provider.Up(ctx)
+		for _, res := range results {
+			l.Info("Migration applied", zap.String("migration", res.Source.Path), zap.Duration("duration", res.Duration))
+		}
+		if err != nil {
+			return fmt.Errorf("migrate up: %w", err)
+		}
+		if len(results) == 0 {
+			l.Info("No pending migrations")
+		}
+	case MigrateDown:
+		res, err := provider.Down(ctx)
+		if err != nil {
+			return fmt.Errorf("migrate down: %w", err)
+		}
+		l.Info("Migration rolled back", zap.String("migration", res.Source.Path), zap.Duration("duration", res.Duration))
+	case MigrateStatus:
+		statuses, err := provider.Status(ctx)
+		if err != nil {
+			return fmt.Errorf("migrate status: %w", err)
+		}
+		for _, st := range statuses {
+			fields := []zap.Field{zap.String("migration", st.Source.Path), zap.String("state", string(st.State))}
+			if st.State == goose.StateApplied {
+				fields = append(fields, zap.Time("applied_at", st.AppliedAt))
+			}
+			l.Info("Migration status", fields...)
+		}
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected up, down or status", command)
+	}
+	return nil
+}
@@ -11,11 +11,52 @@ import (
 
 type ServiceRepository interface {
 	InsertSubscription(ctx context.Context, subUnit model.Subscription) error
-	GetSubscription(ctx context.Context, userId uuid.UUID, serviceName string) (*model.Subscription, error)
+	GetSubscription(ctx context.Context, id uuid.UUID) (*model.Subscription, error)
 	UpdateSubscription(ctx context.Context, subUnit model.Subscription) error
-	DeleteSubscription(ctx context.Context, userId uuid.UUID, serviceName string) error
-	GetSubscriptionsList(ctx context.Context, userId *uuid.UUID, serviceName *string) (*[]model.Subscription, error)
-	GetSubscriptionsSummary(ctx context.Context, from time.Time, to time.Time, userId *uuid.UUID, serviceName *string) (int, error)
+	DeleteSubscription(ctx context.Context, id uuid.UUID, version int64) error
+	RestoreSubscription(ctx context.Context, id uuid.UUID) error
+	IncrementVersion(ctx context.Context, id uuid.UUID) error
+	PurgeSubscriptions(ctx context.Context, deletedBefore time.Time) (int64, error)
+	GetSubscriptionsList(ctx context.Context, filter model.ListFilter) (*model.SubscriptionPage, error)
+	StreamSubscriptions(ctx context.Context, filter model.ListFilter, fn func(model.Subscription) error) error
+	GetSubscriptionsSummary(ctx context.Context, filter model.SummaryFilter) (int, error)
+	GetSubscriptionsSummaryBreakdown(ctx context.Context, filter model.SummaryFilter) ([]model.SummaryRow, error)
+	InsertPriceChange(ctx context.Context, id uuid.UUID, change model.PriceChange) error
+	GetPriceChanges(ctx context.Context, id uuid.UUID) ([]model.PriceChange, error)
+	InsertPause(ctx context.Context, id uuid.UUID, pause model.Pause) error
+	UpdatePauseEnd(ctx context.Context, id uuid.UUID, from, resumeFrom time.Time) error
+	DeletePause(ctx context.Context, id uuid.UUID, from time.Time) error
+	GetPauses(ctx context.Context, id uuid.UUID) ([]model.Pause, error)
+	InsertPromotion(ctx context.Context, promo model.Promotion) error
+	GetPromotion(ctx context.Context, code string) (*model.Promotion, error)
+	GetPromotions(ctx context.Context) ([]model.Promotion, error)
+	DeletePromotion(ctx context.Context, code string) error
+	InsertAppliedPromotion(ctx context.Context, id uuid.UUID, applied model.AppliedPromotion) error
+	GetAppliedPromotions(ctx context.Context, id uuid.UUID) ([]model.AppliedPromotion, error)
+	UpdateAppliedPromotionEnd(ctx context.Context, id uuid.UUID, from, until time.Time) error
+	DeleteAppliedPromotion(ctx context.Context, id uuid.UUID, from time.Time) error
+	InsertCatalogService(ctx context.Context, svc model.CatalogService) error
+	UpdateCatalogService(ctx context.Context, svc model.CatalogService) error
+	DeleteCatalogService(ctx context.Context, name string) error
+	GetCatalogService(ctx context.Context, name string) (*model.CatalogService, error)
+	GetCatalogServices(ctx context.Context, category *string) ([]model.CatalogService, error)
+	ResolveServiceNames(ctx context.Context, keys []string) (map[string]string, error)
+	RenameServiceSubscriptions(ctx context.Context, keys []string, name string) ([]model.Subscription, []model.Subscription, error)
+	UpsertExchangeRate(ctx context.Context, rate model.ExchangeRate) error
+	GetExchangeRates(ctx context.Context, fromCurrency, toCurrency *string) ([]model.ExchangeRate, error)
+	DeleteExchangeRate(ctx context.Context, fromCurrency, toCurrency string, validFrom time.Time) error
+	UpsertBudget(ctx context.Context, budget model.Budget) error
+	GetBudgets(ctx context.Context, userId uuid.UUID) ([]model.Budget, error)
+	DeleteBudget(ctx context.Context, userId uuid.UUID, category string) error
+	InsertBudgetAlert(ctx context.Context, alert model.BudgetAlert) (bool, error)
+	InsertEvent(ctx context.Context, event model.SubscriptionEvent) error
+	InsertEvents(ctx context.Context, events []model.SubscriptionEvent) error
+	GetEvents(ctx context.Context, filter model.EventFilter) ([]model.SubscriptionEvent, error)
+	GetIdempotencyKey(ctx context.Context, userId uuid.UUID, key string) (*model.IdempotencyKey, error)
+	SaveIdempotencyKey(ctx context.Context, rec model.IdempotencyKey) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) (int64, error)
+	LiveSubscriptionsExist(ctx context.Context, subs []model.Subscription) ([]bool, error)
+	ImportSubscriptions(ctx context.Context, subs []model.Subscription) ([]bool, error)
 }
 
 type QueryEngine interface {
@@ -24,6 +65,8 @@ type QueryEngine interface {
 	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
 
 	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
 }
 
 type TransactionManager interface {
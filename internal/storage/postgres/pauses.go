@@ -0,0 +1,113 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
+	"go.uber.org/zap"
+	apimw "subservice/internal/api/middleware"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"time"
+)
+
+func (r *PgRepository) InsertPause(ctx context.Context, id uuid.UUID, pause model.Pause) error {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	query := `
+		INSERT INTO subscription_pauses (subscription_id, paused_from, resumed_from)
+		VALUES ($1, $2, $3)
+	`
+
+	_, err := tx.Exec(ctx, query, id, pause.From, pause.ResumeFrom)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return domain.Conflict("subscription is already paused from %s", pause.From.Format("2006-01"))
+		}
+		if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+			return domain.NotFound("subscription not found")
+		}
+		l.Error("Failed to insert pause", zap.Error(err))
+		return fmt.Errorf("insert pause: %w", err)
+	}
+	l.Info("Subscription pause saved", zap.String("id", id.String()), zap.Time("from", pause.From))
+	return nil
+}
+
+// UpdatePauseEnd sets the resume month of the pause starting at from.
+func (r *PgRepository) UpdatePauseEnd(ctx context.Context, id uuid.UUID, from, resumeFrom time.Time) error {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	query := `
+		UPDATE subscription_pauses
+		SET resumed_from = $3
+		WHERE subscription_id = $1 AND paused_from = $2
+	`
+
+	cmdTag, err := tx.Exec(ctx, query, id, from, resumeFrom)
+	if err != nil {
+		l.Error("Failed to update pause", zap.Error(err))
+		return fmt.Errorf("update pause: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return domain.NotFound("pause not found")
+	}
+	return nil
+}
+
+func (r *PgRepository) DeletePause(ctx context.Context, id uuid.UUID, from time.Time) error {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	cmdTag, err := tx.Exec(ctx, "DELETE FROM subscription_pauses WHERE subscription_id = $1 AND paused_from = $2",
+		id, from)
+	if err != nil {
+		l.Error("Failed to delete pause", zap.Error(err))
+		return fmt.Errorf("delete pause: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return domain.NotFound("pause not found")
+	}
+	return nil
+}
+
+func (r *PgRepository) GetPauses(ctx context.Context, id uuid.UUID) ([]model.Pause, error) {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	query := `
+		SELECT paused_from, resumed_from
+		FROM subscription_pauses
+		WHERE subscription_id = $1
+		ORDER BY paused_from
+	`
+
+	rows, err := tx.Query(ctx, query, id)
+	if err != nil {
+		l.Error("Failed to query pauses", zap.Error(err))
+		return nil, fmt.Errorf("query pauses: %w", err)
+	}
+	defer rows.Close()
+
+	pauses := []model.Pause{}
+	for rows.Next() {
+		var p model.Pause
+		if err := rows.Scan(&p.From, &p.ResumeFrom); err != nil {
+			return nil, fmt.Errorf("scan pause: %w", err)
+		}
+		pauses = append(pauses, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query pauses: %w", err)
+	}
+	return pauses, nil
+}
@@ -0,0 +1,219 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"go.uber.org/zap"
+	apimw "subservice/internal/api/middleware"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"time"
+)
+
+func (r *PgRepository) InsertPromotion(ctx context.Context, promo model.Promotion) error {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	query := `
+		INSERT INTO promotions (code, kind, value, duration_months, service_names, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	serviceNames := promo.ServiceNames
+	if serviceNames == nil {
+		serviceNames = []string{}
+	}
+	_, err := tx.Exec(ctx, query, promo.Code, promo.Kind, promo.Value, promo.DurationMonths, serviceNames, promo.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return domain.Conflict("promotion %s already exists", promo.Code)
+		}
+		l.Error("Failed to insert promotion", zap.Error(err))
+		return fmt.Errorf("insert promotion: %w", err)
+	}
+	l.Info("Promotion created", zap.String("code", promo.Code))
+	return nil
+}
+
+const promotionColumns = "code, kind, value, duration_months, service_names, created_at"
+
+func scanPromotion(row pgx.Row, promo *model.Promotion) error {
+	if err := row.Scan(&promo.Code, &promo.Kind, &promo.Value, &promo.DurationMonths, &promo.ServiceNames, &promo.CreatedAt); err != nil {
+		return err
+	}
+	if len(promo.ServiceNames) == 0 {
+		promo.ServiceNames = nil
+	}
+	return nil
+}
+
+func (r *PgRepository) GetPromotion(ctx context.Context, code string) (*model.Promotion, error) {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	var promo model.Promotion
+	err := scanPromotion(tx.QueryRow(ctx, "SELECT "+promotionColumns+" FROM promotions WHERE code = $1", code), &promo)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NotFound("promotion not found")
+		}
+		l.Error("Failed to get promotion", zap.Error(err))
+		return nil, fmt.Errorf("get promotion: %w", err)
+	}
+	return &promo, nil
+}
+
+func (r *PgRepository) GetPromotions(ctx context.Context) ([]model.Promotion, error) {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	rows, err := tx.Query(ctx, "SELECT "+promotionColumns+" FROM promotions ORDER BY code")
+	if err != nil {
+		l.Error("Failed to query promotions", zap.Error(err))
+		return nil, fmt.Errorf("query promotions: %w", err)
+	}
+	defer rows.Close()
+
+	promos := []model.Promotion{}
+	for rows.Next() {
+		var promo model.Promotion
+		if err := scanPromotion(rows, &promo); err != nil {
+			return nil, fmt.Errorf("scan promotion: %w", err)
+		}
+		promos = append(promos, promo)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query promotions: %w", err)
+	}
+	return promos, nil
+}
+
+func (r *PgRepository) DeletePromotion(ctx context.Context, code string) error {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	cmdTag, err := tx.Exec(ctx, "DELETE FROM promotions WHERE code = $1", code)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+			return domain.Conflict("promotion %s is applied to subscriptions", code)
+		}
+		l.Error("Failed to delete promotion", zap.Error(err))
+		return fmt.Errorf("delete promotion: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return domain.NotFound("promotion not found")
+	}
+	l.Info("Promotion deleted", zap.String("code", code))
+	return nil
+}
+
+func (r *PgRepository) InsertAppliedPromotion(ctx context.Context, id uuid.UUID, applied model.AppliedPromotion) error {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	query := `
+		INSERT INTO subscription_promotions (subscription_id, promo_code, applied_from, applied_until)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	_, err := tx.Exec(ctx, query, id, applied.Code, applied.AppliedFrom, applied.AppliedUntil)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return domain.Conflict("subscription already has a promotion from %s", applied.AppliedFrom.Format("2006-01"))
+		}
+		if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+			return domain.NotFound("subscription or promotion not found")
+		}
+		l.Error("Failed to apply promotion", zap.Error(err))
+		return fmt.Errorf("apply promotion: %w", err)
+	}
+	l.Info("Promotion applied", zap.String("id", id.String()), zap.String("code", applied.Code))
+	return nil
+}
+
+// GetAppliedPromotions returns the promotions applied to the subscription,
+// ordered by AppliedFrom.
+func (r *PgRepository) GetAppliedPromotions(ctx context.Context, id uuid.UUID) ([]model.AppliedPromotion, error) {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	query := `
+		SELECT promo_code, applied_from, applied_until
+		FROM subscription_promotions
+		WHERE subscription_id = $1
+		ORDER BY applied_from
+	`
+
+	rows, err := tx.Query(ctx, query, id)
+	if err != nil {
+		l.Error("Failed to query applied promotions", zap.Error(err))
+		return nil, fmt.Errorf("query applied promotions: %w", err)
+	}
+	defer rows.Close()
+
+	applied := []model.AppliedPromotion{}
+	for rows.Next() {
+		var a model.AppliedPromotion
+		if err := rows.Scan(&a.Code, &a.AppliedFrom, &a.AppliedUntil); err != nil {
+			return nil, fmt.Errorf("scan applied promotion: %w", err)
+		}
+		applied = append(applied, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query applied promotions: %w", err)
+	}
+	return applied, nil
+}
+
+// UpdateAppliedPromotionEnd sets the end month of the promotion applied from
+// from.
+func (r *PgRepository) UpdateAppliedPromotionEnd(ctx context.Context, id uuid.UUID, from, until time.Time) error {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	query := `
+		UPDATE subscription_promotions
+		SET applied_until = $3
+		WHERE subscription_id = $1 AND applied_from = $2
+	`
+
+	cmdTag, err := tx.Exec(ctx, query, id, from, until)
+	if err != nil {
+		l.Error("Failed to update applied promotion", zap.Error(err))
+		return fmt.Errorf("update applied promotion: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return domain.NotFound("subscription has no promotion")
+	}
+	return nil
+}
+
+func (r *PgRepository) DeleteAppliedPromotion(ctx context.Context, id uuid.UUID, from time.Time) error {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	cmdTag, err := tx.Exec(ctx, "DELETE FROM subscription_promotions WHERE subscription_id = $1 AND applied_from = $2", id, from)
+	if err != nil {
+		l.Error("Failed to remove promotion", zap.Error(err))
+		return fmt.Errorf("remove promotion: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return domain.NotFound("subscription has no promotion")
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	apimw "subservice/internal/api/middleware"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"time"
+)
+
+func (r *PgRepository) UpsertExchangeRate(ctx context.Context, rate model.ExchangeRate) error {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	query := `
+		INSERT INTO exchange_rates (from_currency, to_currency, valid_from, rate)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (from_currency, to_currency, valid_from) DO UPDATE SET rate = EXCLUDED.rate
+	`
+
+	_, err := tx.Exec(ctx, query, rate.FromCurrency, rate.ToCurrency, rate.ValidFrom, rate.Rate)
+	if err != nil {
+		l.Error("Failed to upsert exchange rate", zap.Error(err))
+		return fmt.Errorf("upsert exchange rate: %w", err)
+	}
+	l.Info("Exchange rate saved successfully", zap.String("from_currency", rate.FromCurrency), zap.String("to_currency", rate.ToCurrency), zap.Time("valid_from", rate.ValidFrom))
+	return nil
+}
+
+func (r *PgRepository) GetExchangeRates(ctx context.Context, fromCurrency, toCurrency *string) ([]model.ExchangeRate, error) {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	query := `
+		SELECT from_currency, to_currency, valid_from, rate
+		FROM exchange_rates
+		WHERE ($1::text IS NULL OR from_currency = $1)
+		  AND ($2::text IS NULL OR to_currency = $2)
+		ORDER BY from_currency, to_currency, valid_from
+	`
+
+	rows, err := tx.Query(ctx, query, fromCurrency, toCurrency)
+	if err != nil {
+		l.Error("Failed to query exchange rates", zap.Error(err))
+		return nil, fmt.Errorf("query exchange rates: %w", err)
+	}
+	defer rows.Close()
+
+	rates := []model.ExchangeRate{}
+	for rows.Next() {
+		var rate model.ExchangeRate
+		if err := rows.Scan(&rate.FromCurrency, &rate.ToCurrency, &rate.ValidFrom, &rate.Rate); err != nil {
+			return nil, fmt.Errorf("scan exchange rate: %w", err)
+		}
+		rates = append(rates, rate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query exchange rates: %w", err)
+	}
+	return rates, nil
+}
+
+func (r *PgRepository) DeleteExchangeRate(ctx context.Context, fromCurrency, toCurrency string, validFrom time.Time) error {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	query := "DELETE FROM exchange_rates WHERE from_currency = $1 AND to_currency = $2 AND valid_from = $3"
+
+	cmdTag, err := tx.Exec(ctx, query, fromCurrency, toCurrency, validFrom)
+	if err != nil {
+		l.Error("Failed to delete exchange rate", zap.Error(err))
+		return fmt.Errorf("delete exchange rate: %w", err)
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		l.Warn("Exchange rate not found for deletion", zap.String("from_currency", fromCurrency), zap.String("to_currency", toCurrency))
+		return domain.NotFound("exchange rate not found")
+	}
+	return nil
+}
@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"go.uber.org/zap"
+	"strings"
 	apimw "subservice/internal/api/middleware"
+	"subservice/internal/domain"
 	"subservice/internal/model"
 	"time"
 
@@ -14,6 +16,34 @@ import (
 	"github.com/jackc/pgx/v4"
 )
 
+const (
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+)
+
+const subscriptionColumns = "id, user_id, service_name, price, start_date, end_date, billing_period, billing_interval, currency, deleted_at, version, trial_end_date, COALESCE(split, ''), " +
+	subscriptionTagsColumn + ", " + subscriptionMembersColumn
+
+func scanSubscription(row pgx.Row, sub *model.Subscription) error {
+	return row.Scan(
+		&sub.Id,
+		&sub.UserId,
+		&sub.ServiceName,
+		&sub.Price,
+		&sub.StartDate,
+		&sub.EndDate,
+		&sub.BillingPeriod,
+		&sub.BillingInterval,
+		&sub.Currency,
+		&sub.DeletedAt,
+		&sub.Version,
+		&sub.TrialEndDate,
+		&sub.Split,
+		&sub.Tags,
+		&sub.Members,
+	)
+}
+
 type PgRepository struct {
 	txManager TransactionManager
 }
@@ -24,72 +54,78 @@ func NewPgRepository(txManager TransactionManager) *PgRepository {
 
 func (r *PgRepository) InsertSubscription(ctx context.Context, subUnit model.Subscription) error {
 	l := apimw.FromContext(ctx)
-	subUnit.StartDate = firstOfMonth(subUnit.StartDate)
-	if subUnit.EndDate != nil {
-		end := firstOfMonth(*subUnit.EndDate)
-		subUnit.EndDate = &end
-	}
+	subUnit = subUnit.NormalizeDates()
 	l.Info("Updated dates for subscription", zap.Time("start_date", subUnit.StartDate), zap.Timep("end_date", subUnit.EndDate))
 
 	tx := r.txManager.GetQueryEngine(ctx)
 
-	query := "INSERT INTO subscriptions (user_id, service_name, price, start_date, end_date) VALUES ($1, $2, $3, $4, $5)"
+	query := `
+		INSERT INTO subscriptions (id, user_id, service_name, price, start_date, end_date, billing_period, billing_interval, currency, trial_end_date, split)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
 
-	_, err := tx.Exec(ctx, query, subUnit.UserId, subUnit.ServiceName, subUnit.Price, subUnit.StartDate, subUnit.EndDate)
+	_, err := tx.Exec(ctx, query,
+		subUnit.Id,
+		subUnit.UserId,
+		subUnit.ServiceName,
+		subUnit.Price,
+		subUnit.StartDate,
+		subUnit.EndDate,
+		subUnit.BillingPeriod,
+		subUnit.BillingInterval,
+		subUnit.Currency,
+		subUnit.TrialEndDate,
+		nullableString(subUnit.Split),
+	)
 	if err != nil {
 
-		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
-			l.Warn("Subscription already exists", zap.String("user_id", subUnit.UserId.String()), zap.String("service_name", subUnit.ServiceName))
-			return errors.New("subscription already exists")
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			l.Warn("Subscription already exists", zap.String("id", subUnit.Id.String()))
+			return domain.Conflict("subscription with this id already exists")
 		}
 		l.Error("Failed to insert subscription", zap.Error(err))
+		return fmt.Errorf("insert subscription: %w", err)
+	}
+	if err := r.replaceTags(ctx, subUnit.Id, subUnit.Tags); err != nil {
 		return err
 	}
-	l.Info("Subscription inserted successfully", zap.String("user_id", subUnit.UserId.String()), zap.String("service_name", subUnit.ServiceName))
+	if err := r.replaceMembers(ctx, subUnit.Id, subUnit.Members); err != nil {
+		return err
+	}
+	l.Info("Subscription inserted successfully", zap.String("id", subUnit.Id.String()), zap.String("user_id", subUnit.UserId.String()), zap.String("service_name", subUnit.ServiceName))
 	return nil
 }
 
-func (r *PgRepository) GetSubscription(ctx context.Context, userId uuid.UUID, serviceName string) (*model.Subscription, error) {
+func (r *PgRepository) GetSubscription(ctx context.Context, id uuid.UUID) (*model.Subscription, error) {
 	l := apimw.FromContext(ctx)
 
 	tx := r.txManager.GetQueryEngine(ctx)
 
 	query := `
-		SELECT user_id, service_name, price, start_date, end_date
+		SELECT ` + subscriptionColumns + `
 		FROM subscriptions
-		WHERE user_id = $1 AND service_name = $2
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
-	row := tx.QueryRow(ctx, query, userId, serviceName)
-
 	var sub model.Subscription
-	err := row.Scan(
-		&sub.UserId,
-		&sub.ServiceName,
-		&sub.Price,
-		&sub.StartDate,
-		&sub.EndDate,
-	)
+	err := scanSubscription(tx.QueryRow(ctx, query, id), &sub)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			l.Warn("Subscription not found", zap.String("user_id", userId.String()), zap.String("service_name", serviceName))
-			return nil, errors.New("subscription not found")
+			l.Warn("Subscription not found", zap.String("id", id.String()))
+			return nil, domain.NotFound("subscription not found")
 		}
 		l.Error("Failed to get subscription", zap.Error(err))
-		return nil, err
+		return nil, fmt.Errorf("get subscription: %w", err)
 	}
-	l.Info("Subscription fetched successfully", zap.String("user_id", userId.String()), zap.String("service_name", serviceName))
+	l.Info("Subscription fetched successfully", zap.String("id", id.String()))
 	return &sub, nil
 }
 
 func (r *PgRepository) UpdateSubscription(ctx context.Context, subUnit model.Subscription) error {
 	l := apimw.FromContext(ctx)
 
-	subUnit.StartDate = firstOfMonth(subUnit.StartDate)
-	if subUnit.EndDate != nil {
-		end := firstOfMonth(*subUnit.EndDate)
-		subUnit.EndDate = &end
-	}
+	subUnit = subUnit.NormalizeDates()
 	l.Info("Updated dates for subscription", zap.Time("start_date", subUnit.StartDate), zap.Timep("end_date", subUnit.EndDate))
 
 	tx := r.txManager.GetQueryEngine(ctx)
@@ -98,125 +134,695 @@ func (r *PgRepository) UpdateSubscription(ctx context.Context, subUnit model.Sub
 		UPDATE subscriptions
 		SET price = $1,
 		    start_date = $2,
-		    end_date = $3
-		WHERE user_id = $4 AND service_name = $5
+		    end_date = $3,
+		    billing_period = $4,
+		    billing_interval = $5,
+		    currency = $6,
+		    trial_end_date = $7,
+		    split = $8,
+		    version = version + 1
+		WHERE id = $9 AND deleted_at IS NULL
+		  AND ($10::bigint = 0 OR version = $10)
 	`
 
 	cmdTag, err := tx.Exec(ctx, query,
 		subUnit.Price,
 		subUnit.StartDate,
 		subUnit.EndDate,
-		subUnit.UserId,
-		subUnit.ServiceName,
+		subUnit.BillingPeriod,
+		subUnit.BillingInterval,
+		subUnit.Currency,
+		subUnit.TrialEndDate,
+		nullableString(subUnit.Split),
+		subUnit.Id,
+		subUnit.Version,
 	)
 	if err != nil {
 		l.Error("Failed to update subscription", zap.Error(err))
-		return err
+		return fmt.Errorf("update subscription: %w", err)
 	}
 
 	if cmdTag.RowsAffected() == 0 {
-		l.Warn("Subscription not found for update", zap.String("user_id", subUnit.UserId.String()), zap.String("service_name", subUnit.ServiceName))
-		return errors.New("subscription not found")
+		l.Warn("Subscription not found for update", zap.String("id", subUnit.Id.String()), zap.Int64("version", subUnit.Version))
+		return domain.NotFound("subscription not found")
 	}
-	l.Info("Subscription updated successfully", zap.String("user_id", subUnit.UserId.String()), zap.String("service_name", subUnit.ServiceName))
+	if err := r.replaceTags(ctx, subUnit.Id, subUnit.Tags); err != nil {
+		return err
+	}
+	if err := r.replaceMembers(ctx, subUnit.Id, subUnit.Members); err != nil {
+		return err
+	}
+	l.Info("Subscription updated successfully", zap.String("id", subUnit.Id.String()))
 	return nil
 }
 
-func (r *PgRepository) DeleteSubscription(ctx context.Context, userId uuid.UUID, serviceName string) error {
+// DeleteSubscription soft-deletes the subscription by setting deleted_at; the
+// row is removed for good by PurgeSubscriptions. A non-zero version must match
+// the stored one.
+func (r *PgRepository) DeleteSubscription(ctx context.Context, id uuid.UUID, version int64) error {
 	l := apimw.FromContext(ctx)
 
 	tx := r.txManager.GetQueryEngine(ctx)
 
-	query := "DELETE FROM subscriptions WHERE user_id = $1 AND service_name = $2"
+	query := `
+		UPDATE subscriptions
+		SET deleted_at = now(), version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL
+		  AND ($2::bigint = 0 OR version = $2)
+	`
 
-	cmdTag, err := tx.Exec(ctx, query, userId, serviceName)
+	cmdTag, err := tx.Exec(ctx, query, id, version)
 	if err != nil {
 		l.Error("Failed to delete subscription", zap.Error(err))
-		return err
+		return fmt.Errorf("delete subscription: %w", err)
 	}
 
 	if cmdTag.RowsAffected() == 0 {
-		l.Warn("Subscription not found for deletion", zap.String("user_id", userId.String()), zap.String("service_name", serviceName))
-		return errors.New("subscription not found")
+		l.Warn("Subscription not found for deletion", zap.String("id", id.String()))
+		return domain.NotFound("subscription not found")
 	}
-	l.Info("Subscription deleted successfully", zap.String("user_id", userId.String()), zap.String("service_name", serviceName))
+	l.Info("Subscription deleted successfully", zap.String("id", id.String()))
 	return nil
 }
 
-func (r *PgRepository) GetSubscriptionsList(ctx context.Context, userId *uuid.UUID, serviceName *string) (*[]model.Subscription, error) {
+func (r *PgRepository) RestoreSubscription(ctx context.Context, id uuid.UUID) error {
 	l := apimw.FromContext(ctx)
 
 	tx := r.txManager.GetQueryEngine(ctx)
 
 	query := `
-		SELECT user_id, service_name, price, start_date, end_date
-		FROM subscriptions
-		WHERE 1=1
+		UPDATE subscriptions
+		SET deleted_at = NULL, version = version + 1
+		WHERE id = $1 AND deleted_at IS NOT NULL
 	`
 
-	args := []interface{}{}
-	argIdx := 1
+	cmdTag, err := tx.Exec(ctx, query, id)
+	if err != nil {
+		l.Error("Failed to restore subscription", zap.Error(err))
+		return fmt.Errorf("restore subscription: %w", err)
+	}
 
-	if userId != nil {
-		l.Info("Filtering subscriptions by user_id", zap.String("user_id", userId.String()))
-		query += fmt.Sprintf(" AND user_id = $%d", argIdx)
-		args = append(args, *userId)
-		argIdx++
+	if cmdTag.RowsAffected() == 0 {
+		l.Warn("Deleted subscription not found for restore", zap.String("id", id.String()))
+		return domain.NotFound("deleted subscription not found")
+	}
+	l.Info("Subscription restored successfully", zap.String("id", id.String()))
+	return nil
+}
+
+// IncrementVersion bumps the version of a live subscription whose state changed
+// outside of its row, such as its status on pause or resume.
+func (r *PgRepository) IncrementVersion(ctx context.Context, id uuid.UUID) error {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	cmdTag, err := tx.Exec(ctx, "UPDATE subscriptions SET version = version + 1 WHERE id = $1 AND deleted_at IS NULL", id)
+	if err != nil {
+		l.Error("Failed to increment subscription version", zap.Error(err))
+		return fmt.Errorf("increment subscription version: %w", err)
 	}
+	if cmdTag.RowsAffected() == 0 {
+		return domain.NotFound("subscription not found")
+	}
+	return nil
+}
 
-	if serviceName != nil {
-		l.Info("Filtering subscriptions by service_name", zap.String("service_name", *serviceName))
-		query += fmt.Sprintf(" AND service_name = $%d", argIdx)
-		args = append(args, *serviceName)
-		argIdx++
+// PurgeSubscriptions hard-deletes subscriptions soft-deleted before the given
+// time, together with their price history.
+func (r *PgRepository) PurgeSubscriptions(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	query := "DELETE FROM subscriptions WHERE deleted_at IS NOT NULL AND deleted_at < $1"
+
+	cmdTag, err := tx.Exec(ctx, query, deletedBefore)
+	if err != nil {
+		l.Error("Failed to purge subscriptions", zap.Error(err))
+		return 0, fmt.Errorf("purge subscriptions: %w", err)
 	}
+	l.Info("Deleted subscriptions purged", zap.Int64("count", cmdTag.RowsAffected()), zap.Time("deleted_before", deletedBefore))
+	return cmdTag.RowsAffected(), nil
+}
+
+func (r *PgRepository) GetSubscriptionsList(ctx context.Context, filter model.ListFilter) (*model.SubscriptionPage, error) {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	query, args := listQuery(l, filter)
+
+	// One extra row tells whether there is a next page.
+	query += fmt.Sprintf(" LIMIT $%d", len(args)+1)
+	args = append(args, filter.Limit+1)
 
 	rows, err := tx.Query(ctx, query, args...)
 	if err != nil {
 		l.Error("Failed to query subscriptions", zap.Error(err))
-		return nil, err
+		return nil, fmt.Errorf("query subscriptions: %w", err)
 	}
 	defer rows.Close()
 
-	var subs []model.Subscription
+	subs := []model.Subscription{}
 
 	for rows.Next() {
 		var s model.Subscription
-		err := rows.Scan(&s.UserId, &s.ServiceName, &s.Price, &s.StartDate, &s.EndDate)
-		if err != nil {
-			return nil, err
+		if err := scanSubscription(rows, &s); err != nil {
+			return nil, fmt.Errorf("scan subscription: %w", err)
 		}
 		subs = append(subs, s)
 	}
-	l.Info("Fetched subscriptions successfully", zap.Int("count", len(subs)))
-	return &subs, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query subscriptions: %w", err)
+	}
+
+	page := &model.SubscriptionPage{Items: subs}
+	if len(subs) > filter.Limit {
+		page.Items = subs[:filter.Limit]
+		page.NextCursor = model.CursorFor(page.Items[filter.Limit-1], filter.SortBy, filter.Desc).Encode()
+	}
+	l.Info("Fetched subscriptions successfully", zap.Int("count", len(page.Items)))
+	return page, nil
+}
+
+// StreamSubscriptions calls fn for every subscription matching filter, in the
+// listing order, as rows arrive from the server instead of collecting them
+// first. A positive filter.Limit caps the number of rows.
+func (r *PgRepository) StreamSubscriptions(ctx context.Context, filter model.ListFilter, fn func(model.Subscription) error) error {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	query, args := listQuery(l, filter)
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", len(args)+1)
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		l.Error("Failed to query subscriptions", zap.Error(err))
+		return fmt.Errorf("query subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var s model.Subscription
+		if err := scanSubscription(rows, &s); err != nil {
+			return fmt.Errorf("scan subscription: %w", err)
+		}
+		if err := fn(s); err != nil {
+			return err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("query subscriptions: %w", err)
+	}
+	l.Info("Streamed subscriptions successfully", zap.Int("count", count))
+	return nil
+}
+
+// listQuery builds the filtered and ordered listing query without a LIMIT.
+func listQuery(l *zap.Logger, filter model.ListFilter) (string, []interface{}) {
+	query := `
+		SELECT ` + subscriptionColumns + `
+		FROM subscriptions
+		WHERE 1=1
+	`
+
+	args := []interface{}{}
+	argIdx := 1
+
+	if !filter.IncludeDeleted {
+		query += " AND deleted_at IS NULL"
+	}
+
+	if filter.UserId != nil {
+		l.Info("Filtering subscriptions by user_id", zap.String("user_id", filter.UserId.String()))
+		query += fmt.Sprintf(` AND (user_id = $%d OR EXISTS (
+			SELECT 1 FROM subscription_members m
+			WHERE m.subscription_id = subscriptions.id AND m.user_id = $%d
+		))`, argIdx, argIdx)
+		args = append(args, *filter.UserId)
+		argIdx++
+	}
+
+	if filter.ServiceName != nil {
+		l.Info("Filtering subscriptions by service_name", zap.String("service_name", *filter.ServiceName))
+		query += fmt.Sprintf(" AND service_name = $%d", argIdx)
+		args = append(args, *filter.ServiceName)
+		argIdx++
+	}
+
+	if filter.ServiceNamePrefix != nil {
+		query += fmt.Sprintf(" AND starts_with(service_name, $%d)", argIdx)
+		args = append(args, *filter.ServiceNamePrefix)
+		argIdx++
+	}
+
+	if filter.Tag != nil {
+		query += fmt.Sprintf(` AND EXISTS (
+			SELECT 1 FROM subscription_tags t
+			WHERE t.subscription_id = subscriptions.id AND t.tag = $%d
+		)`, argIdx)
+		args = append(args, *filter.Tag)
+		argIdx++
+	}
+
+	if filter.ActiveAt != nil {
+		query += fmt.Sprintf(" AND start_date <= $%d::date AND (end_date IS NULL OR end_date >= $%d::date)", argIdx, argIdx)
+		args = append(args, *filter.ActiveAt)
+		argIdx++
+	}
+
+	if filter.MinPrice != nil {
+		query += fmt.Sprintf(" AND price >= $%d", argIdx)
+		args = append(args, *filter.MinPrice)
+		argIdx++
+	}
+
+	if filter.MaxPrice != nil {
+		query += fmt.Sprintf(" AND price <= $%d", argIdx)
+		args = append(args, *filter.MaxPrice)
+		argIdx++
+	}
+
+	// Keyset pagination: rows are ordered by the sort column with
+	// (user_id, service_name, id) as a tie-breaker, and the cursor holds the
+	// position of the last row of the previous page.
+	columns := sortColumns(filter.SortBy)
+	direction, cmp := "ASC", ">"
+	if filter.Desc {
+		direction, cmp = "DESC", "<"
+	}
+
+	if filter.After != nil {
+		placeholders := make([]string, len(columns))
+		for i, col := range columns {
+			placeholders[i] = fmt.Sprintf("$%d", argIdx)
+			args = append(args, cursorValue(filter.After, col))
+			argIdx++
+		}
+		query += fmt.Sprintf(" AND (%s) %s (%s)", strings.Join(columns, ", "), cmp, strings.Join(placeholders, ", "))
+	}
+
+	order := make([]string, len(columns))
+	for i, col := range columns {
+		order[i] = col + " " + direction
+	}
+	query += " ORDER BY " + strings.Join(order, ", ")
+
+	return query, args
+}
+
+// monthlyChargesQuery expands every matching subscription into one row per
+// active month of the generate_series period, with the amount charged in that
+// month at the price in effect. Monthly, quarterly and yearly plans are charged
+// in their renewal months and weekly plans once per charge day falling into
+// the month; with $5 set the cycle price is spread evenly instead. A promotion
+// covering the renewal month of a cycle, or for weekly plans the month itself,
+// discounts the cycle price, so a fixed discount is taken off once per charge
+// however the amount is spread. With $6 set the amount is converted to that
+// currency at the rate valid for the month and is NULL when no such rate
+// exists. Trial months are charged nothing and marked by the trial column.
+// Paused months and soft-deleted subscriptions are skipped. With $8 set a
+// month of a shared subscription yields a row per payer, with user_id set to
+// the payer and the amount to their share: members pay their share rounded and
+// the owner the rest of the rounded charge, see model.SplitAmount. Otherwise
+// user_id is the owner's and the amount is the full charge.
+// Parameters: $1 from, $2 to, $3 user_id, $4 service_name, $5 amortize,
+// $6 target currency, $7 tag, $8 split shares, $9 catalog category.
+const monthlyChargesQuery = `
+	SELECT s.id, sh.user_id, s.service_name, s.currency, m::date AS month, b.trial,
+	       CASE WHEN b.trial THEN 0 ELSE sh.amount::bigint END AS amount
+	FROM subscriptions s
+	JOIN generate_series($1::date, $2::date, interval '1 month') m
+		ON m >= date_trunc('month', s.start_date)::date
+	   AND (s.end_date IS NULL OR m <= s.end_date)
+	CROSS JOIN LATERAL (
+		SELECT date_trunc('month', m)::date AS month_start,
+		       (date_trunc('month', m) + interval '1 month')::date AS month_end,
+		       7 * s.billing_interval AS cycle_days,
+		       CASE s.billing_period WHEN 'quarter' THEN 3 WHEN 'year' THEN 12 ELSE 1 END * s.billing_interval AS cycle_months,
+		       (EXTRACT(YEAR FROM m) * 12 + EXTRACT(MONTH FROM m))::int
+		           - (EXTRACT(YEAR FROM s.start_date) * 12 + EXTRACT(MONTH FROM s.start_date))::int AS months_since,
+		       COALESCE(m <= s.trial_end_date, false) AS trial
+	) b
+	CROSS JOIN LATERAL (
+		SELECT CASE
+		           WHEN s.billing_period = 'week' THEN m
+		           ELSE date_trunc('month', s.start_date) + make_interval(months => b.months_since - b.months_since % b.cycle_months)
+		       END::date AS month
+	) cy
+	LEFT JOIN LATERAL (
+		SELECT sp.price
+		FROM subscription_prices sp
+		WHERE sp.subscription_id = s.id
+		  AND sp.effective_from <= m
+		ORDER BY sp.effective_from DESC
+		LIMIT 1
+	) p ON true
+	LEFT JOIN LATERAL (
+		SELECT pr.kind, pr.value
+		FROM subscription_promotions spr
+		JOIN promotions pr ON pr.code = spr.promo_code
+		WHERE spr.subscription_id = s.id
+		  AND spr.applied_from <= cy.month
+		  AND spr.applied_until > cy.month
+		LIMIT 1
+	) d ON true
+	CROSS JOIN LATERAL (
+		SELECT CASE d.kind
+		           WHEN 'percent' THEN COALESCE(p.price, s.price)::numeric * (100 - d.value) / 100
+		           WHEN 'fixed' THEN GREATEST(COALESCE(p.price, s.price) - d.value, 0)::numeric
+		           ELSE COALESCE(p.price, s.price)::numeric
+		       END AS price
+	) dp
+	CROSS JOIN LATERAL (
+		SELECT CASE
+		           WHEN s.billing_period = 'week' AND $5::boolean
+		               THEN dp.price * (b.month_end - b.month_start) / b.cycle_days
+		           WHEN s.billing_period = 'week'
+		               THEN dp.price * (
+		                   ceil((b.month_end - s.start_date)::numeric / b.cycle_days)
+		                   - GREATEST(ceil((b.month_start - s.start_date)::numeric / b.cycle_days), 0))
+		           WHEN $5::boolean
+		               THEN dp.price / b.cycle_months
+		           WHEN b.months_since % b.cycle_months = 0
+		               THEN dp.price
+		           ELSE 0
+		       END::numeric AS amount
+	) c
+	LEFT JOIN LATERAL (
+		SELECT er.rate
+		FROM exchange_rates er
+		WHERE er.from_currency = s.currency
+		  AND er.to_currency = $6
+		  AND er.valid_from <= m
+		ORDER BY er.valid_from DESC
+		LIMIT 1
+	) r ON true
+	CROSS JOIN LATERAL (
+		SELECT c.amount * CASE WHEN $6::text IS NULL OR s.currency = $6 THEN 1 ELSE r.rate END AS amount
+	) t
+	CROSS JOIN LATERAL (
+		SELECT COUNT(*) AS members, COALESCE(SUM(sm.share), 0) AS shares
+		FROM subscription_members sm
+		WHERE sm.subscription_id = s.id AND $8::boolean
+	) g
+	CROSS JOIN LATERAL (
+		SELECT CASE s.split
+		           WHEN 'percent' THEN 100
+		           WHEN 'fixed' THEN GREATEST(COALESCE(p.price, s.price), g.shares, 1)
+		       END::numeric AS whole
+	) w
+	CROSS JOIN LATERAL (
+		WITH ms AS (
+			SELECT sm.user_id,
+			       ROUND(t.amount * CASE WHEN w.whole IS NULL THEN 1::numeric / (g.members + 1) ELSE sm.share / w.whole END) AS amount
+			FROM subscription_members sm
+			WHERE sm.subscription_id = s.id AND $8::boolean
+		)
+		SELECT s.user_id, ROUND(t.amount) - (SELECT COALESCE(SUM(ms.amount), 0) FROM ms) AS amount
+		UNION ALL
+		SELECT ms.user_id, ms.amount FROM ms
+	) sh
+	WHERE s.deleted_at IS NULL
+	  AND NOT EXISTS (
+		SELECT 1
+		FROM subscription_pauses sp
+		WHERE sp.subscription_id = s.id
+		  AND m >= sp.paused_from
+		  AND (sp.resumed_from IS NULL OR m < sp.resumed_from)
+	  )
+	  AND ($3::uuid IS NULL OR sh.user_id = $3)
+	  AND ($4::text IS NULL OR s.service_name = $4)
+	  AND ($7::text IS NULL OR EXISTS (
+		SELECT 1
+		FROM subscription_tags st
+		WHERE st.subscription_id = s.id
+		  AND st.tag = $7
+	  ))
+	  AND ($9::text IS NULL OR EXISTS (
+		SELECT 1
+		FROM services sv
+		WHERE sv.name = s.service_name
+		  AND sv.category = $9
+	  ))
+`
+
+// missingRateError reports the first month that could not be converted to the
+// target currency.
+func missingRateError(filter model.SummaryFilter, month time.Time) error {
+	return domain.Validation("target_currency", "no exchange rate to %s for %s", *filter.TargetCurrency, month.Format("2006-01"))
 }
 
-func (r *PgRepository) GetSubscriptionsSummary(ctx context.Context, from time.Time, to time.Time, userId *uuid.UUID, serviceName *string) (int, error) {
+// mixedCurrenciesError rejects summing amounts in different currencies without
+// a target currency to convert them to.
+func mixedCurrenciesError() error {
+	return domain.Validation("target_currency", "subscriptions are priced in several currencies, target_currency is required")
+}
+
+func (r *PgRepository) GetSubscriptionsSummary(ctx context.Context, filter model.SummaryFilter) (int, error) {
 	l := apimw.FromContext(ctx)
 
 	tx := r.txManager.GetQueryEngine(ctx)
 
 	query := `
-		SELECT COALESCE(SUM(s.price), 0) AS total_price
-		FROM subscriptions s
-		JOIN generate_series($1::date, $2::date, interval '1 month') m
-			ON m >= s.start_date
-		   AND (s.end_date IS NULL OR m <= s.end_date)
-		WHERE ($3::uuid IS NULL OR s.user_id = $3)
-		  AND ($4::text IS NULL OR s.service_name = $4)
+		WITH charges AS (` + monthlyChargesQuery + `)
+		SELECT COALESCE(SUM(c.amount), 0) AS total_price,
+		       MIN(c.month) FILTER (WHERE c.amount IS NULL) AS missing_rate_month,
+		       COUNT(DISTINCT c.currency) AS currencies
+		FROM charges c
 	`
 
-	var total int
-	err := tx.QueryRow(ctx, query, from, to, userId, serviceName).Scan(&total)
+	var (
+		total            int
+		missingRateMonth *time.Time
+		currencies       int
+	)
+	err := tx.QueryRow(ctx, query, filter.From, filter.To, filter.UserId, filter.ServiceName, filter.Amortize, filter.TargetCurrency, filter.Tag, filter.SplitShares(), filter.Category).
+		Scan(&total, &missingRateMonth, &currencies)
 	if err != nil {
 		l.Error("Failed to get subscriptions summary", zap.Error(err))
-		return 0, err
+		return 0, fmt.Errorf("get subscriptions summary: %w", err)
+	}
+	if filter.TargetCurrency == nil && currencies > 1 {
+		l.Warn("Summary mixes currencies without a target currency", zap.Int("currencies", currencies))
+		return 0, mixedCurrenciesError()
+	}
+	if missingRateMonth != nil {
+		l.Warn("Exchange rate is missing for summary", zap.Time("month", *missingRateMonth))
+		return 0, missingRateError(filter, *missingRateMonth)
 	}
 	l.Info("Fetched subscriptions summary successfully", zap.Int("total_price", total))
 	return total, nil
 }
 
-func firstOfMonth(t time.Time) time.Time {
-	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+func (r *PgRepository) GetSubscriptionsSummaryBreakdown(ctx context.Context, filter model.SummaryFilter) ([]model.SummaryRow, error) {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	// groupCols group the charges and rowCols select the groups; grouped by
+	// month, every month of the period gets a row, those without charges
+	// with zero totals and the other group columns NULL.
+	var groupCols, rowCols []string
+	source := "grouped g"
+	if filter.GroupByMonth {
+		groupCols = append(groupCols, "c.month")
+		rowCols = append(rowCols, "m::date")
+		source = "generate_series($1::date, $2::date, interval '1 month') m LEFT JOIN grouped g ON g.month = m::date"
+	}
+	if filter.GroupByService {
+		groupCols = append(groupCols, "c.service_name")
+		rowCols = append(rowCols, "g.service_name")
+	}
+	if filter.GroupByUser {
+		groupCols = append(groupCols, "c.user_id")
+		rowCols = append(rowCols, "g.user_id")
+	}
+	// Every charge is repeated for each tag of its subscription; untagged
+	// subscriptions fall into the NULL group.
+	from := "charges c"
+	if filter.GroupByTag {
+		groupCols = append(groupCols, "t.tag")
+		rowCols = append(rowCols, "g.tag")
+		from += " LEFT JOIN subscription_tags t ON t.subscription_id = c.id"
+	}
+	group := strings.Join(groupCols, ", ")
+	selected := strings.Join(rowCols, ", ")
+
+	query := fmt.Sprintf(`
+		WITH charges AS (%s),
+		grouped AS (
+			SELECT %s,
+			       COALESCE(SUM(c.amount), 0) AS total,
+			       COUNT(DISTINCT c.id) AS active_count,
+			       COUNT(*) FILTER (WHERE c.trial) AS trial_months,
+			       MIN(c.month) FILTER (WHERE c.amount IS NULL) AS missing_rate_month,
+			       MIN(c.currency) AS min_currency,
+			       MAX(c.currency) AS max_currency
+			FROM %s
+			GROUP BY %s
+		)
+		SELECT %s,
+		       COALESCE(g.total, 0), COALESCE(g.active_count, 0), COALESCE(g.trial_months, 0),
+		       g.missing_rate_month, COALESCE(g.min_currency, ''), COALESCE(g.max_currency, '')
+		FROM %s
+		ORDER BY %s
+	`, monthlyChargesQuery, group, from, group, selected, source, selected)
+
+	rows, err := tx.Query(ctx, query, filter.From, filter.To, filter.UserId, filter.ServiceName, filter.Amortize, filter.TargetCurrency, filter.Tag, filter.SplitShares(), filter.Category)
+	if err != nil {
+		l.Error("Failed to get subscriptions summary breakdown", zap.Error(err))
+		return nil, fmt.Errorf("get subscriptions summary breakdown: %w", err)
+	}
+	defer rows.Close()
+
+	// The amounts are in several currencies if any two rows disagree.
+	var currency string
+	breakdown := []model.SummaryRow{}
+	for rows.Next() {
+		var (
+			row         model.SummaryRow
+			month       time.Time
+			serviceName *string
+			userId      *uuid.UUID
+			tag         *string
+			missingRate *time.Time
+			minCurrency string
+			maxCurrency string
+			dest        []interface{}
+		)
+		if filter.GroupByMonth {
+			dest = append(dest, &month)
+		}
+		if filter.GroupByService {
+			dest = append(dest, &serviceName)
+		}
+		if filter.GroupByUser {
+			dest = append(dest, &userId)
+		}
+		if filter.GroupByTag {
+			dest = append(dest, &tag)
+		}
+		dest = append(dest, &row.Total, &row.ActiveCount, &row.TrialMonths, &missingRate, &minCurrency, &maxCurrency)
+
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("scan summary row: %w", err)
+		}
+		if missingRate != nil {
+			l.Warn("Exchange rate is missing for summary", zap.Time("month", *missingRate))
+			return nil, missingRateError(filter, *missingRate)
+		}
+		if filter.TargetCurrency == nil && minCurrency != "" {
+			if currency == "" {
+				currency = minCurrency
+			}
+			if minCurrency != currency || maxCurrency != currency {
+				l.Warn("Summary mixes currencies without a target currency")
+				return nil, mixedCurrenciesError()
+			}
+		}
+		if filter.GroupByMonth {
+			row.Month = &month
+		}
+		row.ServiceName, row.UserId, row.Tag = serviceName, userId, tag
+		breakdown = append(breakdown, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("get subscriptions summary breakdown: %w", err)
+	}
+	l.Info("Fetched subscriptions summary breakdown successfully", zap.Int("rows", len(breakdown)))
+	return breakdown, nil
+}
+
+func (r *PgRepository) InsertPriceChange(ctx context.Context, id uuid.UUID, change model.PriceChange) error {
+	l := apimw.FromContext(ctx)
+
+	change.EffectiveFrom = model.FirstOfMonth(change.EffectiveFrom)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	query := `
+		INSERT INTO subscription_prices (subscription_id, effective_from, price)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (subscription_id, effective_from) DO UPDATE SET price = EXCLUDED.price
+	`
+
+	_, err := tx.Exec(ctx, query, id, change.EffectiveFrom, change.Price)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+			l.Warn("Subscription not found for price change", zap.String("id", id.String()))
+			return domain.NotFound("subscription not found")
+		}
+		l.Error("Failed to insert price change", zap.Error(err))
+		return fmt.Errorf("insert price change: %w", err)
+	}
+	l.Info("Price change scheduled successfully", zap.String("id", id.String()), zap.Time("effective_from", change.EffectiveFrom))
+	return nil
+}
+
+func (r *PgRepository) GetPriceChanges(ctx context.Context, id uuid.UUID) ([]model.PriceChange, error) {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	query := `
+		SELECT effective_from, price
+		FROM subscription_prices
+		WHERE subscription_id = $1
+		ORDER BY effective_from
+	`
+
+	rows, err := tx.Query(ctx, query, id)
+	if err != nil {
+		l.Error("Failed to query price changes", zap.Error(err))
+		return nil, fmt.Errorf("query price changes: %w", err)
+	}
+	defer rows.Close()
+
+	changes := []model.PriceChange{}
+	for rows.Next() {
+		var c model.PriceChange
+		if err := rows.Scan(&c.EffectiveFrom, &c.Price); err != nil {
+			return nil, fmt.Errorf("scan price change: %w", err)
+		}
+		changes = append(changes, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query price changes: %w", err)
+	}
+	return changes, nil
+}
+
+func sortColumns(sortBy string) []string {
+	switch sortBy {
+	case model.SortByPrice:
+		return []string{"price", "user_id", "service_name", "id"}
+	case model.SortByStartDate:
+		return []string{"start_date", "user_id", "service_name", "id"}
+	default:
+		return []string{"service_name", "user_id", "id"}
+	}
+}
+
+func cursorValue(c *model.Cursor, column string) interface{} {
+	switch column {
+	case "price":
+		return c.Price
+	case "start_date":
+		return c.StartDate
+	case "user_id":
+		return c.UserId
+	case "id":
+		return c.Id
+	default:
+		return c.ServiceName
+	}
 }
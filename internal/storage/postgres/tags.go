@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	apimw "subservice/internal/api/middleware"
+
+	"github.com/google/uuid"
+)
+
+// subscriptionTagsColumn selects the sorted tags of the row of an unaliased
+// subscriptions table as a text array.
+const subscriptionTagsColumn = `ARRAY(
+	SELECT t.tag FROM subscription_tags t
+	WHERE t.subscription_id = subscriptions.id
+	ORDER BY t.tag
+) AS tags`
+
+// replaceTags sets the tags of a subscription to exactly tags.
+func (r *PgRepository) replaceTags(ctx context.Context, id uuid.UUID, tags []string) error {
+	l := apimw.FromContext(ctx)
+
+	tx := r.txManager.GetQueryEngine(ctx)
+
+	_, err := tx.Exec(ctx, "DELETE FROM subscription_tags WHERE subscription_id = $1", id)
+	if err != nil {
+		l.Error("Failed to delete subscription tags", zap.Error(err))
+		return fmt.Errorf("replace subscription tags: %w", err)
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO subscription_tags (subscription_id, tag)
+		SELECT $1, tag FROM unnest($2::text[]) AS tag
+		ON CONFLICT DO NOTHING
+	`
+	if _, err := tx.Exec(ctx, query, id, tags); err != nil {
+		l.Error("Failed to insert subscription tags", zap.Error(err))
+		return fmt.Errorf("replace subscription tags: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"time"
+)
+
+// PriceChangesOnUpdate returns the price changes an update of the stored
+// subscription current to sub has to record so that the months charged before
+// keep their price: the price charged in the old start month from the earlier
+// of the two start months, unless history already has a change effective then,
+// and the new price from the month of now. Without a new price only a start
+// moved before the history is pinned. The price of a subscription that ends
+// before the month of now cannot be changed this way.
+func PriceChangesOnUpdate(current *model.Subscription, history []model.PriceChange, sub model.Subscription, now time.Time) ([]model.PriceChange, error) {
+	oldStart := model.FirstOfMonth(current.StartDate)
+	start := oldStart
+	if newStart := model.FirstOfMonth(sub.StartDate); newStart.Before(start) {
+		start = newStart
+	}
+	pinned := model.PriceChange{EffectiveFrom: start, Price: priceAt(current.Price, history, oldStart)}
+
+	if sub.Price == current.Price {
+		if start.Before(oldStart) && len(history) > 0 && !hasPriceChangeAt(history, start) {
+			return []model.PriceChange{pinned}, nil
+		}
+		return nil, nil
+	}
+	month := model.FirstOfMonth(now)
+	if sub.EndDate != nil && model.FirstOfMonth(*sub.EndDate).Before(month) {
+		return nil, domain.InvalidPeriod("price of an ended subscription cannot be changed, schedule a price change instead")
+	}
+
+	if month.Before(start) {
+		month = start
+	}
+	var changes []model.PriceChange
+	if month.After(start) && !hasPriceChangeAt(history, start) {
+		changes = append(changes, pinned)
+	}
+	return append(changes, model.PriceChange{EffectiveFrom: month, Price: sub.Price}), nil
+}
+
+// priceAt returns the latest change in history effective at month, or base.
+// history must be ordered by EffectiveFrom.
+func priceAt(base int64, history []model.PriceChange, month time.Time) int64 {
+	price := base
+	for _, c := range history {
+		if c.EffectiveFrom.After(month) {
+			break
+		}
+		price = c.Price
+	}
+	return price
+}
+
+func hasPriceChangeAt(history []model.PriceChange, month time.Time) bool {
+	for _, c := range history {
+		if c.EffectiveFrom.Equal(month) {
+			return true
+		}
+	}
+	return false
+}
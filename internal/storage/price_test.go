@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"errors"
+	"reflect"
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"subservice/internal/testutil"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestPriceChangesOnUpdate(t *testing.T) {
+	id := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	now := time.Date(2024, time.October, 15, 12, 0, 0, 0, time.UTC)
+	change := func(m time.Month, price int64) model.PriceChange {
+		return model.PriceChange{EffectiveFrom: testutil.Month(2024, m), Price: price}
+	}
+
+	tests := []struct {
+		name    string
+		history []model.PriceChange
+		update  func(sub *model.Subscription)
+		want    []model.PriceChange
+		wantErr error
+	}{
+		{
+			name:   "unchanged",
+			update: func(sub *model.Subscription) {},
+		},
+		{
+			name:   "new price from the current month pins the old one",
+			update: func(sub *model.Subscription) { sub.Price = 150 },
+			want:   []model.PriceChange{change(time.January, 100), change(time.October, 150)},
+		},
+		{
+			name:    "start already pinned",
+			history: []model.PriceChange{change(time.January, 100), change(time.May, 120)},
+			update:  func(sub *model.Subscription) { sub.Price = 150 },
+			want:    []model.PriceChange{change(time.October, 150)},
+		},
+		{
+			name:    "earlier start pins the price of the old start month",
+			history: []model.PriceChange{change(time.January, 80), change(time.May, 120)},
+			update: func(sub *model.Subscription) {
+				sub.StartDate = testutil.Month(2023, time.November)
+				sub.Price = 150
+			},
+			want: []model.PriceChange{{EffectiveFrom: testutil.Month(2023, time.November), Price: 80}, change(time.October, 150)},
+		},
+		{
+			name:    "earlier start without a new price",
+			history: []model.PriceChange{change(time.May, 120)},
+			update:  func(sub *model.Subscription) { sub.StartDate = testutil.Month(2023, time.November) },
+			want:    []model.PriceChange{{EffectiveFrom: testutil.Month(2023, time.November), Price: 100}},
+		},
+		{
+			name: "later start keeps the months charged before",
+			update: func(sub *model.Subscription) {
+				sub.StartDate = testutil.Month(2024, time.December)
+				sub.Price = 150
+			},
+			want: []model.PriceChange{change(time.January, 100), change(time.October, 150)},
+		},
+		{
+			name: "ended subscription",
+			update: func(sub *model.Subscription) {
+				sub.EndDate = testutil.PtrTime(testutil.Month(2024, time.June))
+				sub.Price = 150
+			},
+			wantErr: domain.ErrInvalidPeriod,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := testutil.Monthly(id, 100)
+			sub := current
+			tt.update(&sub)
+
+			got, err := PriceChangesOnUpdate(&current, tt.history, sub, now)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("PriceChangesOnUpdate() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PriceChangesOnUpdate() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PriceChangesOnUpdate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
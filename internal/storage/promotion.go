@@ -0,0 +1,32 @@
+package storage
+
+import (
+	"subservice/internal/domain"
+	"subservice/internal/model"
+	"time"
+)
+
+// CheckPromotionOverlap fails with domain.ErrConflict if a discounts a month
+// already discounted by one of applied.
+func CheckPromotionOverlap(applied []model.AppliedPromotion, a model.AppliedPromotion) error {
+	for _, e := range applied {
+		if e.Overlaps(a) {
+			return domain.Conflict("promotion %s is applied from %s to %s", e.Code, e.AppliedFrom.Format("2006-01"), e.AppliedUntil.Format("2006-01"))
+		}
+	}
+	return nil
+}
+
+// PromotionToRemove returns the index of the first promotion in applied
+// (ordered by AppliedFrom) still in effect at month m. When that promotion
+// starts at m or later, removing it cancels it and cancel is set; otherwise it
+// ends at m and the months before keep their discount. domain.ErrNotFound is
+// returned if no promotion is in effect at or after m.
+func PromotionToRemove(applied []model.AppliedPromotion, m time.Time) (i int, cancel bool, err error) {
+	for i, a := range applied {
+		if a.AppliedUntil.After(m) {
+			return i, !a.AppliedFrom.Before(m), nil
+		}
+	}
+	return 0, false, domain.NotFound("subscription has no promotion")
+}
@@ -0,0 +1,28 @@
+package storage
+
+import (
+	"subservice/internal/domain"
+	"subservice/internal/model"
+)
+
+// CheckVersion fails with domain.ErrPreconditionFailed unless version is zero
+// or equal to the current version of sub.
+func CheckVersion(sub *model.Subscription, version int64) error {
+	if version != 0 && sub.Version != version {
+		return domain.PreconditionFailed("subscription version mismatch: current version is %d", sub.Version)
+	}
+	return nil
+}
+
+// CheckIdentity fails with a validation error if sub would move the stored
+// subscription current to another user or service. Service names are compared
+// by model.ServiceKey, so an update may change their spelling.
+func CheckIdentity(current *model.Subscription, sub model.Subscription) error {
+	if current.UserId != sub.UserId {
+		return domain.Validation("user_id", "user_id cannot be changed")
+	}
+	if model.ServiceKey(current.ServiceName) != model.ServiceKey(sub.ServiceName) {
+		return domain.Validation("service_name", "service_name cannot be changed")
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+// Package testutil holds the fixtures shared by the storage, service and API
+// tests.
+package testutil
+
+import (
+	"subservice/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	Owner   = uuid.MustParse("00000000-0000-0000-0000-00000000000a")
+	MemberB = uuid.MustParse("00000000-0000-0000-0000-00000000000b")
+	MemberC = uuid.MustParse("00000000-0000-0000-0000-00000000000c")
+)
+
+func Month(year int, m time.Month) time.Time {
+	return time.Date(year, m, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// Monthly returns a monthly RUB subscription of Owner to Yandex Plus starting
+// in January 2024.
+func Monthly(id uuid.UUID, price int64) model.Subscription {
+	return model.Subscription{
+		Id:              id,
+		ServiceName:     "Yandex Plus",
+		Price:           price,
+		UserId:          Owner,
+		StartDate:       Month(2024, time.January),
+		BillingPeriod:   model.BillingMonth,
+		BillingInterval: 1,
+		Currency:        "RUB",
+	}
+}
+
+// Shared returns Monthly shared with MemberB and MemberC, who hold shareB and
+// shareC under split.
+func Shared(id uuid.UUID, price int64, split string, shareB, shareC int64) model.Subscription {
+	sub := Monthly(id, price)
+	sub.Split = split
+	sub.Members = []model.Member{{UserId: MemberB, Share: shareB}, {UserId: MemberC, Share: shareC}}
+	return sub
+}
+
+func PtrTime(t time.Time) *time.Time {
+	return &t
+}
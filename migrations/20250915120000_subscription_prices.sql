@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS subscription_prices (
+    user_id UUID NOT NULL,
+    service_name TEXT NOT NULL,
+    effective_from DATE NOT NULL CHECK (EXTRACT(DAY FROM effective_from) = 1),
+    price INTEGER NOT NULL CHECK (price >= 0),
+
+    CONSTRAINT subscription_prices_pk PRIMARY KEY (user_id, service_name, effective_from),
+    CONSTRAINT subscription_prices_subscription_fk FOREIGN KEY (user_id, service_name)
+        REFERENCES subscriptions (user_id, service_name) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS subscription_prices;
@@ -0,0 +1,22 @@
+-- +goose Up
+ALTER TABLE subscriptions
+    ADD COLUMN billing_period TEXT NOT NULL DEFAULT 'month'
+        CHECK (billing_period IN ('week', 'month', 'quarter', 'year')),
+    ADD COLUMN billing_interval INTEGER NOT NULL DEFAULT 1 CHECK (billing_interval > 0);
+
+-- Weekly plans keep their exact start date: it defines the charge weekday.
+ALTER TABLE subscriptions
+    DROP CONSTRAINT subscriptions_start_date_check,
+    ADD CONSTRAINT subscriptions_start_date_check
+        CHECK (billing_period = 'week' OR EXTRACT(DAY FROM start_date) = 1);
+
+-- +goose Down
+DELETE FROM subscriptions WHERE billing_period = 'week' AND EXTRACT(DAY FROM start_date) <> 1;
+
+ALTER TABLE subscriptions
+    DROP CONSTRAINT subscriptions_start_date_check,
+    ADD CONSTRAINT subscriptions_start_date_check CHECK (EXTRACT(DAY FROM start_date) = 1);
+
+ALTER TABLE subscriptions
+    DROP COLUMN billing_interval,
+    DROP COLUMN billing_period;
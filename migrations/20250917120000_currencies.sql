@@ -0,0 +1,18 @@
+-- +goose Up
+ALTER TABLE subscriptions
+    ADD COLUMN currency TEXT NOT NULL DEFAULT 'RUB' CHECK (currency ~ '^[A-Z]{3}$');
+
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    from_currency TEXT NOT NULL CHECK (from_currency ~ '^[A-Z]{3}$'),
+    to_currency TEXT NOT NULL CHECK (to_currency ~ '^[A-Z]{3}$'),
+    valid_from DATE NOT NULL,
+    rate NUMERIC(20, 10) NOT NULL CHECK (rate > 0),
+
+    CONSTRAINT exchange_rates_pk PRIMARY KEY (from_currency, to_currency, valid_from),
+    CONSTRAINT exchange_rates_distinct_currencies CHECK (from_currency <> to_currency)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS exchange_rates;
+
+ALTER TABLE subscriptions DROP COLUMN currency;
@@ -0,0 +1,32 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS subscription_events (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL,
+    service_name TEXT NOT NULL,
+    action TEXT NOT NULL,
+    before JSONB,
+    after JSONB,
+    request_id TEXT,
+    actor TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS subscription_events_subscription_idx
+    ON subscription_events (user_id, service_name, id);
+
+-- The log is append-only.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION subscription_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'subscription_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER subscription_events_append_only
+    BEFORE UPDATE OR DELETE ON subscription_events
+    FOR EACH ROW EXECUTE FUNCTION subscription_events_append_only();
+
+-- +goose Down
+DROP TABLE IF EXISTS subscription_events;
+DROP FUNCTION IF EXISTS subscription_events_append_only();
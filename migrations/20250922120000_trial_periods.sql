@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS trial_end_date DATE;
+
+-- +goose Down
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS trial_end_date;
@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS subscription_pauses (
+    user_id UUID NOT NULL,
+    service_name TEXT NOT NULL,
+    paused_from DATE NOT NULL CHECK (EXTRACT(DAY FROM paused_from) = 1),
+    resumed_from DATE CHECK (EXTRACT(DAY FROM resumed_from) = 1),
+
+    CONSTRAINT subscription_pauses_pk PRIMARY KEY (user_id, service_name, paused_from),
+    CONSTRAINT subscription_pauses_period_chk CHECK (resumed_from IS NULL OR resumed_from > paused_from),
+    CONSTRAINT subscription_pauses_subscription_fk FOREIGN KEY (user_id, service_name)
+        REFERENCES subscriptions (user_id, service_name) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS subscription_pauses;
@@ -0,0 +1,28 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS promotions (
+    code TEXT PRIMARY KEY,
+    kind TEXT NOT NULL CHECK (kind IN ('percent', 'fixed')),
+    value BIGINT NOT NULL CHECK (value > 0),
+    duration_months INTEGER NOT NULL CHECK (duration_months > 0),
+    service_names TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    CONSTRAINT promotions_percent_chk CHECK (kind <> 'percent' OR value <= 100)
+);
+
+CREATE TABLE IF NOT EXISTS subscription_promotions (
+    user_id UUID NOT NULL,
+    service_name TEXT NOT NULL,
+    promo_code TEXT NOT NULL REFERENCES promotions (code) ON DELETE RESTRICT,
+    applied_from DATE NOT NULL CHECK (EXTRACT(DAY FROM applied_from) = 1),
+
+    CONSTRAINT subscription_promotions_pk PRIMARY KEY (user_id, service_name),
+    CONSTRAINT subscription_promotions_subscription_fk FOREIGN KEY (user_id, service_name)
+        REFERENCES subscriptions (user_id, service_name) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS subscription_promotions_code_idx ON subscription_promotions (promo_code);
+
+-- +goose Down
+DROP TABLE IF EXISTS subscription_promotions;
+DROP TABLE IF EXISTS promotions;
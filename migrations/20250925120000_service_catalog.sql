@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS services (
+    name TEXT PRIMARY KEY,
+    -- name_key is the normalized name used to resolve service names.
+    name_key TEXT NOT NULL UNIQUE,
+    category TEXT,
+    default_price INTEGER CHECK (default_price >= 0)
+);
+
+CREATE TABLE IF NOT EXISTS service_aliases (
+    alias TEXT PRIMARY KEY,
+    service_name TEXT NOT NULL REFERENCES services (name) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS service_aliases_service_name_idx ON service_aliases (service_name);
+
+-- +goose Down
+DROP TABLE IF EXISTS service_aliases;
+DROP TABLE IF EXISTS services;
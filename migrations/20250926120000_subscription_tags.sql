@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS subscription_tags (
+    user_id UUID NOT NULL,
+    service_name TEXT NOT NULL,
+    tag TEXT NOT NULL,
+
+    CONSTRAINT subscription_tags_pk PRIMARY KEY (user_id, service_name, tag),
+    CONSTRAINT subscription_tags_subscription_fk FOREIGN KEY (user_id, service_name)
+        REFERENCES subscriptions (user_id, service_name) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS subscription_tags_tag_idx ON subscription_tags (tag);
+
+-- +goose Down
+DROP TABLE IF EXISTS subscription_tags;
@@ -0,0 +1,144 @@
+-- +goose Up
+-- Subscriptions get their own id, so a user may hold several subscriptions to
+-- the same service. Dependent tables are re-keyed from (user_id, service_name)
+-- to subscription_id.
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS id UUID NOT NULL DEFAULT gen_random_uuid();
+
+ALTER TABLE subscription_prices ADD COLUMN subscription_id UUID;
+ALTER TABLE subscription_pauses ADD COLUMN subscription_id UUID;
+ALTER TABLE subscription_promotions ADD COLUMN subscription_id UUID;
+ALTER TABLE subscription_tags ADD COLUMN subscription_id UUID;
+ALTER TABLE subscription_events ADD COLUMN subscription_id UUID;
+
+UPDATE subscription_prices c SET subscription_id = s.id
+FROM subscriptions s WHERE s.user_id = c.user_id AND s.service_name = c.service_name;
+UPDATE subscription_pauses c SET subscription_id = s.id
+FROM subscriptions s WHERE s.user_id = c.user_id AND s.service_name = c.service_name;
+UPDATE subscription_promotions c SET subscription_id = s.id
+FROM subscriptions s WHERE s.user_id = c.user_id AND s.service_name = c.service_name;
+UPDATE subscription_tags c SET subscription_id = s.id
+FROM subscriptions s WHERE s.user_id = c.user_id AND s.service_name = c.service_name;
+
+-- Events of purged subscriptions keep a NULL subscription_id.
+ALTER TABLE subscription_events DISABLE TRIGGER subscription_events_append_only;
+UPDATE subscription_events e SET subscription_id = s.id
+FROM subscriptions s WHERE s.user_id = e.user_id AND s.service_name = e.service_name;
+ALTER TABLE subscription_events ENABLE TRIGGER subscription_events_append_only;
+
+ALTER TABLE subscription_prices DROP CONSTRAINT subscription_prices_subscription_fk;
+ALTER TABLE subscription_pauses DROP CONSTRAINT subscription_pauses_subscription_fk;
+ALTER TABLE subscription_promotions DROP CONSTRAINT subscription_promotions_subscription_fk;
+ALTER TABLE subscription_tags DROP CONSTRAINT subscription_tags_subscription_fk;
+
+ALTER TABLE subscriptions
+    DROP CONSTRAINT subscriptions_pk,
+    ADD CONSTRAINT subscriptions_pk PRIMARY KEY (id);
+
+CREATE INDEX IF NOT EXISTS subscriptions_user_service_idx ON subscriptions (user_id, service_name);
+
+ALTER TABLE subscription_prices
+    DROP CONSTRAINT subscription_prices_pk,
+    DROP COLUMN user_id,
+    DROP COLUMN service_name,
+    ALTER COLUMN subscription_id SET NOT NULL,
+    ADD CONSTRAINT subscription_prices_pk PRIMARY KEY (subscription_id, effective_from),
+    ADD CONSTRAINT subscription_prices_subscription_fk FOREIGN KEY (subscription_id)
+        REFERENCES subscriptions (id) ON DELETE CASCADE;
+
+ALTER TABLE subscription_pauses
+    DROP CONSTRAINT subscription_pauses_pk,
+    DROP COLUMN user_id,
+    DROP COLUMN service_name,
+    ALTER COLUMN subscription_id SET NOT NULL,
+    ADD CONSTRAINT subscription_pauses_pk PRIMARY KEY (subscription_id, paused_from),
+    ADD CONSTRAINT subscription_pauses_subscription_fk FOREIGN KEY (subscription_id)
+        REFERENCES subscriptions (id) ON DELETE CASCADE;
+
+ALTER TABLE subscription_promotions
+    DROP CONSTRAINT subscription_promotions_pk,
+    DROP COLUMN user_id,
+    DROP COLUMN service_name,
+    ALTER COLUMN subscription_id SET NOT NULL,
+    ADD CONSTRAINT subscription_promotions_pk PRIMARY KEY (subscription_id),
+    ADD CONSTRAINT subscription_promotions_subscription_fk FOREIGN KEY (subscription_id)
+        REFERENCES subscriptions (id) ON DELETE CASCADE;
+
+ALTER TABLE subscription_tags
+    DROP CONSTRAINT subscription_tags_pk,
+    DROP COLUMN user_id,
+    DROP COLUMN service_name,
+    ALTER COLUMN subscription_id SET NOT NULL,
+    ADD CONSTRAINT subscription_tags_pk PRIMARY KEY (subscription_id, tag),
+    ADD CONSTRAINT subscription_tags_subscription_fk FOREIGN KEY (subscription_id)
+        REFERENCES subscriptions (id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS subscription_events_subscription_id_idx
+    ON subscription_events (subscription_id, id);
+
+-- +goose Down
+-- Of several subscriptions to the same service only the latest one is kept.
+DELETE FROM subscriptions s
+USING subscriptions o
+WHERE s.user_id = o.user_id AND s.service_name = o.service_name
+  AND (s.start_date, s.id) < (o.start_date, o.id);
+
+DROP INDEX IF EXISTS subscription_events_subscription_id_idx;
+ALTER TABLE subscription_events DROP COLUMN subscription_id;
+
+ALTER TABLE subscription_prices ADD COLUMN user_id UUID, ADD COLUMN service_name TEXT;
+ALTER TABLE subscription_pauses ADD COLUMN user_id UUID, ADD COLUMN service_name TEXT;
+ALTER TABLE subscription_promotions ADD COLUMN user_id UUID, ADD COLUMN service_name TEXT;
+ALTER TABLE subscription_tags ADD COLUMN user_id UUID, ADD COLUMN service_name TEXT;
+
+UPDATE subscription_prices c SET user_id = s.user_id, service_name = s.service_name
+FROM subscriptions s WHERE s.id = c.subscription_id;
+UPDATE subscription_pauses c SET user_id = s.user_id, service_name = s.service_name
+FROM subscriptions s WHERE s.id = c.subscription_id;
+UPDATE subscription_promotions c SET user_id = s.user_id, service_name = s.service_name
+FROM subscriptions s WHERE s.id = c.subscription_id;
+UPDATE subscription_tags c SET user_id = s.user_id, service_name = s.service_name
+FROM subscriptions s WHERE s.id = c.subscription_id;
+
+ALTER TABLE subscription_prices
+    DROP CONSTRAINT subscription_prices_subscription_fk,
+    DROP CONSTRAINT subscription_prices_pk,
+    DROP COLUMN subscription_id,
+    ALTER COLUMN user_id SET NOT NULL,
+    ALTER COLUMN service_name SET NOT NULL,
+    ADD CONSTRAINT subscription_prices_pk PRIMARY KEY (user_id, service_name, effective_from);
+ALTER TABLE subscription_pauses
+    DROP CONSTRAINT subscription_pauses_subscription_fk,
+    DROP CONSTRAINT subscription_pauses_pk,
+    DROP COLUMN subscription_id,
+    ALTER COLUMN user_id SET NOT NULL,
+    ALTER COLUMN service_name SET NOT NULL,
+    ADD CONSTRAINT subscription_pauses_pk PRIMARY KEY (user_id, service_name, paused_from);
+ALTER TABLE subscription_promotions
+    DROP CONSTRAINT subscription_promotions_subscription_fk,
+    DROP CONSTRAINT subscription_promotions_pk,
+    DROP COLUMN subscription_id,
+    ALTER COLUMN user_id SET NOT NULL,
+    ALTER COLUMN service_name SET NOT NULL,
+    ADD CONSTRAINT subscription_promotions_pk PRIMARY KEY (user_id, service_name);
+ALTER TABLE subscription_tags
+    DROP CONSTRAINT subscription_tags_subscription_fk,
+    DROP CONSTRAINT subscription_tags_pk,
+    DROP COLUMN subscription_id,
+    ALTER COLUMN user_id SET NOT NULL,
+    ALTER COLUMN service_name SET NOT NULL,
+    ADD CONSTRAINT subscription_tags_pk PRIMARY KEY (user_id, service_name, tag);
+
+DROP INDEX IF EXISTS subscriptions_user_service_idx;
+ALTER TABLE subscriptions
+    DROP CONSTRAINT subscriptions_pk,
+    ADD CONSTRAINT subscriptions_pk PRIMARY KEY (user_id, service_name),
+    DROP COLUMN id;
+
+ALTER TABLE subscription_prices ADD CONSTRAINT subscription_prices_subscription_fk
+    FOREIGN KEY (user_id, service_name) REFERENCES subscriptions (user_id, service_name) ON DELETE CASCADE;
+ALTER TABLE subscription_pauses ADD CONSTRAINT subscription_pauses_subscription_fk
+    FOREIGN KEY (user_id, service_name) REFERENCES subscriptions (user_id, service_name) ON DELETE CASCADE;
+ALTER TABLE subscription_promotions ADD CONSTRAINT subscription_promotions_subscription_fk
+    FOREIGN KEY (user_id, service_name) REFERENCES subscriptions (user_id, service_name) ON DELETE CASCADE;
+ALTER TABLE subscription_tags ADD CONSTRAINT subscription_tags_subscription_fk
+    FOREIGN KEY (user_id, service_name) REFERENCES subscriptions (user_id, service_name) ON DELETE CASCADE;
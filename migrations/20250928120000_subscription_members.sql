@@ -0,0 +1,23 @@
+-- +goose Up
+-- A shared subscription is paid by its owner (user_id) together with its
+-- members; split says how the charges are divided and is NULL when the
+-- subscription is not shared.
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS split TEXT CHECK (split IN ('equal', 'percent', 'fixed'));
+
+CREATE TABLE IF NOT EXISTS subscription_members (
+    subscription_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    share BIGINT CHECK (share > 0),
+
+    CONSTRAINT subscription_members_pk PRIMARY KEY (subscription_id, user_id),
+    CONSTRAINT subscription_members_subscription_fk FOREIGN KEY (subscription_id)
+        REFERENCES subscriptions (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS subscription_members_user_id_idx ON subscription_members (user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS subscription_members;
+
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS split;
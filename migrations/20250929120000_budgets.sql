@@ -0,0 +1,26 @@
+-- +goose Up
+-- The empty category is the user's overall budget.
+CREATE TABLE IF NOT EXISTS budgets (
+    user_id UUID NOT NULL,
+    category TEXT NOT NULL DEFAULT '',
+    monthly_limit BIGINT NOT NULL CHECK (monthly_limit >= 0),
+    currency TEXT NOT NULL DEFAULT 'RUB' CHECK (currency ~ '^[A-Z]{3}$'),
+
+    CONSTRAINT budgets_pk PRIMARY KEY (user_id, category)
+);
+
+-- Months in which a budget was found exceeded, so each is reported once.
+CREATE TABLE IF NOT EXISTS budget_alerts (
+    user_id UUID NOT NULL,
+    category TEXT NOT NULL,
+    month DATE NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    CONSTRAINT budget_alerts_pk PRIMARY KEY (user_id, category, month),
+    CONSTRAINT budget_alerts_budget_fk FOREIGN KEY (user_id, category)
+        REFERENCES budgets (user_id, category) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS budget_alerts;
+DROP TABLE IF EXISTS budgets;
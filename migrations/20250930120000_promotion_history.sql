@@ -0,0 +1,28 @@
+-- +goose Up
+-- A subscription keeps every promotion applied to it. Removing a promotion ends
+-- it at applied_until instead of deleting it, so the months it discounted stay
+-- discounted; a new promotion may follow once the previous one has ended.
+ALTER TABLE subscription_promotions ADD COLUMN applied_until DATE;
+
+UPDATE subscription_promotions sp
+SET applied_until = (sp.applied_from + make_interval(months => p.duration_months))::date
+FROM promotions p
+WHERE p.code = sp.promo_code;
+
+ALTER TABLE subscription_promotions
+    ALTER COLUMN applied_until SET NOT NULL,
+    ADD CONSTRAINT subscription_promotions_period_chk CHECK (applied_until > applied_from),
+    DROP CONSTRAINT subscription_promotions_pk,
+    ADD CONSTRAINT subscription_promotions_pk PRIMARY KEY (subscription_id, applied_from);
+
+-- +goose Down
+-- Only the latest promotion of each subscription is kept.
+DELETE FROM subscription_promotions s
+USING subscription_promotions o
+WHERE s.subscription_id = o.subscription_id AND s.applied_from < o.applied_from;
+
+ALTER TABLE subscription_promotions
+    DROP CONSTRAINT subscription_promotions_pk,
+    DROP CONSTRAINT subscription_promotions_period_chk,
+    DROP COLUMN applied_until,
+    ADD CONSTRAINT subscription_promotions_pk PRIMARY KEY (subscription_id);
@@ -0,0 +1,26 @@
+-- +goose Up
+-- Subscriptions created before their service or alias was added to the catalog
+-- are stored under the canonical catalog name, as new ones are. Keys are
+-- normalized like model.ServiceKey. Every renamed subscription gets an update
+-- event in the audit log; its before and after are the stored rows, without
+-- the tags and members the service adds to the events it records.
+WITH renamed AS (
+    UPDATE subscriptions s
+    SET service_name = k.name, version = s.version + 1
+    FROM (
+        SELECT name_key AS key, name FROM services
+        UNION ALL
+        SELECT alias, service_name FROM service_aliases
+    ) k, subscriptions prev
+    WHERE k.key = lower(btrim(regexp_replace(s.service_name, '\s+', ' ', 'g')))
+      AND s.service_name <> k.name
+      AND prev.id = s.id
+    RETURNING s.id, s.user_id, s.service_name, to_jsonb(prev) AS before, to_jsonb(s) AS after
+)
+INSERT INTO subscription_events (subscription_id, user_id, service_name, action, before, after, actor)
+SELECT id, user_id, service_name, 'update', before, after, 'migration'
+FROM renamed;
+
+-- +goose Down
+-- The names the subscriptions were stored under before are kept only in the
+-- audit log.
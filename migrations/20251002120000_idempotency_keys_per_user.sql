@@ -0,0 +1,13 @@
+-- +goose Up
+-- Idempotency keys are scoped to the user the subscription is created for.
+-- Stored keys carry no user and are dropped; they only live for the TTL.
+DELETE FROM idempotency_keys;
+ALTER TABLE idempotency_keys DROP CONSTRAINT idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD COLUMN user_id UUID NOT NULL;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (user_id, key);
+
+-- +goose Down
+DELETE FROM idempotency_keys;
+ALTER TABLE idempotency_keys DROP CONSTRAINT idempotency_keys_pkey;
+ALTER TABLE idempotency_keys DROP COLUMN user_id;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (key);
@@ -0,0 +1,7 @@
+// Package migrations embeds the goose SQL migrations into the service binary.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS